@@ -3,11 +3,15 @@
 package main_test
 
 import (
+	"encoding/json"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/test"
+	"github.com/korrel8r/korrel8r/pkg/rest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,13 +26,16 @@ func TestMain_list(t *testing.T) {
 	out, err := cliCommand(t, "list").Output()
 	require.NoError(t, test.ExecError(err))
 	want := `
-alert     Alerts that metric values are out of bounds.
-k8s       Resource objects in a Kubernetes API server
-log       Records from container and node logs.
-metric    Time-series of measured values
-mock      Mock domain.
-netflow   Network flows from source nodes to destination nodes.
-trace     Traces from Pods and Nodes.
+alert      Alerts that metric values are out of bounds.
+event      Kubernetes events archived in a log store.
+incident   Incidents in an external incident management system.
+k8s        Resource objects in a Kubernetes API server
+log        Records from container and node logs.
+metric     Time-series of measured values
+mock       Mock domain.
+netflow    Network flows from source nodes to destination nodes.
+profile    Continuous profiles of running processes.
+trace      Traces from Pods and Nodes.
 `
 	assert.Equal(t, strings.TrimSpace(want), strings.TrimSpace(string(out)))
 }
@@ -77,6 +84,10 @@ func TestMain_rules(t *testing.T) {
 			args: []string{"rules", "--goal", "mock:foo"},
 			want: "barfoo",
 		},
+		{
+			args: []string{"rules", "--graph", "--start", "mock:foo", "-o", "mermaid"},
+			want: "flowchart LR\n  n0[\"mock:foo\"]\n  n1[\"mock:bar\"]\n  n0 -->|\"foobar\"| n1",
+		},
 	} {
 		t.Run(strings.Join(x.args, " "), func(t *testing.T) {
 			out, err := cliCommand(t, x.args...).Output()
@@ -85,3 +96,115 @@ func TestMain_rules(t *testing.T) {
 		})
 	}
 }
+
+func TestMain_validate(t *testing.T) {
+	out, err := cliCommand(t, "validate").Output()
+	require.NoError(t, test.ExecError(err))
+	assert.Empty(t, strings.TrimSpace(string(out)))
+
+	out, err = command(t, "validate", "testdata/invalid.yaml").Output()
+	require.Error(t, err)
+	assert.Equal(t, `testdata/invalid.yaml:2: error: rule "bad": start: domain not found: "nonesuch"`, strings.TrimSpace(string(out)))
+}
+
+func TestMain_test_rules(t *testing.T) {
+	out, err := cliCommand(t, "test-rules", "testdata/rules_test.yaml").Output()
+	require.Error(t, err)
+	want := `
+FAIL  barfoo  1 of 1 tests failed
+  testdata/rules_test.yaml #1: expected query: mock:foo:wrong
+    got query: mock:foo:x
+PASS  foobar  1 tests
+`
+	assert.Equal(t, strings.TrimSpace(want), strings.TrimSpace(string(out)))
+}
+
+func TestMain_functions(t *testing.T) {
+	out, err := cliCommand(t, "functions", "log").Output()
+	require.NoError(t, test.ExecError(err))
+	want := `
+logSafeLabel (log) func(string) string
+    Converts the string argument into a safe label containing only alphanumerics '_' and ':'.
+    Example: {{ logSafeLabel "app.kubernetes.io/name" }} => app_kubernetes_io_name
+logTypeForNamespace (log) func(string) string
+    Takes a namespace string argument. Returns the log type ("application" or "infrastructure") of a container in the namespace.
+    Example: {{ logTypeForNamespace "openshift-monitoring" }} => infrastructure
+`
+	assert.Equal(t, strings.TrimSpace(want), strings.TrimSpace(string(out)))
+}
+
+func TestMain_neighbours_explain(t *testing.T) {
+	out, err := cliCommand(t, "neighbours", "--query", "mock:foo:x", "--depth", "1", "--explain").Output()
+	require.NoError(t, test.ExecError(err))
+	want := `
+RULE    START     OBJECT  OUTCOME  QUERY       COUNT
+foobar  mock:foo  foo.x   applied  mock:bar:y  1
+`
+	assert.Equal(t, strings.TrimSpace(want), strings.TrimSpace(string(out)))
+}
+
+func TestMain_neighbours_ranked(t *testing.T) {
+	out, err := cliCommand(t, "neighbours", "--query", "mock:foo:x", "--depth", "1", "--ranked", "1", "-o", "yaml").Output()
+	require.NoError(t, test.ExecError(err))
+	assert.Contains(t, string(out), `
+  ranked:
+  - distance: 1
+    object: bar.y
+    paths: 1
+    score: 0.5
+`)
+}
+
+func TestMain_timeline(t *testing.T) {
+	out, err := cliCommand(t, "timeline", "--query", "mock:foo:x", "--depth", "1", "-o", "yaml").Output()
+	require.NoError(t, test.ExecError(err))
+	assert.Equal(t, `- class: mock:bar
+  object: bar.y
+  path:
+  - foobar
+- class: mock:foo
+  object: foo.x
+`, string(out))
+}
+
+func TestMain_neighbours_mermaid(t *testing.T) {
+	out, err := cliCommand(t, "neighbours", "--query", "mock:foo:x", "--depth", "1", "-o", "mermaid").Output()
+	require.NoError(t, test.ExecError(err))
+	assert.Equal(t, `flowchart LR
+  n0["mock:foo (1)"]
+  n1["mock:bar (1)"]
+  n0 -->|"foobar (1)"| n1
+`, string(out))
+}
+
+func TestMain_report(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "report.html")
+	out, err := cliCommand(t, "report", "--query", "mock:foo:x", "--depth", "1", "--title", "Test Report", "-f", file).Output()
+	require.NoError(t, test.ExecError(err))
+	assert.Empty(t, string(out))
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	html := string(b)
+	assert.Contains(t, html, "<title>Test Report</title>")
+	assert.Contains(t, html, ">mock:foo (1)</text>")
+	assert.Contains(t, html, ">mock:bar (1)</text>")
+	assert.Contains(t, html, "<td>foobar</td>")
+	assert.Contains(t, html, "<code>mock:bar:y</code></td><td>1</td>")
+}
+
+func TestMain_recordReplay(t *testing.T) {
+	dir := t.TempDir()
+	args := []string{"neighbours", "--query", "mock:foo:x", "--depth", "1", "-o", "json"}
+	recorded, err := cliCommand(t, append(args, "--record", dir)...).Output()
+	require.NoError(t, test.ExecError(err))
+	b, err := os.ReadFile(filepath.Join(dir, "mock.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "mock:bar:y:\n- bar.y\nmock:foo:x:\n- foo.x\n", string(b))
+	replayed, err := cliCommand(t, append(args, "--replay", dir)...).Output()
+	require.NoError(t, test.ExecError(err))
+	// Node order is not deterministic, compare normalized graphs.
+	var want, got rest.Graph
+	require.NoError(t, json.Unmarshal(recorded, &want))
+	require.NoError(t, json.Unmarshal(replayed, &got))
+	assert.Equal(t, rest.Normalize(want), rest.Normalize(got))
+}
@@ -0,0 +1,30 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/korrel8r/korrel8r/pkg/rest"
+)
+
+// printExplanation prints a table of steps, with errors on an indented line following the step.
+func printExplanation(out io.Writer, steps []rest.Step) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "RULE\tSTART\tOBJECT\tOUTCOME\tQUERY\tCOUNT")
+	for _, s := range steps {
+		count := "-"
+		if s.Count >= 0 {
+			count = fmt.Sprint(s.Count)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", s.Rule, s.Start, s.Object, s.Outcome, s.Query, count)
+		if s.Error != "" && s.Outcome != "notApplicable" {
+			w.Flush()
+			fmt.Fprintf(out, "  error: %v\n", strings.ReplaceAll(s.Error, "\n", "\n    "))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var functionsCmd = &cobra.Command{
+	Use:   "functions [SOURCE]",
+	Short: "List template functions available to rules. SOURCE is a domain name, 'engine' or 'sprig'.",
+	Args:  cobra.RangeArgs(0, 1),
+	Run: func(cmd *cobra.Command, args []string) {
+		e, _ := newEngine()
+		out := cmd.OutOrStdout()
+		for _, f := range e.TemplateFuncs() {
+			switch {
+			case len(args) > 0 && f.Source != args[0]:
+				continue
+			case len(args) == 0 && f.Source == "sprig" && !*functionsAll:
+				continue
+			}
+			fmt.Fprintf(out, "%v (%v) %v\n", f.Name, f.Source, f.Signature())
+			if f.Description != "" {
+				fmt.Fprintf(out, "    %v\n", f.Description)
+			}
+			if f.Example != "" {
+				fmt.Fprintf(out, "    Example: %v\n", f.Example)
+			}
+		}
+	},
+}
+
+var functionsAll *bool
+
+func init() {
+	functionsAll = functionsCmd.Flags().Bool("all", false, "Include sprig functions.")
+	rootCmd.AddCommand(functionsCmd)
+}
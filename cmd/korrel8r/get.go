@@ -14,20 +14,24 @@ import (
 	"github.com/korrel8r/korrel8r/internal/pkg/must"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
+	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/ptr"
 	"github.com/korrel8r/korrel8r/pkg/rest"
 	"github.com/spf13/cobra"
 )
 
-// Common flags for neighbours and goals
+// Common flags for searches
 var (
 	class   string
 	queries []string
 	objects []string
+	explain bool
+	ranked  int
 
 	limit                 int
 	since, until, timeout time.Duration
+	namespaces            []string
 )
 
 func startFlags(cmd *cobra.Command) {
@@ -36,11 +40,17 @@ func startFlags(cmd *cobra.Command) {
 	cmd.Flags().StringArrayVar(&objects, "object", nil, "Serialized start object, can be multiple.")
 }
 
+func graphFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&ranked, "ranked", 0, "Include up to this many of the most relevant objects in each node, with scores.")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Print the outcome of each rule applied to each start object, instead of the result graph.")
+}
+
 func constraintFlags(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&limit, "limit", 0, "Limit total number of results.")
 	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Timeout for store requests.")
 	cmd.Flags().DurationVar(&since, "since", 0, "Only get results since this long ago.")
 	cmd.Flags().DurationVar(&until, "until", 0, "Only get results until this long ago.")
+	cmd.Flags().StringArrayVar(&namespaces, "namespace", nil, "Only get results in this namespace, can be multiple.")
 }
 
 var (
@@ -81,9 +91,11 @@ var (
 			e, _ := newEngine()
 			ctx, cancel := korrel8r.WithConstraint(context.Background(), constraint())
 			defer cancel()
-			g, err := traverse.New(e, e.Graph()).Neighbours(ctx, start(e), depth)
+			x := explanation()
+			s := start(e)
+			g, err := traverse.New(e, e.Graph()).Neighbours(traverse.WithExplanation(ctx, x), s, depth)
 			check(err)
-			newPrinter(os.Stdout).Print(rest.NewGraph(g))
+			printGraph(g, s.Class, x)
 		},
 	}
 	depth int
@@ -92,6 +104,7 @@ var (
 func init() {
 	rootCmd.AddCommand(neighboursCmd)
 	startFlags(neighboursCmd)
+	graphFlags(neighboursCmd)
 	constraintFlags(neighboursCmd)
 	neighboursCmd.Flags().IntVarP(&depth, "depth", "d", 2, "Depth of neighbourhood search.")
 }
@@ -109,17 +122,45 @@ var (
 			}
 			ctx, cancel := korrel8r.WithConstraint(context.Background(), constraint())
 			defer cancel()
-			g, err := traverse.New(e, e.Graph()).Goals(ctx, start(e), goals)
+			ctx = traverse.WithMaxCost(ctx, maxCost)
+			x := explanation()
+			s := start(e)
+			g, err := traverse.New(e, e.Graph()).Goals(traverse.WithExplanation(ctx, x), s, goals)
 			check(err)
-			newPrinter(os.Stdout).Print(rest.NewGraph(g))
+			printGraph(g, s.Class, x)
 		},
 	}
+	maxCost float64
 )
 
 func init() {
 	rootCmd.AddCommand(goalsCmd)
 	startFlags(goalsCmd)
+	graphFlags(goalsCmd)
 	constraintFlags(goalsCmd)
+	goalsCmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Exclude goals that cost more than this to reach, 0 means no limit.")
+}
+
+// explanation returns an explanation to record steps if the --explain flag is set, nil otherwise.
+func explanation() *traverse.Explanation {
+	if explain {
+		return traverse.NewExplanation()
+	}
+	return nil
+}
+
+// printGraph prints the explanation if there is one, the graph otherwise.
+// The graph is encoded if the --output flag names a graph format.
+func printGraph(g *graph.Graph, start korrel8r.Class, x *traverse.Explanation) {
+	if x != nil {
+		printExplanation(os.Stdout, rest.NewSteps(x))
+	} else if f := graph.FormatNamed(*outputFlag); f != nil {
+		must.Must(f.Encode(os.Stdout, g.NonEmpty()))
+	} else {
+		gr := rest.NewGraph(g)
+		rest.RankNodes(gr, g, start, ranked)
+		newPrinter(os.Stdout).Print(gr)
+	}
 }
 
 func constraint() *korrel8r.Constraint {
@@ -137,6 +178,7 @@ func constraint() *korrel8r.Constraint {
 	if until > 0 {
 		c.End = ptr.To(now.Add(-until))
 	}
+	c.Namespaces = namespaces
 	return c
 }
 
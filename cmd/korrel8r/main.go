@@ -6,6 +6,7 @@ import (
 	_ "embed"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/build"
 	"github.com/korrel8r/korrel8r/internal/pkg/logging"
@@ -13,13 +14,18 @@ import (
 	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
 	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/domains/alert"
+	"github.com/korrel8r/korrel8r/pkg/domains/event"
+	"github.com/korrel8r/korrel8r/pkg/domains/incident"
 	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
 	logdomain "github.com/korrel8r/korrel8r/pkg/domains/log"
 	"github.com/korrel8r/korrel8r/pkg/domains/metric"
 	"github.com/korrel8r/korrel8r/pkg/domains/netflow"
+	"github.com/korrel8r/korrel8r/pkg/domains/profile"
 	"github.com/korrel8r/korrel8r/pkg/domains/trace"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/spf13/cobra"
 )
 
@@ -33,10 +39,12 @@ var (
 	}
 
 	// Global Flags
-	outputFlag  = rootCmd.PersistentFlags().StringP("output", "o", "yaml", "Output format: [json, json-pretty, yaml]")
+	outputFlag  = rootCmd.PersistentFlags().StringP("output", "o", "yaml", fmt.Sprintf("Output format: [json, json-pretty, ndjson, yaml], commands that output graphs also accept: [%v]", strings.Join(graph.FormatNames(), ", ")))
 	verboseFlag = rootCmd.PersistentFlags().IntP("verbose", "v", 0, "Verbosity for logging (0: notice/error/warn, 1: info, 2: debug, 3: trace-per-request, 4: trace-per-rule, 5: trace-per-object)")
 	configFlag  = rootCmd.PersistentFlags().StringP("config", "c", getConfig(), "Configuration file")
 	panicFlag   = rootCmd.PersistentFlags().Bool("panic", false, "Panic on error")
+	recordFlag  = rootCmd.PersistentFlags().String("record", "", "Record store queries and results in this directory, for use with --replay")
+	replayFlag  = rootCmd.PersistentFlags().String("replay", "", "Replay store results recorded by --record in this directory, instead of using configured stores")
 	// TODO: remove sync search once async search is full tested.
 	syncFlag = rootCmd.PersistentFlags().Bool("sync", false, "Deprectated: synchronous search, will be removed")
 	// see profile.go for profile flag
@@ -47,7 +55,10 @@ const (
 	defaultConfig = "/etc/korrel8r/korrel8r.yaml"
 )
 
-var profileStop interface{ Stop() }
+var (
+	profileStop interface{ Stop() }
+	tracingStop func()
+)
 
 func init() {
 	_ = rootCmd.PersistentFlags().MarkHidden("panic")
@@ -60,12 +71,16 @@ func init() {
 		if profileFlag != nil {
 			profileStop = StartProfile()
 		}
+		tracingStop = StartTracing()
 	})
 
 	cobra.OnFinalize(func() {
 		if profileStop != nil {
 			profileStop.Stop()
 		}
+		if tracingStop != nil {
+			tracingStop()
+		}
 	})
 }
 
@@ -98,10 +113,30 @@ func newEngine() (*engine.Engine, config.Configs) {
 	if *syncFlag {
 		traverse.New = traverse.NewSync
 	}
-	c := must.Must1(config.Load(*configFlag))
-	e := must.Must1(engine.Build().
-		Domains(k8s.Domain, logdomain.Domain, netflow.Domain, trace.Domain, alert.Domain, metric.Domain, mock.Domain("mock")).
-		Config(c).
-		Engine())
-	return e, c
+	c := must.Must1(config.Read(*configFlag))
+	return must.Must1(buildEngine(c)), c
+}
+
+// authorizer if not nil authorizes store queries for engines created by buildEngine.
+var authorizer engine.Authorizer
+
+// buildEngine builds a new engine with all known domains from configuration.
+// Stores are recorded or replayed if --record or --replay are set.
+func buildEngine(c config.Configs) (*engine.Engine, error) {
+	b := engine.Build().Domains(domains()...).Config(c)
+	if authorizer != nil {
+		b.Authorizer(authorizer)
+	}
+	if *recordFlag != "" {
+		b.Record(*recordFlag)
+	}
+	if *replayFlag != "" {
+		b.Replay(*replayFlag)
+	}
+	return b.Engine()
+}
+
+// domains returns all known domains.
+func domains() []korrel8r.Domain {
+	return []korrel8r.Domain{k8s.Domain, logdomain.Domain, netflow.Domain, trace.Domain, alert.Domain, metric.Domain, event.Domain, profile.Domain, incident.Domain, mock.Domain("mock")}
 }
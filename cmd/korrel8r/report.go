@@ -0,0 +1,76 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/must"
+	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report [GOAL...]",
+	Short: "Write an HTML report of correlation results.",
+	Long: `Search from the start objects to GOAL classes, or to the neighbours of the start objects if there are no goals.
+Write a self-contained HTML report with a graph of the results, the objects of each class,
+the queries run with their result counts, and any errors encountered.
+The report can be viewed offline, for example when attached to a ticket.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		e, _ := newEngine()
+		search := traverse.NeighbourSearch(depth)
+		if len(args) > 0 {
+			var goals []korrel8r.Class
+			for _, g := range args {
+				goals = append(goals, must.Must1(e.Class(g)))
+			}
+			search = traverse.GoalSearch(goals)
+		}
+		c := constraint()
+		ctx, cancel := korrel8r.WithConstraint(context.Background(), c)
+		defer cancel()
+		x := traverse.NewExplanation()
+		ctx = traverse.WithExplanation(ctx, x)
+		s := start(e)
+		g, err := search(ctx, traverse.New(e, e.Graph()), s)
+		if g == nil {
+			check(err)
+		}
+		var w io.Writer = os.Stdout
+		if reportFile != "" {
+			f := must.Must1(os.Create(reportFile))
+			defer func() { must.Must(f.Close()) }()
+			w = f
+		}
+		r := &report.Report{
+			Title:       reportTitle,
+			Start:       s.Class,
+			Graph:       g,
+			Constraint:  c,
+			Explanation: x,
+			Err:         err,
+			MaxObjects:  reportMaxObjects,
+		}
+		must.Must(r.Write(w))
+	},
+}
+
+var (
+	reportFile, reportTitle string
+	reportMaxObjects        int
+)
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	startFlags(reportCmd)
+	constraintFlags(reportCmd)
+	reportCmd.Flags().IntVarP(&depth, "depth", "d", 2, "Depth of neighbourhood search, if there are no goals.")
+	reportCmd.Flags().StringVarP(&reportFile, "file", "f", "", "Write the report to this file instead of standard output.")
+	reportCmd.Flags().StringVar(&reportTitle, "title", "", "Title of the report.")
+	reportCmd.Flags().IntVar(&reportMaxObjects, "max-objects", 100, "Maximum number of objects shown for each class, 0 means no limit.")
+}
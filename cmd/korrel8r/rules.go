@@ -3,6 +3,7 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
 	"os"
 	"regexp"
@@ -13,7 +14,6 @@ import (
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/spf13/cobra"
-	"gonum.org/v1/gonum/graph/encoding/dot"
 )
 
 var rulesCmd = &cobra.Command{
@@ -38,8 +38,8 @@ var rulesCmd = &cobra.Command{
 		}
 		if *ruleGraph {
 			g := e.Graph().Select(func(l *graph.Line) bool { return test(l.Rule) })
-			b := must.Must1(dot.MarshalMulti(g, "", "", "  "))
-			os.Stdout.Write(b)
+			f := cmp.Or(graph.FormatNamed(*outputFlag), graph.DOT)
+			must.Must(f.Encode(os.Stdout, g))
 		} else { // Print rules as text
 			for _, r := range e.Rules() {
 				if test(r) {
@@ -60,6 +60,6 @@ func init() {
 	ruleStart = rulesCmd.Flags().StringP("start", "s", "", "show rules with this start class")
 	ruleGoal = rulesCmd.Flags().StringP("goal", "g", "", "show rules with this goal class")
 	ruleName = rulesCmd.Flags().StringP("name", "n", "", "show rules with name matching this regexp")
-	ruleGraph = rulesCmd.Flags().Bool("graph", false, "write rule graph in graphviz format")
+	ruleGraph = rulesCmd.Flags().Bool("graph", false, "write rule graph in the --output graph format, graphviz by default")
 	rootCmd.AddCommand(rulesCmd)
 }
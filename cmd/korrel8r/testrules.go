@@ -0,0 +1,88 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/must"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/engine"
+	"github.com/korrel8r/korrel8r/pkg/rules/ruletest"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/maps"
+)
+
+var testRulesCmd = &cobra.Command{
+	Use:   "test-rules TEST_FILE_OR_DIR...",
+	Short: "Run declarative rule tests against the configured rules. Exit with non-zero status if any test fails.",
+	Long: `Run declarative rule tests against the rules in --config. Stores are not used.
+
+A test file is a YAML or JSON list of tests. For a directory, all files named *_test.yaml or *_test.json are loaded.
+Each test names a rule, the class of a start object, the start object, and the expected query.
+If the rule should not apply to the object, set notApplicable: true instead of a query.
+
+  - rule: PodToLogs
+    start: k8s:Pod.v1.
+    object:
+      metadata: {namespace: project, name: application}
+    query: 'log:application:{kubernetes_namespace_name="project",kubernetes_pod_name="application"}'
+
+Results are reported for each rule.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configs := must.Must1(config.Read(*configFlag)).Clone()
+		for i := range configs {
+			configs[i].Stores = nil // Rule tests do not use stores.
+		}
+		e := must.Must1(engine.Build().Domains(domains()...).Config(configs).Engine())
+		var tests []ruletest.Test
+		for _, path := range args {
+			tests = append(tests, must.Must1(ruletest.Load(path))...)
+		}
+		results := ruletest.RunAll(e, tests)
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		failed := 0
+		names := maps.Keys(results)
+		slices.Sort(names)
+		for _, name := range names {
+			var failures []string
+			for _, r := range results[name] {
+				if !r.Passed() {
+					failures = append(failures, fmt.Sprintf("%v: %v", r.Test, strings.ReplaceAll(r.Err.Error(), "\n", "\n    ")))
+				}
+			}
+			if len(failures) == 0 {
+				fmt.Fprintf(w, "PASS\t%v\t%v tests\n", name, len(results[name]))
+			} else {
+				failed++
+				fmt.Fprintf(w, "FAIL\t%v\t%v of %v tests failed\n", name, len(failures), len(results[name]))
+				w.Flush()
+				for _, f := range failures {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %v\n", f)
+				}
+			}
+		}
+		if *testRulesAll {
+			for _, r := range e.Rules() {
+				if results[r.Name()] == nil {
+					fmt.Fprintf(w, "NONE\t%v\tno tests\n", r.Name())
+				}
+			}
+		}
+		w.Flush()
+		if failed > 0 {
+			panic(fmt.Errorf("%v of %v rules failed", failed, len(results)))
+		}
+	},
+}
+
+var testRulesAll *bool
+
+func init() {
+	testRulesAll = testRulesCmd.Flags().Bool("all", false, "Also list rules that have no tests.")
+	rootCmd.AddCommand(testRulesCmd)
+}
@@ -0,0 +1,45 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/must"
+	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/rest"
+	"github.com/spf13/cobra"
+)
+
+var timelineCmd = &cobra.Command{
+	Use:   "timeline [GOAL...]",
+	Short: "Print correlated objects in chronological order.",
+	Long: `Search from the start objects to GOAL classes, or to the neighbours of the start objects if there are no goals.
+Print all objects found, in chronological order, with the path of rules that led to each object.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		e, _ := newEngine()
+		search := traverse.NeighbourSearch(depth)
+		if len(args) > 0 {
+			var goals []korrel8r.Class
+			for _, g := range args {
+				goals = append(goals, must.Must1(e.Class(g)))
+			}
+			search = traverse.GoalSearch(goals)
+		}
+		ctx, cancel := korrel8r.WithConstraint(context.Background(), constraint())
+		defer cancel()
+		s := start(e)
+		g, err := search(ctx, traverse.New(e, e.Graph()), s)
+		check(err)
+		newPrinter(os.Stdout).Print(rest.NewTimeline(g, s.Class))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(timelineCmd)
+	startFlags(timelineCmd)
+	constraintFlags(timelineCmd)
+	timelineCmd.Flags().IntVarP(&depth, "depth", "d", 2, "Depth of neighbourhood search, if there are no goals.")
+}
@@ -0,0 +1,33 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/must"
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
+)
+
+// traceEndpointEnv is the standard OpenTelemetry environment variable for the trace export URL.
+const traceEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+
+var traceEndpointFlag = rootCmd.PersistentFlags().String("trace-endpoint", os.Getenv(traceEndpointEnv),
+	"Export OpenTelemetry spans for korrel8r requests, searches and store queries to this OTLP/HTTP URL, e.g. http://localhost:4318/v1/traces")
+
+// StartTracing starts exporting spans if there is a trace endpoint, returns a function to flush and stop.
+func StartTracing() func() {
+	if *traceEndpointFlag == "" {
+		return func() {}
+	}
+	stop := must.Must1(tracing.Start(context.Background(), *traceEndpointFlag))
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := stop(ctx); err != nil {
+			log.Error(err, "Trace export failed", "endpoint", *traceEndpointFlag)
+		}
+	}
+}
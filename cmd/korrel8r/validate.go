@@ -0,0 +1,54 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/engine"
+	"github.com/spf13/cobra"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [CONFIG...]",
+	Short: "Check configuration files for problems. Exit with non-zero status if there are errors.",
+	Long: `Check configuration files for problems, including included files.
+If no CONFIG arguments are given, check the configuration from --config.
+
+Reports every problem found with file and line, including unknown domains and classes,
+invalid rule templates, template references to fields that do not exist on the start class,
+duplicate rules, unused aliases and unreachable classes.
+
+Exits with non-zero status if there are errors, or warnings with --strict.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			args = []string{*configFlag}
+		}
+		var problems config.Problems
+		for _, source := range args {
+			configs, err := config.Read(source)
+			if err != nil {
+				problems = append(problems, config.Problem{Message: err.Error()})
+				continue
+			}
+			problems = append(problems, engine.Validate(configs, domains()...)...)
+		}
+		for _, p := range problems {
+			fmt.Fprintln(cmd.OutOrStdout(), p)
+		}
+		if n := problems.Errors(); n > 0 {
+			panic(fmt.Errorf("%v errors, %v warnings", n, len(problems)-n))
+		}
+		if *validateStrict && len(problems) > 0 {
+			panic(fmt.Errorf("%v warnings", len(problems)))
+		}
+	},
+}
+
+var validateStrict *bool
+
+func init() {
+	validateStrict = validateCmd.Flags().Bool("strict", false, "Exit with non-zero status if there are warnings.")
+	rootCmd.AddCommand(validateCmd)
+}
@@ -3,14 +3,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/korrel8r/korrel8r/internal/pkg/build"
 	"github.com/korrel8r/korrel8r/internal/pkg/must"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
 	"github.com/korrel8r/korrel8r/pkg/rest"
+	"github.com/korrel8r/korrel8r/pkg/rest/auth"
 	"github.com/korrel8r/korrel8r/pkg/rest/docs"
 	"github.com/spf13/cobra"
 )
@@ -52,13 +57,32 @@ var webCmd = &cobra.Command{
 			}
 		}
 
+		authenticator := webAuth()
 		engine, configs := newEngine()
 		gin.SetMode(gin.ReleaseMode)
 		router := gin.New()
 		router.Use(gin.Recovery())
+		// Only trust X-Forwarded-For from listed proxies, it identifies clients for rate limits.
+		must.Must(router.SetTrustedProxies(*trustedProxiesFlag))
 		r, err := rest.New(engine, configs, router)
 		must.Must(err)
 		defer r.Close()
+		if *scopeHeaderFlag != "" {
+			r.Scope = rest.HeaderScope(*scopeHeaderFlag)
+		}
+		r.Authenticator, r.Impersonate = authenticator, *authorizeFlag == "impersonate"
+		r.BuildEngine = buildEngine // Rebuild with the same authorizer and recording options.
+		if *editConfigFlag {
+			if authenticator == nil {
+				panic(fmt.Errorf("--edit-config requires --authenticate"))
+			}
+			r.EditConfig = (&auth.AccessReview{Client: must.Must1(k8s.NewClient(nil))}).AuthorizeEdit
+		}
+		if *watchFlag > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go config.Watch(ctx, *configFlag, configs, *watchFlag, r.Reload)
+		}
 		s.Handler = router
 		if *profileFlag == "http" {
 			rest.WebProfile(router)
@@ -74,11 +98,49 @@ var webCmd = &cobra.Command{
 	},
 }
 
+// webAuth returns the authenticator selected by --authenticate, and sets the engine authorizer for --authorize.
+func webAuth() auth.Authenticator {
+	var authenticator auth.Authenticator
+	switch *authenticateFlag {
+	case "":
+		if *authorizeFlag != "" {
+			panic(fmt.Errorf("--authorize requires --authenticate"))
+		}
+		return nil
+	case "tokenreview":
+		authenticator = &auth.TokenReview{Client: must.Must1(k8s.NewClient(nil))}
+	case "oidc":
+		if *oidcIssuerFlag == "" || *oidcJWKSFlag == "" {
+			panic(fmt.Errorf("--oidc-issuer and --oidc-jwks are required for --authenticate=oidc"))
+		}
+		authenticator = &auth.OIDC{Issuer: *oidcIssuerFlag, Audience: *oidcAudienceFlag, Keys: must.Must1(auth.LoadJWKS(*oidcJWKSFlag))}
+	default:
+		panic(fmt.Errorf("invalid value for --authenticate: %q", *authenticateFlag))
+	}
+	switch *authorizeFlag {
+	case "":
+		panic(fmt.Errorf("--authenticate requires --authorize"))
+	case "impersonate":
+	case "accessreview":
+		authorizer = &auth.AccessReview{Client: must.Must1(k8s.NewClient(nil))}
+	default:
+		panic(fmt.Errorf("invalid value for --authorize: %q", *authorizeFlag))
+	}
+	return authenticator
+}
+
 var (
 	httpFlag, httpsFlag *string
 	certFlag, keyFlag   *string
 	specFlag            *string
-	WebProfile          func()
+	watchFlag           *time.Duration
+	scopeHeaderFlag     *string
+	trustedProxiesFlag  *[]string
+	editConfigFlag      *bool
+
+	authenticateFlag, authorizeFlag                *string
+	oidcIssuerFlag, oidcAudienceFlag, oidcJWKSFlag *string
+	WebProfile                                     func()
 )
 
 func init() {
@@ -88,4 +150,13 @@ func init() {
 	certFlag = webCmd.Flags().String("cert", "", "TLS certificate file (PEM format) for https")
 	keyFlag = webCmd.Flags().String("key", "", "Private key (PEM format) for https")
 	specFlag = webCmd.Flags().String("spec", "", "Dump swagger spec to a file, '-' for stdout.")
+	scopeHeaderFlag = webCmd.Flags().String("scope-header", "", "Restrict each request to the comma-separated namespaces in this header, '*' allows all namespaces.")
+	trustedProxiesFlag = webCmd.Flags().StringSlice("trusted-proxies", nil, "Comma-separated IP addresses or CIDRs of proxies trusted to set X-Forwarded-For. By default the client IP address is the connection address.")
+	authenticateFlag = webCmd.Flags().String("authenticate", "", "Authenticate callers: 'tokenreview' or 'oidc'. Stores are accessed with korrel8r's own credentials, see --authorize.")
+	authorizeFlag = webCmd.Flags().String("authorize", "", "Restrict store access for authenticated callers: 'impersonate' or 'accessreview'.")
+	oidcIssuerFlag = webCmd.Flags().String("oidc-issuer", "", "Required token issuer for --authenticate=oidc.")
+	oidcAudienceFlag = webCmd.Flags().String("oidc-audience", "", "Required token audience for --authenticate=oidc, optional.")
+	oidcJWKSFlag = webCmd.Flags().String("oidc-jwks", "", "JSON Web Key Set file to verify tokens for --authenticate=oidc.")
+	editConfigFlag = webCmd.Flags().Bool("edit-config", false, "Enable REST endpoints to edit rules, aliases and stores. Requires --authenticate, edits are authorized by a Kubernetes access review of resource 'config' in group 'korrel8r.io'.")
+	watchFlag = webCmd.Flags().Duration("watch", 0, "Interval to check configuration files for changes and reload, 0 disables reloading.")
 }
@@ -9,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -82,11 +83,14 @@ func TestMain_server_insecure(t *testing.T) {
 	u := startServer(t, http.DefaultClient, "http", "-c", "testdata/korrel8r.yaml").String() + "/domains"
 	assertDo(t, http.DefaultClient, `[
 {"name":"alert"},
+{"name":"event"},
+{"name":"incident"},
 {"name":"k8s"},
 {"name":"log"},
 {"name":"metric"},
 {"name":"mock","stores":[{"domain":"mock", "mockData":"testdata/mock_store.yaml"}]},
 {"name":"netflow"},
+{"name":"profile"},
 {"name":"trace"}
 ]`, "GET", u, "")
 }
@@ -97,11 +101,14 @@ func TestMain_server_secure(t *testing.T) {
 	u := startServer(t, h, "https", "--cert", filepath.Join(tmpDir, "tls.crt"), "--key", filepath.Join(tmpDir, "tls.key"), "-c", "testdata/korrel8r.yaml").String() + "/domains"
 	assertDo(t, h, `[
 {"name":"alert"},
+{"name":"event"},
+{"name":"incident"},
 {"name":"k8s"},
 {"name":"log"},
 {"name":"metric"},
 {"name":"mock","stores":[{"domain":"mock", "mockData":"testdata/mock_store.yaml"}]},
 {"name":"netflow"},
+{"name":"profile"},
 {"name":"trace"}
 ]`,
 		"GET", u, "")
@@ -146,3 +153,27 @@ func TestMain_concurrent_requests(t *testing.T) {
 	workers.Wait()
 	assert.Zero(t, failed.Load())
 }
+
+func TestMain_server_forwardedFor(t *testing.T) {
+	cfg := filepath.Join(t.TempDir(), "korrel8r.yaml")
+	testdata, err := filepath.Abs("testdata/korrel8r.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(cfg, []byte(fmt.Sprintf(`
+include: [%q]
+tuning:
+  clientRequestRate: {qps: 0.01, burst: 1}
+`, testdata)), 0666))
+	u := startServer(t, http.DefaultClient, "http", "-c", cfg).String() + "/domains"
+	status := func(forwardedFor string) int {
+		req, err := http.NewRequest("GET", u, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		return res.StatusCode
+	}
+	// A forged X-Forwarded-For does not get a new client limit.
+	assert.Equal(t, http.StatusOK, status("192.0.2.1"))
+	assert.Equal(t, http.StatusTooManyRequests, status("192.0.2.2"))
+}
@@ -0,0 +1,38 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/korrel8r/korrel8r/pkg/domains/event"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestArchivedEvent(t *testing.T) {
+	e := setup()
+	pod := k8s.New[corev1.Pod]("aNamespace", "foo")
+	ev := k8s.EventFor(pod, "a")
+
+	t.Run("K8sToEvent", func(t *testing.T) {
+		want := event.Query{InvolvedObject: event.ObjectReference{Namespace: "aNamespace", Name: "foo", Kind: "Pod", APIVersion: "v1"}}
+		testTraverse(t, e, k8s.ClassOf(pod), event.Class{}, []korrel8r.Object{pod}, want)
+	})
+
+	t.Run("EventToK8s", func(t *testing.T) {
+		want := k8s.NewQuery(k8s.ClassOf(pod), "aNamespace", "foo", nil, nil)
+		testTraverse(t, e, event.Class{}, k8s.ClassOf(pod), []korrel8r.Object{ev}, want)
+	})
+
+	t.Run("K8sToEvent_Deployment", func(t *testing.T) {
+		d := k8s.New[appsv1.Deployment]("aNamespace", "bar")
+		got, err := apply(e, "K8sToEvent", d)
+		if assert.NoError(t, err) {
+			assert.Equal(t, `event:event:{"involvedObject":{"namespace":"aNamespace","name":"bar","kind":"Deployment","apiVersion":"apps/v1"}}`, got.String())
+		}
+	})
+}
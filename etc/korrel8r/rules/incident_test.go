@@ -0,0 +1,74 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/korrel8r/korrel8r/pkg/domains/alert"
+	"github.com/korrel8r/korrel8r/pkg/domains/incident"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncidentRules(t *testing.T) {
+	e := setup()
+	a := &alert.Object{
+		Labels:      map[string]string{"alertname": "KubePodCrashLooping", "namespace": "foo"},
+		Fingerprint: "a8f2b3e4c5d6e7f8",
+		Receivers:   []alert.Receiver{{Name: "pagerduty"}, {Name: "email"}},
+	}
+	for _, x := range []struct {
+		rule  string
+		start korrel8r.Object
+		want  string
+	}{
+		{
+			rule:  "AlertToIncident",
+			start: a,
+			want:  `incident:incident:{"fingerprint":"a8f2b3e4c5d6e7f8"}`,
+		},
+		{
+			rule:  "AlertReceiverToIncident",
+			start: a,
+			want:  `incident:incident:{"receivers":["pagerduty","email"],"labels":{"alertname":"KubePodCrashLooping"}}`,
+		},
+		{
+			rule: "AlertReceiverToIncident",
+			start: &alert.Object{
+				Labels:    map[string]string{"alertname": `Bad"Name`},
+				Receivers: []alert.Receiver{{Name: `team "a"`}},
+			},
+			want: `incident:incident:{"receivers":["team \"a\""],"labels":{"alertname":"Bad\"Name"}}`,
+		},
+		{
+			rule:  "IncidentToAlert",
+			start: &incident.Incident{ID: "INC-1", Labels: map[string]string{"alertname": "KubePodCrashLooping", "namespace": "foo"}},
+			want:  `alert:alert:{"alertname":"KubePodCrashLooping","namespace":"foo"}`,
+		},
+	} {
+		t.Run(x.rule, func(t *testing.T) {
+			got, err := apply(e, x.rule, x.start)
+			if assert.NoError(t, err) {
+				assert.Equal(t, x.want, got.String())
+			}
+		})
+	}
+}
+
+func TestIncidentRules_notApplicable(t *testing.T) {
+	e := setup()
+	for _, x := range []struct {
+		rule  string
+		start korrel8r.Object
+	}{
+		{"AlertToIncident", &alert.Object{Labels: map[string]string{"alertname": "x"}}},
+		{"AlertReceiverToIncident", &alert.Object{Labels: map[string]string{"alertname": "x"}}},
+		{"IncidentToAlert", &incident.Incident{ID: "INC-1"}},
+	} {
+		t.Run(x.rule, func(t *testing.T) {
+			_, err := apply(e, x.rule, x.start)
+			assert.Error(t, err)
+		})
+	}
+}
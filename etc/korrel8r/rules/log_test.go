@@ -5,12 +5,14 @@ package rules_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
 	"github.com/korrel8r/korrel8r/pkg/domains/log"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/unique"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -61,3 +63,32 @@ func TestPodToLogs(t *testing.T) {
 		})
 	}
 }
+
+func TestLogToAlert(t *testing.T) {
+	e := setup()
+	when := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	start := log.Object{
+		"@timestamp": when.Format(time.RFC3339Nano),
+		"kubernetes": map[string]any{"namespace_name": "foo", "pod_name": "bar"},
+	}
+	q, err := apply(e, "LogToAlert", start)
+	require.NoError(t, err)
+	assert.Equal(t, `alert:alert:{"namespace":"foo","pod":"bar"}`, q.String())
+
+	// The goal query searches 5 minutes either side of the log record.
+	r, ok := e.Rule("LogToAlert").(korrel8r.Constrainer)
+	require.True(t, ok)
+	begin, end := when.Add(-time.Hour), when.Add(time.Hour)
+	c := &korrel8r.Constraint{Start: &begin, End: &end}
+	got, err := r.Constrain(start, c)
+	require.NoError(t, err)
+	assert.Equal(t, when.Add(-5*time.Minute), got.GetStart())
+	assert.Equal(t, when.Add(5*time.Minute), got.GetEnd())
+
+	// A log record without a timestamp leaves the constraint unchanged.
+	delete(start, "@timestamp")
+	got, err = r.Constrain(start, c)
+	require.NoError(t, err)
+	assert.Equal(t, begin, got.GetStart())
+	assert.Equal(t, end, got.GetEnd())
+}
@@ -127,3 +127,69 @@ func Test_NetflowFromK8S(t *testing.T) {
 		})
 	}
 }
+
+func Test_TopologyToK8S(t *testing.T) {
+	e := setup()
+	start := &netflow.Topology{
+		Src: netflow.Workload{Namespace: "foo", Kind: "Deployment", Name: "bar"},
+		Dst: netflow.Workload{Namespace: "baz", Kind: "Pod", Name: "qux"},
+	}
+	for _, x := range []struct {
+		rule  string
+		start *netflow.Topology
+		want  string
+	}{
+		{
+			rule: "TopologyToSrcK8s",
+			want: `k8s:Deployment.v1.apps:{"namespace":"foo","name":"bar"}`,
+		},
+		{
+			rule: "TopologyToDstK8s",
+			want: `k8s:Pod.v1.:{"namespace":"baz","name":"qux"}`,
+		},
+	} {
+		t.Run(x.rule, func(t *testing.T) {
+			got, err := apply(e, x.rule, start)
+			if assert.NoError(t, err) {
+				assert.Equal(t, x.want, got.String())
+			}
+		})
+	}
+}
+
+func Test_TopologyFromK8S(t *testing.T) {
+	e := setup()
+	for _, x := range []struct {
+		rule  string
+		start k8s.Object
+		want  string
+	}{
+		{
+			rule:  "K8sSrcToTopology",
+			start: k8s.New[corev1.Pod]("bar", "foo"),
+			want:  `netflow:topology:{SrcK8S_Type="Pod", SrcK8S_Namespace="bar"} | json | SrcK8S_Name="foo"`,
+		},
+		{
+			rule:  "K8sSrcOwnerToTopology",
+			start: k8s.New[appv1.Deployment]("bar", "foo"),
+			want:  `netflow:topology:{SrcK8S_Namespace="bar", SrcK8S_OwnerName="foo"}`,
+		},
+		{
+			rule:  "K8sDstToTopology",
+			start: k8s.New[corev1.Pod]("bar", "foo"),
+			want:  `netflow:topology:{DstK8S_Type="Pod", DstK8S_Namespace="bar"} | json | DstK8S_Name="foo"`,
+		},
+		{
+			rule:  "K8sDstOwnerToTopology",
+			start: k8s.New[appv1.Deployment]("bar", "foo"),
+			want:  `netflow:topology:{DstK8S_Namespace="bar", DstK8S_OwnerName="foo"}`,
+		},
+	} {
+		t.Run(x.rule, func(t *testing.T) {
+			got, err := apply(e, x.rule, x.start)
+			if assert.NoError(t, err) {
+				assert.Equal(t, x.want, got.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/korrel8r/korrel8r/pkg/domains/alert"
+	"github.com/korrel8r/korrel8r/pkg/domains/profile"
+	"github.com/korrel8r/korrel8r/pkg/domains/trace"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestProfileRules(t *testing.T) {
+	e := setup()
+	for _, x := range []struct {
+		rule  string
+		start korrel8r.Object
+		want  string
+	}{
+		{
+			rule:  "PodToProfile",
+			start: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}},
+			want:  `profile:profile:{namespace="bar", pod="foo"}`,
+		},
+		{
+			rule:  "ProfileToPod",
+			start: &profile.Profile{Type: "process_cpu:cpu:nanoseconds:cpu:nanoseconds", Labels: map[string]string{"namespace": "bar", "pod": "foo"}},
+			want:  `k8s:Pod.v1.:{"namespace":"bar","name":"foo"}`,
+		},
+		{
+			rule: "TraceToProfile",
+			start: &trace.Span{
+				Context:    trace.SpanContext{TraceID: "232323", SpanID: "3d48369744164bd0"},
+				Attributes: map[string]any{"service.name": "checkout", "pyroscope.profile.id": "3d48369744164bd0"},
+			},
+			want: `profile:profile:{service_name="checkout", span_id="3d48369744164bd0"}`,
+		},
+		{
+			rule:  "AlertToProfile",
+			start: &alert.Object{Labels: map[string]string{"namespace": "bar", "pod": "foo"}},
+			want:  `profile:profile:{namespace="bar", pod="foo"}`,
+		},
+	} {
+		t.Run(x.rule, func(t *testing.T) {
+			got, err := apply(e, x.rule, x.start)
+			if assert.NoError(t, err) {
+				assert.Equal(t, x.want, got.String())
+			}
+		})
+	}
+}
+
+func TestTraceToProfile_noService(t *testing.T) {
+	e := setup()
+	_, err := apply(e, "TraceToProfile", &trace.Span{Attributes: map[string]any{}})
+	assert.Error(t, err)
+	got, err := apply(e, "TraceToProfile", &trace.Span{Attributes: map[string]any{"pyroscope.profile.id": "3d48369744164bd0"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `profile:profile:{span_id="3d48369744164bd0"}`, got.String())
+	}
+}
+
+func TestTraceToProfile_notProfiled(t *testing.T) {
+	e := setup()
+	_, err := apply(e, "TraceToProfile", &trace.Span{Attributes: map[string]any{"service.name": "checkout"}})
+	assert.Error(t, err, "span without a profile ID has no profile")
+}
+
+func TestAlertToProfile_noPod(t *testing.T) {
+	e := setup()
+	for _, labels := range []map[string]string{{"namespace": "bar"}, {"pod": "foo"}, {}} {
+		_, err := apply(e, "AlertToProfile", &alert.Object{Labels: labels})
+		assert.Error(t, err, "%v", labels)
+	}
+}
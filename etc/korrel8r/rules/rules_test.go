@@ -14,15 +14,19 @@ import (
 
 	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/domains/alert"
+	"github.com/korrel8r/korrel8r/pkg/domains/event"
+	"github.com/korrel8r/korrel8r/pkg/domains/incident"
 	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
 	"github.com/korrel8r/korrel8r/pkg/domains/log"
 	"github.com/korrel8r/korrel8r/pkg/domains/metric"
 	"github.com/korrel8r/korrel8r/pkg/domains/netflow"
+	"github.com/korrel8r/korrel8r/pkg/domains/profile"
 	"github.com/korrel8r/korrel8r/pkg/domains/trace"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/rules/ruletest"
 	"github.com/korrel8r/korrel8r/pkg/unique"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/exp/maps"
@@ -45,7 +49,7 @@ func setup() *engine.Engine {
 		panic(err)
 	}
 	e, err := engine.Build().
-		Domains(k8s.Domain, log.Domain, netflow.Domain, trace.Domain, alert.Domain, metric.Domain).
+		Domains(k8s.Domain, log.Domain, netflow.Domain, trace.Domain, alert.Domain, metric.Domain, event.Domain, profile.Domain, incident.Domain).
 		Config(configs).
 		Stores(s).Engine()
 	if err != nil {
@@ -88,3 +92,23 @@ func apply(e *engine.Engine, ruleName string, start korrel8r.Object) (korrel8r.Q
 }
 
 var rules = unique.Set[string]{}
+
+func TestValidate(t *testing.T) {
+	configs, err := config.Read("all.yaml")
+	assert.NoError(t, err)
+	problems := engine.Validate(configs, k8s.Domain, log.Domain, netflow.Domain, trace.Domain, alert.Domain, metric.Domain, event.Domain, profile.Domain, incident.Domain)
+	assert.Zero(t, problems.Errors(), "%v", problems)
+}
+
+func TestDeclarativeRules(t *testing.T) {
+	e := setup()
+	tests, err := ruletest.Load("testdata")
+	assert.NoError(t, err)
+	for i := range tests {
+		t.Run(tests[i].String(), func(t *testing.T) {
+			r := ruletest.Run(e, &tests[i])
+			tested(r.Rule)
+			assert.NoError(t, r.Err)
+		})
+	}
+}
@@ -10,8 +10,14 @@ import (
 	"os"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/asciidoc"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
+	logdomain "github.com/korrel8r/korrel8r/pkg/domains/log"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 )
 
+// funcDomains are domains that provide template functions, documented by name.
+var funcDomains = []korrel8r.Domain{k8s.Domain, logdomain.Domain}
+
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags] PKGSPEC...:\n", os.Args[0])
@@ -29,11 +35,22 @@ func main() {
 		domains, err := asciidoc.Load(flag.Args()[i])
 		check(err)
 		for _, d := range domains {
+			d.TemplateFuncs = templateFuncs(d.Package.Name)
 			check(d.Write(os.Stdout))
 		}
 	}
 }
 
+// templateFuncs returns the template functions of the domain with the given name.
+func templateFuncs(name string) []korrel8r.TemplateFunc {
+	for _, d := range funcDomains {
+		if tf, ok := d.(korrel8r.TemplateFuncer); ok && d.Name() == name {
+			return tf.TemplateFuncs()
+		}
+	}
+	return nil
+}
+
 func check(err error) {
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
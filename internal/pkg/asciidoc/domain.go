@@ -15,6 +15,7 @@ import (
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/davecgh/go-spew/spew"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -45,7 +46,8 @@ func Load(pkgSpec string) ([]*Domain, error) {
 type Domain struct {
 	Package        *doc.Package // Package is the doc.Package object.
 	DocLinkBaseURL string
-	pkg            *packages.Package // Package for internal type manipulation.
+	TemplateFuncs  []korrel8r.TemplateFunc // TemplateFuncs provided by the domain, if any.
+	pkg            *packages.Package       // Package for internal type manipulation.
 }
 
 //go:embed templates/domains.tmpl.adoc
@@ -0,0 +1,141 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// package elastic is a limited client for the Elasticsearch and OpenSearch search API:
+// https://www.elastic.co/guide/en/elasticsearch/reference/current/search-search.html
+//
+// Only the subset of the API needed by korrel8r stores is implemented.
+// OpenSearch is API compatible with Elasticsearch for this subset.
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
+
+// Query is a search query in the Elasticsearch query DSL.
+type Query map[string]any
+
+// Request body for a search.
+type Request struct {
+	Query Query            `json:"query,omitempty"`
+	Size  int              `json:"size"`
+	Sort  []map[string]any `json:"sort,omitempty"`
+}
+
+// Hit is a single document returned by a search.
+type Hit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Source json.RawMessage `json:"_source"`
+}
+
+// CollectFunc is called for each hit returned by a search.
+type CollectFunc func(*Hit)
+
+// Client for Elasticsearch HTTP API
+type Client struct {
+	c    *http.Client
+	base *url.URL
+}
+
+func New(c *http.Client, base *url.URL) *Client { return &Client{c: c, base: base} }
+
+// TimestampField is the field used to restrict and sort searches by time.
+const TimestampField = "@timestamp"
+
+// MaxSize is the largest number of hits returned by a search.
+// It is the default index.max_result_window, larger sizes are rejected by the server.
+const MaxSize = 10000
+
+// Search the indices matching index for documents matching query with a Constraint.
+//
+// The Constraint time interval is added as a range filter on [TimestampField].
+// Hits are collected in order of increasing timestamp, the most recent hits are returned if there is a limit.
+// At most [MaxSize] hits are returned, even if the Constraint has no limit or a larger one.
+func (c *Client) Search(ctx context.Context, index string, query Query, constraint *korrel8r.Constraint, collect CollectFunc) error {
+	req := NewRequest(query, constraint)
+	u := c.base.ResolveReference(&url.URL{Path: path.Join("/", c.base.Path, index, searchPath)})
+	r := response{}
+	if err := c.post(ctx, u, req, &r); err != nil {
+		return err
+	}
+	// Search is sorted by descending time to get the most recent hits, collect in ascending order.
+	for i := len(r.Hits.Hits) - 1; i >= 0; i-- {
+		collect(&r.Hits.Hits[i])
+	}
+	return nil
+}
+
+const searchPath = "_search"
+
+// NewRequest returns a search request for query with the Constraint applied.
+func NewRequest(query Query, constraint *korrel8r.Constraint) *Request {
+	filter := []any{query}
+	start, end := constraint.GetStart(), constraint.GetEnd()
+	if !start.IsZero() || !end.IsZero() {
+		r := map[string]any{}
+		if !start.IsZero() {
+			r["gte"] = start.UTC().Format(time.RFC3339Nano)
+		}
+		if !end.IsZero() {
+			r["lte"] = end.UTC().Format(time.RFC3339Nano)
+		}
+		filter = append(filter, Query{"range": map[string]any{TimestampField: r}})
+	}
+	return &Request{
+		Query: Query{"bool": map[string]any{"filter": filter}},
+		Size:  size(constraint.GetLimit()),
+		Sort:  []map[string]any{{TimestampField: map[string]any{"order": "desc"}}},
+	}
+}
+
+// size returns the search size for a limit, the server default is too small if there is no limit.
+func size(limit int) int {
+	if limit <= 0 || limit > MaxSize {
+		return MaxSize
+	}
+	return limit
+}
+
+func (c *Client) post(ctx context.Context, u *url.URL, body, result any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		if b, err := io.ReadAll(resp.Body); err == nil && len(b) > 0 {
+			return fmt.Errorf("%v: %v", resp.Status, string(b))
+		}
+		return fmt.Errorf("%v", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Data types for search responses.
+
+type response struct {
+	Hits hits `json:"hits"`
+}
+
+type hits struct {
+	Hits []Hit `json:"hits"`
+}
@@ -31,6 +31,16 @@ type Entry struct {
 // CollectFunc is called for each entry returned by a query.
 type CollectFunc func(*Entry)
 
+// Sample is a single element of the vector returned by a metric query.
+type Sample struct {
+	Labels Labels
+	Time   time.Time
+	Value  float64
+}
+
+// SampleFunc is called for each sample returned by a metric query.
+type SampleFunc func(*Sample)
+
 // Client for loki HTTP API
 type Client struct {
 	c    *http.Client
@@ -52,6 +62,19 @@ func (c *Client) GetStack(ctx context.Context, logQL, tenant string, constraint
 	return c.get(ctx, u, collect)
 }
 
+// Metric uses the plain Loki API to evaluate a LogQL metric query as an instant query at the end of the Constraint interval.
+func (c *Client) Metric(ctx context.Context, logQL string, constraint *korrel8r.Constraint, collect SampleFunc) error {
+	u := instantURL(logQL, constraint)
+	return c.metric(ctx, u, collect)
+}
+
+// MetricStack uses the LokiStack tenant API to evaluate a LogQL metric query, see [Client.Metric]
+func (c *Client) MetricStack(ctx context.Context, logQL, tenant string, constraint *korrel8r.Constraint, collect SampleFunc) error {
+	u := instantURL(logQL, constraint)
+	u.Path = path.Join(lokiStackPath, tenant, u.Path)
+	return c.metric(ctx, u, collect)
+}
+
 const ( // Query URL keywords
 	query     = "query"
 	direction = "direction"
@@ -60,6 +83,7 @@ const ( // Query URL keywords
 
 	lokiStackPath  = "/api/logs/v1/"
 	queryRangePath = "/loki/api/v1/query_range"
+	queryPath      = "/loki/api/v1/query"
 )
 
 func queryURL(logQL string, c *korrel8r.Constraint) *url.URL {
@@ -73,7 +97,7 @@ func queryURL(logQL string, c *korrel8r.Constraint) *url.URL {
 	if !end.IsZero() {
 		v.Add("end", formatTime(end))
 	}
-	if !c.Start.IsZero() {
+	if !start.IsZero() {
 		v.Add("start", formatTime(start))
 		if end.IsZero() { // Can't have start without end.
 			v.Add("end", formatTime(time.Now()))
@@ -82,6 +106,28 @@ func queryURL(logQL string, c *korrel8r.Constraint) *url.URL {
 	return &url.URL{Path: queryRangePath, RawQuery: v.Encode()}
 }
 
+func instantURL(logQL string, c *korrel8r.Constraint) *url.URL {
+	v := url.Values{}
+	v.Add(query, logQL)
+	if end := c.GetEnd(); !end.IsZero() {
+		v.Add("time", formatTime(end))
+	}
+	return &url.URL{Path: queryPath, RawQuery: v.Encode()}
+}
+
+// Range returns the duration of the Constraint interval formatted as a LogQL range, e.g. "[3600s]".
+// If the interval is not set, [korrel8r.DefaultDuration] is used.
+func Range(c *korrel8r.Constraint) string {
+	d := korrel8r.DefaultDuration
+	if start, end := c.GetStart(), c.GetEnd(); !start.IsZero() {
+		if end.IsZero() {
+			end = time.Now()
+		}
+		d = end.Sub(start)
+	}
+	return fmt.Sprintf("[%vs]", max(int64(d.Seconds()), 1))
+}
+
 func formatTime(t time.Time) string { return strconv.FormatInt(t.UTC().UnixNano(), 10) }
 
 func (c *Client) get(ctx context.Context, u *url.URL, collect CollectFunc) error {
@@ -100,6 +146,24 @@ func (c *Client) get(ctx context.Context, u *url.URL, collect CollectFunc) error
 	return nil
 }
 
+func (c *Client) metric(ctx context.Context, u *url.URL, collect SampleFunc) error {
+	u = c.base.ResolveReference(u)
+	qr := metricResponse{}
+	if err := impl.Get(ctx, u, c.c, &qr); err != nil {
+		return err
+	}
+	if qr.Status != "success" {
+		return fmt.Errorf("expected 'status: success' in %v", qr)
+	}
+	if qr.Data.ResultType != "vector" {
+		return fmt.Errorf("expected 'resultType: vector' in %v", qr)
+	}
+	for _, s := range qr.Data.Result {
+		collect(&Sample{Labels: s.Metric, Time: s.Value.Time, Value: s.Value.Value})
+	}
+	return nil
+}
+
 // least returns index of non-empty stream with the smallest timestamp, or -1 if all are empty.
 func least(streams []stream) int {
 	// NOTE assumes query direction is "backward"
@@ -145,6 +209,51 @@ type stream struct {
 	Values []value           `json:"values"` // [ timestamp, line ] pairs
 }
 
+type metricResponse struct {
+	Status string     `json:"status"`
+	Data   metricData `json:"data"`
+}
+
+type metricData struct {
+	ResultType string   `json:"resultType"`
+	Result     []sample `json:"result"`
+}
+
+type sample struct {
+	Metric map[string]string `json:"metric"` // Labels for the sample
+	Value  sampleValue       `json:"value"`  // [ timestamp, value ] pair
+}
+
+type sampleValue struct {
+	Time  time.Time
+	Value float64
+}
+
+// UnmarshalJSON unmarshals sampleValue from array [unixSeconds, "value"]
+func (v *sampleValue) UnmarshalJSON(data []byte) error {
+	var a [2]json.RawMessage
+	if err := json.Unmarshal(data, &a); err != nil {
+		return err
+	}
+	var (
+		ts float64
+		s  string
+	)
+	if err := json.Unmarshal(a[0], &ts); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(a[1], &s); err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	v.Time = time.Unix(0, int64(ts*float64(time.Second)))
+	v.Value = f
+	return nil
+}
+
 type value struct {
 	Time time.Time
 	Line string
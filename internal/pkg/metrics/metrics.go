@@ -0,0 +1,34 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// package metrics has the Prometheus registry for korrel8r's own metrics.
+//
+// Packages create their metrics with [Factory], the REST server serves them with [Handler].
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace is the prefix for all korrel8r metric names.
+const Namespace = "korrel8r"
+
+var (
+	// Registry for all korrel8r metrics, including Go runtime and process metrics.
+	Registry = prometheus.NewRegistry()
+	// Factory creates metrics registered with [Registry].
+	Factory = promauto.With(Registry)
+)
+
+func init() {
+	Registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+}
+
+// Handler serves the metrics in [Registry].
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
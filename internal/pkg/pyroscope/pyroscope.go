@@ -0,0 +1,109 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// package pyroscope is a limited client for the Pyroscope HTTP API: https://grafana.com/docs/pyroscope/latest/reference-server-api/
+// Should be replaced with an official Pyroscope client package if/when one is available.
+package pyroscope
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// Labels is a map of label names to values identifying a profile series.
+type Labels map[string]string
+
+// Client for Pyroscope HTTP API
+type Client struct {
+	c    *http.Client
+	base *url.URL
+}
+
+func New(c *http.Client, base *url.URL) *Client { return &Client{c: c, base: base} }
+
+const seriesPath = "/querier.v1.QuerierService/Series"
+
+// Series returns the label sets of profile series matching a label selector in a time interval.
+func (c *Client) Series(ctx context.Context, selector string, start, end time.Time) ([]Labels, error) {
+	req := seriesRequest{Matchers: []string{selector}, Start: start.UnixMilli(), End: end.UnixMilli()}
+	resp := seriesResponse{}
+	if err := c.post(ctx, seriesPath, req, &resp); err != nil {
+		return nil, err
+	}
+	var result []Labels
+	for _, ls := range resp.LabelsSet {
+		l := Labels{}
+		for _, p := range ls.Labels {
+			l[p.Name] = p.Value
+		}
+		result = append(result, l)
+	}
+	return result, nil
+}
+
+// RenderURL returns a URL to render the profile selected by query in a time interval.
+func (c *Client) RenderURL(query string, start, end time.Time) *url.URL {
+	v := url.Values{}
+	v.Add("query", query)
+	v.Add("from", fmt.Sprintf("%v", start.Unix()))
+	v.Add("until", fmt.Sprintf("%v", end.Unix()))
+	u := *c.base
+	u.Path = path.Join(u.Path, renderPath)
+	u.RawQuery = v.Encode()
+	return &u
+}
+
+const renderPath = "/pyroscope/render"
+
+func (c *Client) post(ctx context.Context, p string, body, result any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	u := *c.base
+	u.Path = path.Join(u.Path, p)
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		if b, err := io.ReadAll(resp.Body); err == nil && len(b) > 0 {
+			return fmt.Errorf("%v: %v", resp.Status, string(b))
+		}
+		return fmt.Errorf("%v", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Data types for the querier API.
+
+type seriesRequest struct {
+	Matchers []string `json:"matchers"`
+	Start    int64    `json:"start"` // Milliseconds
+	End      int64    `json:"end"`   // Milliseconds
+}
+
+type seriesResponse struct {
+	LabelsSet []labelsSet `json:"labelsSet"`
+}
+
+type labelsSet struct {
+	Labels []labelPair `json:"labels"`
+}
+
+type labelPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
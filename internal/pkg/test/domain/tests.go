@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
@@ -22,15 +23,21 @@ func (f *Fixture) Test(t *testing.T) {
 	}
 }
 
+// mockConstraint returns a constraint with a time interval that includes all recorded mock data.
+// The engine drops objects outside the constraint interval, the default interval is too recent.
+func mockConstraint() *korrel8r.Constraint {
+	return &korrel8r.Constraint{Start: ptr.To(time.Time{}), End: ptr.To(time.Now())}
+}
+
 // TestGet tests only the domain Get overhead using mock in-memory stores.
 func (f *Fixture) TestGet(t *testing.T) {
 	t.Helper()
 	r := graph.NewResult(f.Query.Class())
-	require.NoError(t, f.MockEngine.Get(context.Background(), f.Query, &korrel8r.Constraint{}, r))
+	require.NoError(t, f.MockEngine.Get(context.Background(), f.Query, mockConstraint(), r))
 	if assert.Equal(t, BatchLen, len(r.List()), "wrong number of results: %v", f.Query) {
 		if _, ok := f.Query.Class().(korrel8r.IDer); ok { // Only test de-duplication for classes with ID.
 			t.Run("TestGet_dedup", func(t *testing.T) {
-				require.NoError(t, f.MockEngine.Get(context.Background(), f.Query, &korrel8r.Constraint{}, r))
+				require.NoError(t, f.MockEngine.Get(context.Background(), f.Query, mockConstraint(), r))
 				assert.Equal(t, BatchLen, len(r.List()), "de-duplication failed: %v", f.Query)
 			})
 		}
@@ -41,7 +48,9 @@ func (f *Fixture) TestMarshalUnmashal(t *testing.T) {
 	t.Helper()
 	c := f.Query.Class()
 	r := graph.NewResult(c)
-	require.NoError(t, f.MockEngine.Get(context.Background(), f.Query, &korrel8r.Constraint{Limit: ptr.To(1)}, r))
+	constraint := mockConstraint()
+	constraint.Limit = ptr.To(1)
+	require.NoError(t, f.MockEngine.Get(context.Background(), f.Query, constraint, r))
 	require.GreaterOrEqual(t, len(r.List()), 1)
 	o := r.List()[0]
 	bytes, err := json.Marshal(o)
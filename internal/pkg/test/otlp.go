@@ -0,0 +1,63 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// OTLPCollector is an in-process OTLP/HTTP trace collector stub that keeps the spans it receives.
+type OTLPCollector struct {
+	*httptest.Server
+
+	lock  sync.Mutex
+	spans []*tracepb.Span
+}
+
+// NewOTLPCollector starts a collector that is closed when the test ends.
+func NewOTLPCollector(t testing.TB) *OTLPCollector {
+	c := &OTLPCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(c.export))
+	t.Cleanup(c.Close)
+	return c
+}
+
+// Endpoint is the URL for exporting traces to the collector.
+func (c *OTLPCollector) Endpoint() string { return c.URL + "/v1/traces" }
+
+// Spans returns the spans received so far.
+func (c *OTLPCollector) Spans() []*tracepb.Span {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*tracepb.Span(nil), c.spans...)
+}
+
+func (c *OTLPCollector) export(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	var req coltracepb.ExportTraceServiceRequest
+	if err == nil {
+		err = proto.Unmarshal(body, &req)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.lock.Lock()
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans = append(c.spans, ss.Spans...)
+		}
+	}
+	c.lock.Unlock()
+	resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	_, _ = w.Write(resp)
+}
@@ -0,0 +1,64 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// package tracing emits OpenTelemetry spans for korrel8r's own operations.
+//
+// Packages create spans with [Tracer]. Spans are dropped unless [Start] is called to export them.
+package tracing
+
+import (
+	"context"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/build"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name of the korrel8r tracer.
+const Name = "github.com/korrel8r/korrel8r"
+
+// Attribute keys for korrel8r spans.
+const (
+	Domain  = attribute.Key("korrel8r.domain")
+	Class   = attribute.Key("korrel8r.class")
+	Query   = attribute.Key("korrel8r.query")
+	Count   = attribute.Key("korrel8r.count")
+	Rule    = attribute.Key("korrel8r.rule")
+	Goals   = attribute.Key("korrel8r.goals")
+	Depth   = attribute.Key("korrel8r.depth")
+	Classes = attribute.Key("korrel8r.classes")
+)
+
+// Tracer returns the korrel8r tracer from the global TracerProvider.
+func Tracer() trace.Tracer { return otel.Tracer(Name) }
+
+// Start exports spans to an OTLP/HTTP endpoint URL, for example "http://localhost:4318/v1/traces".
+// Incoming W3C trace context headers are propagated.
+// Returns a function that flushes pending spans and stops exporting.
+func Start(ctx context.Context, endpoint string) (stop func(context.Context) error, err error) {
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName("korrel8r"), semconv.ServiceVersion(build.Version))))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// End ends a span, recording err if it is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,39 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test"
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestStart(t *testing.T) {
+	collector := test.NewOTLPCollector(t)
+	stop, err := tracing.Start(context.Background(), collector.Endpoint())
+	require.NoError(t, err)
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "parent")
+	_, child := tracing.Tracer().Start(ctx, "child")
+	child.SetAttributes(tracing.Count.Int(3))
+	tracing.End(child, errors.New("failed"))
+	tracing.End(parent, nil)
+	require.NoError(t, stop(context.Background()))
+
+	spans := collector.Spans()
+	require.Len(t, spans, 2)
+	c, p := spans[0], spans[1]
+	assert.Equal(t, "child", c.Name)
+	assert.Equal(t, "parent", p.Name)
+	assert.Equal(t, p.SpanId, c.ParentSpanId)
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, c.Status.Code)
+	assert.Equal(t, "failed", c.Status.Message)
+	assert.Equal(t, string(tracing.Count), c.Attributes[0].Key)
+	assert.Equal(t, int64(3), c.Attributes[0].Value.GetIntValue())
+}
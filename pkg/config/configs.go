@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/logging"
 	"github.com/korrel8r/korrel8r/pkg/unique"
@@ -26,14 +27,50 @@ var log = logging.Log()
 // If a configuration has an Include section, also loads all referenced configurations.
 // Relative paths in Include are relative to the location of file containing them.
 func Load(fileOrURL string) (Configs, error) {
+	configs, err := Read(fileOrURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := expand(configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// Read loads all configurations like [Load], but does not expand aliases.
+func Read(fileOrURL string) (Configs, error) {
 	l := loader{loaded: unique.NewSet[string]()}
 	if err := l.load(fileOrURL); err != nil {
 		return nil, err
 	}
-	if err := expand(l.configs); err != nil {
+	return l.configs, nil
+}
+
+// Expand returns a copy of the configurations with aliases expanded in all rules, and no aliases.
+// The original configurations are not modified.
+func (cs Configs) Expand() (Configs, error) {
+	c := cs.Clone()
+	if err := expand(c); err != nil {
 		return nil, err
 	}
-	return l.configs, nil
+	return c, nil
+}
+
+// Clone returns a copy of the configurations.
+// The rule, alias, store and include lists are copied, the elements are not.
+func (cs Configs) Clone() Configs {
+	if cs == nil {
+		return nil
+	}
+	clone := make(Configs, len(cs))
+	for i, c := range cs {
+		c.Rules = slices.Clone(c.Rules)
+		c.Aliases = slices.Clone(c.Aliases)
+		c.Stores = slices.Clone(c.Stores)
+		c.Include = slices.Clone(c.Include)
+		clone[i] = c
+	}
+	return clone
 }
 
 type loader struct {
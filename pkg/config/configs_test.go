@@ -108,3 +108,45 @@ func TestConfigs_Expand_sameGroupDifferentDomain(t *testing.T) {
 		}}
 	assert.Equal(t, want, c)
 }
+
+func TestConfigs_Validate(t *testing.T) {
+	c, err := Read("testdata/invalid.yaml")
+	require.NoError(t, err)
+	var got []string
+	for _, p := range c.Validate(NewLocator()) {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{
+		`testdata/invalid.yaml:2: error: alias "nodomain": no domain`,
+		`testdata/invalid.yaml:4: error: alias "noclasses": no classes`,
+		`testdata/invalid.yaml:11: error: rule "r1": duplicate rule name, first defined at testdata/invalid.yaml:7`,
+		`testdata/invalid.yaml:11: error: rule "r1": no goal domain`,
+		`testdata/invalid.yaml:15: error: rule "": no name`,
+		`testdata/invalid.yaml:15: error: rule "": no result query`,
+		`testdata/invalid.yaml:15: error: rule "": negative cost`,
+		`testdata/invalid.yaml:20: error: store has no domain`,
+		`testdata/invalid.yaml:21: error: invalid store qps: "fast"`,
+		`testdata/invalid.yaml: error: tuning: domain "foo": negative cost`,
+		`testdata/invalid.yaml: error: tuning: clientRequestRate: invalid rate`,
+		`testdata/invalid.yaml: error: tuning: maxTraversals: negative limit`,
+		`testdata/invalid.yaml:2: warning: alias "nodomain": not used`,
+		`testdata/invalid.yaml:4: warning: alias "noclasses": not used`,
+	}, got)
+}
+
+func TestStore_Rate(t *testing.T) {
+	r, err := Store{StoreKeyQPS: "2.5"}.Rate()
+	require.NoError(t, err)
+	assert.Equal(t, &Rate{QPS: 2.5}, r)
+	assert.Equal(t, 3, r.GetBurst())
+	r, err = Store{StoreKeyQPS: "10", StoreKeyBurst: "20"}.Rate()
+	require.NoError(t, err)
+	assert.Equal(t, 20, r.GetBurst())
+	r, err = Store{}.Rate()
+	require.NoError(t, err)
+	assert.Nil(t, r)
+	for _, s := range []Store{{StoreKeyQPS: "x"}, {StoreKeyQPS: "-1"}, {StoreKeyQPS: "1", StoreKeyBurst: "x"}} {
+		_, err = s.Rate()
+		assert.Error(t, err, "%v", s)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Rate is a token bucket rate limit.
+type Rate struct {
+	// QPS is the sustained rate, in requests per second.
+	QPS float64 `json:"qps"`
+	// Burst is the max number of requests allowed at once, default is QPS rounded up.
+	Burst int `json:"burst,omitempty"`
+}
+
+// GetBurst returns Burst, or the default burst if Burst is 0.
+func (r *Rate) GetBurst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	return max(1, int(math.Ceil(r.QPS)))
+}
+
+// Rate returns the query rate budget from the [StoreKeyQPS] and [StoreKeyBurst] keys,
+// or nil if there is no [StoreKeyQPS] key.
+func (s Store) Rate() (*Rate, error) {
+	if s[StoreKeyQPS] == "" {
+		return nil, nil
+	}
+	r := &Rate{}
+	var err error
+	if r.QPS, err = strconv.ParseFloat(s[StoreKeyQPS], 64); err != nil || r.QPS <= 0 {
+		return nil, fmt.Errorf("invalid store %v: %q", StoreKeyQPS, s[StoreKeyQPS])
+	}
+	if s[StoreKeyBurst] != "" {
+		if r.Burst, err = strconv.Atoi(s[StoreKeyBurst]); err != nil || r.Burst < 0 {
+			return nil, fmt.Errorf("invalid store %v: %q", StoreKeyBurst, s[StoreKeyBurst])
+		}
+	}
+	return r, nil
+}
@@ -38,6 +38,8 @@ const (
 	StoreKeyErrorCount = "errorCount"           // Count of errors on a store.
 	StoreKeyMock       = "mockData"             // Store loads mock data from a file or directory.
 	StoreKeyCA         = "certificateAuthority" // Path to CA certificate.
+	StoreKeyQPS        = "qps"                  // Max queries per second sent to the store, default unlimited.
+	StoreKeyBurst      = "burst"                // Max burst of queries to the store, see [Rate.Burst].
 )
 
 // Rule configures a template rule.
@@ -66,6 +68,10 @@ type Rule struct {
 	// Each template is applied to an object from one of the `start` classes.
 	// If any template yields a blank string or an error, the rule does not apply.
 	Result ResultSpec `json:"result"`
+
+	// Cost of following this rule, multiplied by the cost of the goal domain.
+	// Goal searches follow the lowest-cost paths. If omitted, the cost is 1.
+	Cost float64 `json:"cost,omitempty"`
 }
 
 // ClassSpec specifies one or more classes.
@@ -82,6 +88,26 @@ type ClassSpec struct {
 type ResultSpec struct {
 	// Query template generates a query object suitable for the goal store.
 	Query string `json:"query"`
+
+	// Constraint templates narrow the constraint for the goal query, optional.
+	Constraint *ConstraintSpec `json:"constraint,omitempty"`
+}
+
+// ConstraintSpec contains templates to narrow the time interval of the constraint for a goal query.
+//
+// Each template is applied to the start object, and generates a time in RFC 3339 format,
+// or a blank string to leave that end of the interval unchanged.
+// The resulting interval is the intersection with the interval of the request constraint.
+//
+// For example, to search 5 minutes either side of the timestamp of a log record:
+//
+//	start: '{{timeAdd "-5m" (index . "@timestamp")}}'
+//	end: '{{timeAdd "5m" (index . "@timestamp")}}'
+type ConstraintSpec struct {
+	// Start template generates the start of the time interval.
+	Start string `json:"start,omitempty"`
+	// End template generates the end of the time interval.
+	End string `json:"end,omitempty"`
 }
 
 // Class defines a shortcut name for a set of existing classes.
@@ -99,4 +125,26 @@ type Tuning struct {
 	// RequestTimeout cancel requests if they last longer than this timeout.
 	// Cancelling a correlation operation may return an error or a partial result (HTTP 206).
 	RequestTimeout Duration `json:"requestTimeout,omitempty"`
+
+	// DomainCosts is the cost of querying each domain, by domain name.
+	// The cost of following a rule is the rule cost multiplied by the cost of its goal domain.
+	// Domains that are not listed have a cost of 1, unless LearnCosts is true.
+	DomainCosts map[string]float64 `json:"domainCosts,omitempty"`
+
+	// LearnCosts if true, domains not listed in DomainCosts have a cost learned from
+	// the observed latency and result counts of their stores.
+	LearnCosts bool `json:"learnCosts,omitempty"`
+
+	// RequestRate limits the rate of all REST API requests.
+	// Requests over the limit are rejected with HTTP 429 (Too Many Requests).
+	RequestRate *Rate `json:"requestRate,omitempty"`
+
+	// ClientRequestRate limits the rate of REST API requests from each client.
+	// Clients are identified by authenticated user name if there is one, by IP address otherwise.
+	ClientRequestRate *Rate `json:"clientRequestRate,omitempty"`
+
+	// MaxTraversals limits the number of correlation searches (goals, neighbours, timelines)
+	// in progress at the same time. Requests over the limit are rejected with HTTP 429.
+	// A multi-start search counts one search per start.
+	MaxTraversals int `json:"maxTraversals,omitempty"`
 }
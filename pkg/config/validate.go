@@ -0,0 +1,209 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem found when validating a configuration.
+type Problem struct {
+	Source  string `json:"source,omitempty"` // Source file or URL.
+	Line    int    `json:"line,omitempty"`   // Line number in source, 0 if unknown.
+	Warning bool   `json:"warning,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	kind := "error"
+	if p.Warning {
+		kind = "warning"
+	}
+	loc := p.Source
+	if p.Line > 0 {
+		loc = fmt.Sprintf("%v:%v", p.Source, p.Line)
+	}
+	if loc == "" {
+		return fmt.Sprintf("%v: %v", kind, p.Message)
+	}
+	return fmt.Sprintf("%v: %v: %v", loc, kind, p.Message)
+}
+
+// Problems is a list of validation problems.
+type Problems []Problem
+
+// Errors returns the number of problems that are not warnings.
+func (ps Problems) Errors() (n int) {
+	for _, p := range ps {
+		if !p.Warning {
+			n++
+		}
+	}
+	return n
+}
+
+// Section names of lists in a configuration, used to locate items.
+const (
+	SectionRules   = "rules"
+	SectionAliases = "aliases"
+	SectionStores  = "stores"
+)
+
+// Locator finds line numbers of configuration items in their source.
+// Sources are read and parsed at most once.
+type Locator struct{ docs map[string]*yaml.Node }
+
+func NewLocator() *Locator { return &Locator{docs: map[string]*yaml.Node{}} }
+
+// Line returns the line number of the item at index in a section of source, or 0 if not found.
+func (l *Locator) Line(source, section string, index int) int {
+	doc, ok := l.docs[source]
+	if !ok {
+		doc = &yaml.Node{}
+		if b, err := readFileOrURL(source); err != nil || yaml.Unmarshal(b, doc) != nil {
+			doc = nil
+		}
+		l.docs[source] = doc
+	}
+	if doc == nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return 0
+	}
+	m := doc.Content[0].Content
+	for i := 0; i+1 < len(m); i += 2 {
+		if m[i].Value == section && m[i+1].Kind == yaml.SequenceNode && index < len(m[i+1].Content) {
+			return m[i+1].Content[index].Line
+		}
+	}
+	return 0
+}
+
+// Problem returns a problem located at the item at index in a section of source.
+func (l *Locator) Problem(source, section string, index int, warning bool, format string, args ...any) Problem {
+	return Problem{Source: source, Line: l.Line(source, section, index), Warning: warning, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks configurations for problems that do not depend on the domains in use.
+// Unlike [Load], it reports all problems found, not just the first.
+//
+// Errors: rules without a name, duplicate rule names, rules without a domain or result, negative costs,
+// aliases without a domain or classes, duplicate aliases, and stores without a domain.
+// Warnings: duplicate rules with different names, aliases that are never used.
+func (cs Configs) Validate(l *Locator) Problems {
+	var problems Problems
+	add := func(p Problem) { problems = append(problems, p) }
+
+	type named struct{ domain, name string }
+	aliases := map[named]bool{} // Value is true if the alias is used
+	for _, c := range cs {
+		for i, a := range c.Aliases {
+			switch {
+			case a.Domain == "":
+				add(l.Problem(c.Source, SectionAliases, i, false, "alias %q: no domain", a.Name))
+			case len(a.Classes) == 0:
+				add(l.Problem(c.Source, SectionAliases, i, false, "alias %q: no classes", a.Name))
+			}
+			k := named{a.Domain, a.Name}
+			if _, ok := aliases[k]; ok {
+				add(l.Problem(c.Source, SectionAliases, i, false, "alias %q: duplicate alias name", a.Name))
+			}
+			aliases[k] = false
+		}
+	}
+	use := func(domain string, classes []string) {
+		for _, class := range classes {
+			if _, ok := aliases[named{domain, class}]; ok {
+				aliases[named{domain, class}] = true
+			}
+		}
+	}
+	for _, c := range cs {
+		for _, a := range c.Aliases {
+			use(a.Domain, a.Classes)
+		}
+	}
+
+	type located struct {
+		source string
+		index  int
+	}
+	names := map[string]located{}
+	var seen []Rule
+	var seenAt []located
+	for _, c := range cs {
+		for i, r := range c.Rules {
+			problem := func(warning bool, format string, args ...any) {
+				add(l.Problem(c.Source, SectionRules, i, warning, "rule %q: %v", r.Name, fmt.Sprintf(format, args...)))
+			}
+			use(r.Start.Domain, r.Start.Classes)
+			use(r.Goal.Domain, r.Goal.Classes)
+			if r.Name == "" {
+				problem(false, "no name")
+			} else if at, ok := names[r.Name]; ok {
+				problem(false, "duplicate rule name, first defined at %v:%v", at.source, l.Line(at.source, SectionRules, at.index))
+			} else {
+				names[r.Name] = located{c.Source, i}
+			}
+			if r.Start.Domain == "" {
+				problem(false, "no start domain")
+			}
+			if r.Goal.Domain == "" {
+				problem(false, "no goal domain")
+			}
+			if r.Result.Query == "" {
+				problem(false, "no result query")
+			}
+			if r.Cost < 0 {
+				problem(false, "negative cost")
+			}
+			unnamed := r
+			unnamed.Name = ""
+			if j := slices.IndexFunc(seen, func(r2 Rule) bool { return reflect.DeepEqual(r2, unnamed) }); j >= 0 {
+				at := seenAt[j]
+				problem(true, "duplicate of rule defined at %v:%v", at.source, l.Line(at.source, SectionRules, at.index))
+			} else {
+				seen = append(seen, unnamed)
+				seenAt = append(seenAt, located{c.Source, i})
+			}
+		}
+		for i, s := range c.Stores {
+			if s[StoreKeyDomain] == "" {
+				add(l.Problem(c.Source, SectionStores, i, false, "store has no domain"))
+			}
+			if _, err := s.Rate(); err != nil {
+				add(l.Problem(c.Source, SectionStores, i, false, "%v", err))
+			}
+		}
+	}
+	if len(cs) > 0 && cs[0].Tuning != nil {
+		costs := cs[0].Tuning.DomainCosts
+		for _, domain := range slices.Sorted(maps.Keys(costs)) {
+			if costs[domain] < 0 {
+				add(Problem{Source: cs[0].Source, Message: fmt.Sprintf("tuning: domain %q: negative cost", domain)})
+			}
+		}
+		for _, r := range []struct {
+			name string
+			rate *Rate
+		}{{"requestRate", cs[0].Tuning.RequestRate}, {"clientRequestRate", cs[0].Tuning.ClientRequestRate}} {
+			if r.rate != nil && (r.rate.QPS <= 0 || r.rate.Burst < 0) {
+				add(Problem{Source: cs[0].Source, Message: fmt.Sprintf("tuning: %v: invalid rate", r.name)})
+			}
+		}
+		if cs[0].Tuning.MaxTraversals < 0 {
+			add(Problem{Source: cs[0].Source, Message: "tuning: maxTraversals: negative limit"})
+		}
+	}
+	for _, c := range cs {
+		for i, a := range c.Aliases {
+			if !aliases[named{a.Domain, a.Name}] {
+				add(l.Problem(c.Source, SectionAliases, i, true, "alias %q: not used", a.Name))
+			}
+		}
+	}
+	return problems
+}
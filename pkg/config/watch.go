@@ -0,0 +1,81 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Sources returns the source file or URL of each configuration.
+func (cs Configs) Sources() []string {
+	sources := make([]string, 0, len(cs))
+	for _, c := range cs {
+		sources = append(sources, c.Source)
+	}
+	return sources
+}
+
+// Watch polls the configuration at source and all included configurations for changes.
+//
+// configs is the currently loaded configuration.
+// When the contents of any source change, the configuration is re-read and passed to reload.
+// Aliases are not expanded, see [Read].
+// Errors loading the configuration or returned by reload are logged, and the current configuration remains in use.
+// Changed sources are only re-loaded once, until they are changed again.
+//
+// Watch returns when ctx is cancelled.
+func Watch(ctx context.Context, source string, configs Configs, interval time.Duration, reload func(Configs) error) {
+	sums := checksums(configs.Sources())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		newSums := checksums(slices.Collect(maps.Keys(sums)))
+		if maps.Equal(sums, newSums) {
+			continue
+		}
+		log.V(1).Info("Configuration: Changed, reloading", "config", source)
+		newConfigs, err := Read(source)
+		if err == nil {
+			// Included sources may have changed, checksum new sources.
+			loaded := map[string][sha256.Size]byte{}
+			for _, s := range newConfigs.Sources() {
+				if sum, ok := newSums[s]; ok {
+					loaded[s] = sum
+				} else {
+					maps.Copy(loaded, checksums([]string{s}))
+				}
+			}
+			newSums = loaded
+			err = reload(newConfigs)
+		}
+		sums = newSums
+		if err != nil {
+			log.Error(err, "Configuration: Reload failed, keeping current configuration", "config", source)
+			continue
+		}
+		log.Info("Configuration: Reloaded", "config", source)
+	}
+}
+
+// checksums returns a map of source to a checksum of the source contents.
+// Sources that can't be read have an empty checksum.
+func checksums(sources []string) map[string][sha256.Size]byte {
+	sums := map[string][sha256.Size]byte{}
+	for _, s := range sources {
+		if b, err := readFileOrURL(s); err == nil {
+			sums[s] = sha256.Sum256(b)
+		} else {
+			sums[s] = [sha256.Size]byte{}
+		}
+	}
+	return sums
+}
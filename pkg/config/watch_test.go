@@ -0,0 +1,68 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	main, included := filepath.Join(dir, "main.yaml"), filepath.Join(dir, "included.yaml")
+	write := func(path, content string) { require.NoError(t, os.WriteFile(path, []byte(content), 0666)) }
+	rule := func(name string) string {
+		return "rules: [{name: " + name + ", start: {domain: a}, goal: {domain: b}, result: {query: q}}]\n"
+	}
+	write(main, "include: [included.yaml]\n")
+	write(included, rule("r1"))
+	configs, err := Load(main)
+	require.NoError(t, err)
+
+	reloaded := make(chan Configs, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go Watch(ctx, main, configs, time.Millisecond, func(c Configs) error {
+		reloaded <- c
+		return nil
+	})
+	next := func() Configs {
+		t.Helper()
+		select {
+		case c := <-reloaded:
+			return c
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout waiting for reload")
+			return nil
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond) // Let Watch compute initial checksums.
+
+	// Change to included file.
+	write(included, rule("r2"))
+	c := next()
+	require.Len(t, c, 2)
+	assert.Equal(t, "r2", c[1].Rules[0].Name)
+
+	// Change include tree, then change the newly included file.
+	other := filepath.Join(dir, "other.yaml")
+	write(other, rule("r3"))
+	write(main, "include: [other.yaml]\n")
+	c = next()
+	assert.Equal(t, []string{main, other}, c.Sources())
+	write(other, rule("r4"))
+	assert.Equal(t, "r4", next()[1].Rules[0].Name)
+
+	// Invalid configuration is not passed to reload, watching continues.
+	write(other, "not: valid: yaml")
+	time.Sleep(10 * time.Millisecond)
+	write(other, rule("r5"))
+	assert.Equal(t, "r5", next()[1].Rules[0].Name)
+}
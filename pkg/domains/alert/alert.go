@@ -52,11 +52,12 @@ import (
 )
 
 var (
-	_ korrel8r.Domain = Domain
-	_ korrel8r.Class  = Class{}
-	_ korrel8r.Query  = Query{}
-	_ korrel8r.Store  = &Store{}
-	_ korrel8r.Object = &Object{}
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.Query       = Query{}
+	_ korrel8r.Store       = &Store{}
+	_ korrel8r.Object      = &Object{}
 )
 
 var Domain = domain{}
@@ -123,6 +124,27 @@ func (c Class) Preview(o korrel8r.Object) string {
 	return ""
 }
 
+// Severity uses the "severity" label: "critical" or "error" is an error, "warning" is a warning.
+func (c Class) Severity(o korrel8r.Object) korrel8r.Severity {
+	if o, ok := o.(*Object); ok {
+		switch o.Labels["severity"] {
+		case "critical", "error":
+			return korrel8r.SeverityError
+		case "warning":
+			return korrel8r.SeverityWarning
+		}
+	}
+	return korrel8r.SeverityNone
+}
+
+// Timestamp is the interval from StartsAt to EndsAt, EndsAt may be zero for active Prometheus alerts.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	if o, ok := o.(*Object); ok {
+		return o.StartsAt, o.EndsAt
+	}
+	return time.Time{}, time.Time{}
+}
+
 // Object contains alert data, passed as *Object when used as a korrel8r.Object.
 type Object struct {
 	// Common fields.
@@ -325,8 +347,8 @@ func (s Store) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.Constr
 	}
 
 	for _, a := range alerts {
-		// Only include alerts that overlap with the constraint interval.
-		if c.CompareTime(a.StartsAt) <= 0 && c.CompareTime(a.EndsAt) >= 0 {
+		// Only include alerts that overlap with the constraint interval, in allowed namespaces.
+		if c.Overlaps(Class{}.Timestamp(a)) && c.AllowsNamespace(a.Labels["namespace"]) {
 			result.Append(a)
 		}
 	}
@@ -0,0 +1,352 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package event is a domain for Kubernetes events archived in a Loki log store.
+//
+// Kubernetes events are deleted from the API server after a short time (1 hour by default).
+// An event router or event exporter can forward events to a log store, where they are kept
+// for as long as other logs.
+// This domain queries the archived events, so they are available for correlation after they expire.
+//
+// # Class
+//
+// There is a single class `event:event`.
+//
+// # Object
+//
+// An object is a Kubernetes [corev1.Event], the same type as the `k8s:Event` class.
+//
+// # Query
+//
+// A query is a JSON object with optional fields to match the involved object, reason and type of events.
+// For example:
+//
+//	event:event:{"involvedObject":{"namespace":"foo","name":"bar","kind":"Pod"},"type":"Warning"}
+//
+// # Store
+//
+// Archived events are log records, so the store configuration is similar to the log domain.
+// Events are stored in the infrastructure tenant of a LokiStack:
+//
+//	domain: event
+//	lokiStack: URL_OF_LOKISTACK_PROXY
+//
+// To connect to plain loki store use:
+//
+//	domain: event
+//	loki: URL_OF_LOKI
+//
+// An optional "selector" field is a LogQL stream selector for event log records.
+// The default selects logs from the openshift-logging event router container:
+//
+//	selector: '{kubernetes_namespace_name="openshift-logging",kubernetes_container_name="eventrouter"}'
+//
+// The store recognizes event log records in any of these formats:
+//   - Event router output: `{"verb":"ADDED","event":{...}}`
+//   - ViaQ log records with a `kubernetes.event` field, or with event router output in the `message` field.
+//   - Plain serialized Event objects, for example from an event exporter.
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/loki"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	// Verify implementing interfaces.
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Store       = &store{}
+	_ korrel8r.Store       = &stackStore{}
+	_ korrel8r.Query       = Query{}
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.IDer        = Class{}
+	_ korrel8r.Previewer   = Class{}
+)
+
+// Domain for Kubernetes events archived in a log store.
+var Domain = domain{}
+
+type domain struct{}
+
+func (domain) Name() string                     { return "event" }
+func (d domain) String() string                 { return d.Name() }
+func (domain) Description() string              { return "Kubernetes events archived in a log store." }
+func (domain) Class(name string) korrel8r.Class { return Class{} }
+func (domain) Classes() []korrel8r.Class        { return []korrel8r.Class{Class{}} }
+func (d domain) Query(s string) (korrel8r.Query, error) {
+	_, q, err := impl.UnmarshalQueryString[Query](d, s)
+	return q, err
+}
+
+const (
+	StoreKeyLoki      = "loki"
+	StoreKeyLokiStack = "lokiStack"
+	StoreKeySelector  = "selector"
+)
+
+// DefaultSelector is the LogQL stream selector for events forwarded by the openshift-logging event router.
+const DefaultSelector = `{kubernetes_namespace_name="openshift-logging",kubernetes_container_name="eventrouter"}`
+
+func (domain) Store(s any) (korrel8r.Store, error) {
+	cs, err := impl.TypeAssert[config.Store](s)
+	if err != nil {
+		return nil, err
+	}
+	hc, err := k8s.NewHTTPClient(cs)
+	if err != nil {
+		return nil, err
+	}
+	selector := cs[StoreKeySelector]
+	loki, lokiStack := cs[StoreKeyLoki], cs[StoreKeyLokiStack]
+	switch {
+
+	case loki != "" && lokiStack != "":
+		return nil, fmt.Errorf("can't set both loki and lokiStack URLs")
+
+	case loki != "":
+		u, err := url.Parse(loki)
+		if err != nil {
+			return nil, err
+		}
+		return NewPlainLokiStore(u, hc, selector)
+
+	case lokiStack != "":
+		u, err := url.Parse(lokiStack)
+		if err != nil {
+			return nil, err
+		}
+		return NewLokiStackStore(u, hc, selector)
+
+	default:
+		return nil, fmt.Errorf("must set one of loki or lokiStack URLs")
+	}
+}
+
+// Class singleton `event:event` for archived Kubernetes events.
+type Class struct{}
+
+func (c Class) Domain() korrel8r.Domain { return Domain }
+func (c Class) Name() string            { return "event" }
+func (c Class) String() string          { return impl.ClassString(c) }
+func (c Class) Description() string {
+	return "Kubernetes Event objects archived in a log store."
+}
+func (c Class) Unmarshal(b []byte) (korrel8r.Object, error) { return impl.UnmarshalAs[Object](b) }
+
+// ID is the event UID. Updates to the same event have the same ID.
+// An event without a UID is identified by namespace, name and time.
+func (c Class) ID(o korrel8r.Object) any {
+	if e, _ := o.(Object); e != nil {
+		if e.UID != "" {
+			return e.UID
+		}
+		start, _ := k8s.EventTimestamp(e)
+		return fmt.Sprintf("%v/%v@%v", e.Namespace, e.Name, start.UTC().Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+func (c Class) Preview(o korrel8r.Object) string {
+	return impl.Preview(o, func(e Object) string { return e.Message })
+}
+
+// Severity is a warning for events of type "Warning".
+func (c Class) Severity(o korrel8r.Object) korrel8r.Severity {
+	if e, _ := o.(Object); e != nil && e.Type == corev1.EventTypeWarning {
+		return korrel8r.SeverityWarning
+	}
+	return korrel8r.SeverityNone
+}
+
+// Timestamp is the interval from the first to the last occurrence of the event.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	if e, _ := o.(Object); e != nil {
+		return k8s.EventTimestamp(e)
+	}
+	return time.Time{}, time.Time{}
+}
+
+// Object is a Kubernetes Event.
+type Object = *corev1.Event
+
+// Query matches archived events. Empty fields match any value.
+type Query struct {
+	// InvolvedObject matches the object the event is about.
+	InvolvedObject ObjectReference `json:"involvedObject,omitempty"`
+	// Reason matches the event reason, for example "BackOff".
+	Reason string `json:"reason,omitempty"`
+	// Type matches the event type: "Normal" or "Warning".
+	Type string `json:"type,omitempty"`
+}
+
+// ObjectReference matches the involved object of an event.
+type ObjectReference struct {
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty"`
+}
+
+func (q Query) Class() korrel8r.Class { return Class{} }
+func (q Query) Data() string          { b, _ := json.Marshal(q); return string(b) }
+func (q Query) String() string        { return impl.QueryString(q) }
+
+// Matches returns true if the event matches all non-empty fields of the query.
+func (q Query) Matches(e Object) bool {
+	match := func(want, got string) bool { return want == "" || want == got }
+	ref := e.InvolvedObject
+	return match(q.InvolvedObject.Namespace, ref.Namespace) &&
+		match(q.InvolvedObject.Name, ref.Name) &&
+		match(q.InvolvedObject.Kind, ref.Kind) &&
+		match(q.InvolvedObject.APIVersion, ref.APIVersion) &&
+		match(q.Reason, e.Reason) &&
+		match(q.Type, e.Type)
+}
+
+// LogQL returns a LogQL query for events matching q, using a stream selector for event log records.
+//
+// Line filters select candidate records, the store checks each event with [Query.Matches].
+func (q Query) LogQL(selector string) string {
+	if selector == "" {
+		selector = DefaultSelector
+	}
+	b := &strings.Builder{}
+	b.WriteString(selector)
+	for _, s := range []string{q.InvolvedObject.Name, q.InvolvedObject.Namespace, q.InvolvedObject.Kind, q.Reason, q.Type} {
+		if s != "" {
+			fmt.Fprintf(b, " |= %v", strconv.Quote(s))
+		}
+	}
+	return b.String()
+}
+
+// NewPlainLokiStore returns a store that uses plain Loki URLs.
+func NewPlainLokiStore(base *url.URL, h *http.Client, selector string) (korrel8r.Store, error) {
+	return &store{Client: loki.New(h, base), selector: selector}, nil
+}
+
+// NewLokiStackStore returns a store that uses LokiStack observatorium-style URLs.
+func NewLokiStackStore(base *url.URL, h *http.Client, selector string) (korrel8r.Store, error) {
+	return &stackStore{store: store{Client: loki.New(h, base), selector: selector}}, nil
+}
+
+type store struct {
+	*loki.Client
+	selector string
+}
+
+func (store) Domain() korrel8r.Domain { return Domain }
+
+func (s *store) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) error {
+	q, err := impl.TypeAssert[Query](query)
+	if err != nil {
+		return err
+	}
+	events := newEvents(q)
+	if err := s.Client.Get(ctx, q.LogQL(s.selector), constraint, events.collect); err != nil {
+		return err
+	}
+	events.appendTo(result, constraint)
+	return nil
+}
+
+type stackStore struct{ store }
+
+// tenant for events forwarded by the event router.
+const tenant = "infrastructure"
+
+func (s *stackStore) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) error {
+	q, err := impl.TypeAssert[Query](query)
+	if err != nil {
+		return err
+	}
+	events := newEvents(q)
+	if err := s.Client.GetStack(ctx, q.LogQL(s.selector), tenant, constraint, events.collect); err != nil {
+		return err
+	}
+	events.appendTo(result, constraint)
+	return nil
+}
+
+// events collects matching events from log entries, keeping only the latest version of each event.
+type events struct {
+	query Query
+	list  []Object
+	index map[types.UID]int
+	times []time.Time
+}
+
+func newEvents(q Query) *events { return &events{query: q, index: map[types.UID]int{}} }
+
+func (ev *events) collect(e *loki.Entry) {
+	o := parse(e.Line)
+	if o == nil || !ev.query.Matches(o) {
+		return
+	}
+	if i, ok := ev.index[o.UID]; ok && o.UID != "" {
+		if e.Time.After(ev.times[i]) { // Replace with the later version.
+			ev.list[i], ev.times[i] = o, e.Time
+		}
+		return
+	}
+	ev.index[o.UID] = len(ev.list)
+	ev.list = append(ev.list, o)
+	ev.times = append(ev.times, e.Time)
+}
+
+// appendTo appends events to result in order of the time they were last logged,
+// excluding events in namespaces that are not allowed by the constraint.
+func (ev *events) appendTo(result korrel8r.Appender, constraint *korrel8r.Constraint) {
+	order := make([]int, len(ev.list))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(i, j int) int { return ev.times[i].Compare(ev.times[j]) })
+	for _, i := range order {
+		if constraint.AllowsNamespace(ev.list[i].Namespace) {
+			result.Append(ev.list[i])
+		}
+	}
+}
+
+// parse an event from a log line, return nil if the line is not a recognized event record.
+func parse(line string) Object {
+	var record struct {
+		Event      *corev1.Event `json:"event"`
+		Message    string        `json:"message"`
+		Kubernetes struct {
+			Event *corev1.Event `json:"event"`
+		} `json:"kubernetes"`
+	}
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		return nil
+	}
+	switch {
+	case record.Event != nil: // Event router
+		return record.Event
+	case record.Kubernetes.Event != nil: // ViaQ
+		return record.Kubernetes.Event
+	case strings.HasPrefix(record.Message, "{"): // ViaQ with unparsed event router output
+		return parse(record.Message)
+	}
+	o := &corev1.Event{}
+	if err := json.Unmarshal([]byte(line), o); err != nil || o.InvolvedObject.Kind == "" {
+		return nil
+	}
+	return o
+}
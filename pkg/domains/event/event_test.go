@@ -0,0 +1,100 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package event_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/domain"
+	"github.com/korrel8r/korrel8r/pkg/domains/event"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixture = domain.Fixture{Query: event.Query{InvolvedObject: event.ObjectReference{Namespace: "openshift-etcd"}}}
+
+func TestEventDomain(t *testing.T)      { fixture.Test(t) }
+func BenchmarkEventDomain(b *testing.B) { fixture.Benchmark(b) }
+
+func TestQuery(t *testing.T) {
+	q, err := event.Domain.Query(`event:event:{"involvedObject":{"namespace":"foo","name":"bar","kind":"Pod"},"type":"Warning"}`)
+	require.NoError(t, err)
+	assert.Equal(t, event.Query{InvolvedObject: event.ObjectReference{Namespace: "foo", Name: "bar", Kind: "Pod"}, Type: "Warning"}, q)
+	assert.Equal(t, `{kubernetes_namespace_name="openshift-logging",kubernetes_container_name="eventrouter"} |= "bar" |= "foo" |= "Pod" |= "Warning"`,
+		q.(event.Query).LogQL(""))
+}
+
+// Log lines in the formats recognized by the store.
+var lines = []string{
+	// Event router
+	`{"verb":"ADDED","event":{"metadata":{"name":"bar.1","namespace":"foo","uid":"1"},"involvedObject":{"kind":"Pod","namespace":"foo","name":"bar","apiVersion":"v1"},"reason":"BackOff","message":"first","type":"Warning","count":1}}`,
+	// ViaQ record, different pod
+	`{"message":"other","kubernetes":{"namespace_name":"openshift-logging","event":{"metadata":{"name":"baz.1","namespace":"foo","uid":"2"},"involvedObject":{"kind":"Pod","namespace":"foo","name":"baz","apiVersion":"v1"},"reason":"BackOff","message":"other","type":"Warning"}}}`,
+	// Plain event, update of the first event.
+	`{"metadata":{"name":"bar.1","namespace":"foo","uid":"1"},"involvedObject":{"kind":"Pod","namespace":"foo","name":"bar","apiVersion":"v1"},"reason":"BackOff","message":"updated","type":"Warning","count":2}`,
+	// ViaQ record with event router output in the message, different reason.
+	`{"message":"{\"verb\":\"ADDED\",\"event\":{\"metadata\":{\"name\":\"bar.2\",\"namespace\":\"foo\",\"uid\":\"3\"},\"involvedObject\":{\"kind\":\"Pod\",\"namespace\":\"foo\",\"name\":\"bar\",\"apiVersion\":\"v1\"},\"reason\":\"Killing\",\"message\":\"killed\",\"type\":\"Normal\"}}"}`,
+	// Not an event
+	`{"message":"not an event"}`,
+}
+
+func TestStore_Get(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/logs/v1/infrastructure/loki/api/v1/query_range", r.URL.Path)
+		assert.Contains(t, r.URL.Query().Get("query"), `{kubernetes_container_name="router"}`)
+		values := []string{}
+		for i := len(lines) - 1; i >= 0; i-- { // Direction is backward
+			values = append(values, `["`+strconv.Itoa(1000+i)+`",`+strconv.Quote(lines[i])+`]`)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{},"values":[` + strings.Join(values, ",") + `]}]}}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := event.NewLokiStackStore(u, server.Client(), `{kubernetes_container_name="router"}`)
+	require.NoError(t, err)
+
+	var result graph.ListResult
+	q := event.Query{InvolvedObject: event.ObjectReference{Namespace: "foo", Name: "bar", Kind: "Pod"}}
+	require.NoError(t, s.Get(context.Background(), q, nil, &result))
+	require.Len(t, result, 2)
+	assert.Equal(t, "killed", result[1].(event.Object).Message)
+	e := result[0].(event.Object)
+	assert.Equal(t, "updated", e.Message)
+	assert.Equal(t, int32(2), e.Count)
+	assert.Equal(t, "updated", event.Class{}.Preview(e))
+}
+
+func TestStore_Get_noUID(t *testing.T) {
+	noUID := []string{
+		`{"metadata":{"name":"bar.1","namespace":"foo"},"involvedObject":{"kind":"Pod","namespace":"foo","name":"bar"},"message":"one","lastTimestamp":"2024-01-01T10:00:00Z"}`,
+		`{"metadata":{"name":"bar.2","namespace":"foo"},"involvedObject":{"kind":"Pod","namespace":"foo","name":"bar"},"message":"two","lastTimestamp":"2024-01-01T10:00:00Z"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := []string{}
+		for i, line := range noUID {
+			values = append(values, `["`+strconv.Itoa(1000+i)+`",`+strconv.Quote(line)+`]`)
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{},"values":[` + strings.Join(values, ",") + `]}]}}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := event.NewLokiStackStore(u, server.Client(), `{kubernetes_container_name="router"}`)
+	require.NoError(t, err)
+
+	// Events without a UID are distinct objects in a de-duplicated result.
+	result := graph.NewResult(event.Class{})
+	q := event.Query{InvolvedObject: event.ObjectReference{Namespace: "foo", Name: "bar"}}
+	require.NoError(t, s.Get(context.Background(), q, nil, result))
+	var messages []string
+	for _, o := range result.List() {
+		messages = append(messages, o.(event.Object).Message)
+	}
+	assert.ElementsMatch(t, []string{"one", "two"}, messages)
+}
@@ -0,0 +1,313 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package incident is a domain for incidents or tickets in an external incident management system.
+//
+// Alerts are often forwarded by Alertmanager receivers to an incident system, which groups them into incidents.
+// This domain links alerts to the incidents they belong to.
+//
+// # Class
+//
+// There is a single class `incident:incident`.
+//
+// # Object
+//
+// An [Incident] has an ID, a title and status, the Alertmanager receiver that created it,
+// and the fingerprints and common labels of the alerts it contains.
+//
+// # Query
+//
+// A query is a JSON [Query] object, non-empty fields must all match. For example:
+//
+//	incident:incident:{"fingerprint":"a8f2b3e4c5d6e7f8"}
+//	incident:incident:{"receivers":["pagerduty"],"labels":{"alertname":"KubePodCrashLooping"}}
+//
+// # Store
+//
+// The store is a generic REST service, configured with the URL of the incident search endpoint:
+//
+//	domain: incident
+//	incidents: URL_OF_INCIDENT_SEARCH
+//
+// The store sends a GET request with the non-empty query fields and constraint as URL parameters:
+//
+//	id, fingerprint, receiver (repeated), label (repeated, NAME=VALUE), start, end (RFC 3339), limit
+//
+// The service responds with a JSON array of incident objects.
+// A small adapter service can translate this API for a specific incident system.
+//
+// For testing, or to use exported incidents, the store can read a local JSON or YAML file containing an array of incidents:
+//
+//	domain: incident
+//	file: PATH_TO_INCIDENTS_FILE
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	// Verify implementing interfaces.
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Store       = &restStore{}
+	_ korrel8r.Store       = &fileStore{}
+	_ korrel8r.Query       = Query{}
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.IDer        = Class{}
+	_ korrel8r.Previewer   = Class{}
+)
+
+// Domain for incidents in an external incident management system.
+var Domain = domain{}
+
+type domain struct{}
+
+func (domain) Name() string                     { return "incident" }
+func (d domain) String() string                 { return d.Name() }
+func (domain) Description() string              { return "Incidents in an external incident management system." }
+func (domain) Class(name string) korrel8r.Class { return Class{} }
+func (domain) Classes() []korrel8r.Class        { return []korrel8r.Class{Class{}} }
+func (d domain) Query(s string) (korrel8r.Query, error) {
+	_, q, err := impl.UnmarshalQueryString[Query](d, s)
+	return q, err
+}
+
+const (
+	StoreKeyIncidents = "incidents"
+	StoreKeyFile      = "file"
+)
+
+func (domain) Store(s any) (korrel8r.Store, error) {
+	cs, err := impl.TypeAssert[config.Store](s)
+	if err != nil {
+		return nil, err
+	}
+	incidents, file := cs[StoreKeyIncidents], cs[StoreKeyFile]
+	switch {
+
+	case incidents != "" && file != "":
+		return nil, fmt.Errorf("can't set both incidents URL and file")
+
+	case incidents != "":
+		hc, err := k8s.NewHTTPClient(cs)
+		if err != nil {
+			return nil, err
+		}
+		u, err := url.Parse(incidents)
+		if err != nil {
+			return nil, err
+		}
+		return NewStore(u, hc)
+
+	case file != "":
+		return NewFileStore(file)
+
+	default:
+		return nil, fmt.Errorf("must set one of incidents URL or file")
+	}
+}
+
+// Class singleton `incident:incident`.
+type Class struct{}
+
+func (c Class) Domain() korrel8r.Domain { return Domain }
+func (c Class) Name() string            { return "incident" }
+func (c Class) String() string          { return impl.ClassString(c) }
+func (c Class) Description() string {
+	return "An incident or ticket grouping related alerts."
+}
+func (c Class) Unmarshal(b []byte) (korrel8r.Object, error) { return impl.UnmarshalAs[Object](b) }
+func (c Class) ID(o korrel8r.Object) any {
+	if i, _ := o.(Object); i != nil {
+		return i.ID
+	}
+	return nil
+}
+
+func (c Class) Preview(o korrel8r.Object) string {
+	return impl.Preview(o, func(i Object) string { return i.Title })
+}
+
+// Timestamp is the creation time of the incident, the interval has no end.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	if i, _ := o.(Object); i != nil {
+		return i.CreatedAt, time.Time{}
+	}
+	return time.Time{}, time.Time{}
+}
+
+// Object is an incident.
+type Object = *Incident
+
+// Incident in an external incident management system.
+type Incident struct {
+	// ID is the unique identifier of the incident in the incident system.
+	ID string `json:"id"`
+	// Title is a short description of the incident.
+	Title string `json:"title,omitempty"`
+	// Status of the incident, depends on the incident system. For example: open, acknowledged, resolved.
+	Status string `json:"status,omitempty"`
+	// Severity of the incident, depends on the incident system.
+	Severity string `json:"severity,omitempty"`
+	// URL of the incident in the incident system.
+	URL string `json:"url,omitempty"`
+	// Receiver is the name of the Alertmanager receiver that created the incident.
+	Receiver string `json:"receiver,omitempty"`
+	// Fingerprints of the alerts that belong to the incident.
+	Fingerprints []string `json:"fingerprints,omitempty"`
+	// Labels common to all alerts in the incident.
+	Labels map[string]string `json:"labels,omitempty"`
+	// CreatedAt is the time the incident was created.
+	CreatedAt time.Time `json:"createdAt"`
+	// UpdatedAt is the time the incident was last updated.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Query for incidents. Empty fields match any incident.
+type Query struct {
+	// ID matches the incident ID.
+	ID string `json:"id,omitempty"`
+	// Fingerprint matches incidents containing an alert with this fingerprint.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Receivers matches incidents created by any of these receivers.
+	Receivers []string `json:"receivers,omitempty"`
+	// Labels matches incidents that have all of these labels.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func (q Query) Class() korrel8r.Class { return Class{} }
+func (q Query) Data() string          { b, _ := json.Marshal(q); return string(b) }
+func (q Query) String() string        { return impl.QueryString(q) }
+
+// Matches returns true if the incident matches all non-empty fields of the query.
+func (q Query) Matches(i *Incident) bool {
+	if q.ID != "" && q.ID != i.ID {
+		return false
+	}
+	if q.Fingerprint != "" && !slices.Contains(i.Fingerprints, q.Fingerprint) {
+		return false
+	}
+	if len(q.Receivers) > 0 && !slices.Contains(q.Receivers, i.Receiver) {
+		return false
+	}
+	for k, v := range q.Labels {
+		if i.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// appendMatches appends incidents matching the query and constraint to result.
+func appendMatches(incidents []*Incident, q Query, constraint *korrel8r.Constraint, result korrel8r.Appender) {
+	n := 0
+	for _, i := range incidents {
+		if limit := constraint.GetLimit(); limit > 0 && n >= limit {
+			return
+		}
+		// Skip incidents created after the end of the interval.
+		// Incidents without a namespace label are excluded by a namespace constraint.
+		if q.Matches(i) && constraint.Overlaps(Class{}.Timestamp(i)) && constraint.AllowsNamespace(i.Labels["namespace"]) {
+			result.Append(i)
+			n++
+		}
+	}
+}
+
+// NewStore returns a store for a REST incident search service.
+func NewStore(base *url.URL, h *http.Client) (korrel8r.Store, error) {
+	return &restStore{base: base, hc: h}, nil
+}
+
+type restStore struct {
+	base *url.URL
+	hc   *http.Client
+}
+
+func (restStore) Domain() korrel8r.Domain { return Domain }
+
+func (s *restStore) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) error {
+	q, err := impl.TypeAssert[Query](query)
+	if err != nil {
+		return err
+	}
+	var incidents []*Incident
+	if err := impl.Get(ctx, s.searchURL(q, constraint), s.hc, &incidents); err != nil {
+		return err
+	}
+	// The service may not implement all query parameters, check results.
+	appendMatches(incidents, q, constraint, result)
+	return nil
+}
+
+func (s *restStore) searchURL(q Query, constraint *korrel8r.Constraint) *url.URL {
+	v := s.base.Query()
+	add := func(k, v2 string) {
+		if v2 != "" {
+			v.Add(k, v2)
+		}
+	}
+	add("id", q.ID)
+	add("fingerprint", q.Fingerprint)
+	for _, r := range q.Receivers {
+		add("receiver", r)
+	}
+	for k, l := range q.Labels {
+		add("label", k+"="+l)
+	}
+	if t := constraint.GetStart(); !t.IsZero() {
+		add("start", t.UTC().Format(time.RFC3339))
+	}
+	if t := constraint.GetEnd(); !t.IsZero() {
+		add("end", t.UTC().Format(time.RFC3339))
+	}
+	if n := constraint.GetLimit(); n > 0 {
+		add("limit", fmt.Sprintf("%v", n))
+	}
+	u := *s.base
+	u.RawQuery = v.Encode()
+	return &u
+}
+
+// NewFileStore returns a store that reads incidents from a JSON or YAML file.
+// The file is read on each request, so it can be updated while korrel8r is running.
+func NewFileStore(path string) (korrel8r.Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &fileStore{path: path}, nil
+}
+
+type fileStore struct{ path string }
+
+func (fileStore) Domain() korrel8r.Domain { return Domain }
+
+func (s *fileStore) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) error {
+	q, err := impl.TypeAssert[Query](query)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var incidents []*Incident
+	if err := yaml.Unmarshal(b, &incidents); err != nil {
+		return fmt.Errorf("%v: %w", s.path, err)
+	}
+	appendMatches(incidents, q, constraint, result)
+	return nil
+}
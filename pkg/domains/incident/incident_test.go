@@ -0,0 +1,92 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package incident_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/domain"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/domains/incident"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+var fixture = domain.Fixture{Query: incident.Query{Receivers: []string{"pagerduty"}}, SkipCluster: true}
+
+func TestIncidentDomain(t *testing.T)      { fixture.Test(t) }
+func BenchmarkIncidentDomain(b *testing.B) { fixture.Benchmark(b) }
+
+func ids(result graph.ListResult) (ids []string) {
+	for _, o := range result {
+		ids = append(ids, o.(incident.Object).ID)
+	}
+	return ids
+}
+
+func TestFileStore_Get(t *testing.T) {
+	s, err := incident.Domain.Store(config.Store{incident.StoreKeyFile: "testdata/incidents.yaml"})
+	require.NoError(t, err)
+	end := time.Date(2024, 8, 7, 1, 30, 0, 0, time.UTC)
+	for _, x := range []struct {
+		query      incident.Query
+		constraint *korrel8r.Constraint
+		want       []string
+	}{
+		{incident.Query{Fingerprint: "b8f2b3e4c5d6e7f8"}, nil, []string{"INC-1"}},
+		{incident.Query{Receivers: []string{"pagerduty", "email"}}, nil, []string{"INC-1", "INC-2", "INC-3"}},
+		{incident.Query{Labels: map[string]string{"alertname": "KubePodCrashLooping"}}, nil, []string{"INC-1", "INC-3"}},
+		{incident.Query{Labels: map[string]string{"alertname": "KubePodCrashLooping", "namespace": "cart"}}, nil, []string{"INC-3"}},
+		{incident.Query{ID: "INC-2"}, nil, []string{"INC-2"}},
+		{incident.Query{}, &korrel8r.Constraint{End: &end}, []string{"INC-1", "INC-2"}},
+		{incident.Query{}, &korrel8r.Constraint{Limit: ptr.To(1)}, []string{"INC-1"}},
+	} {
+		t.Run(x.query.String(), func(t *testing.T) {
+			var result graph.ListResult
+			require.NoError(t, s.Get(context.Background(), x.query, x.constraint, &result))
+			assert.Equal(t, x.want, ids(result))
+		})
+	}
+}
+
+func TestStore_Get(t *testing.T) {
+	b, err := os.ReadFile("testdata/incidents.yaml")
+	require.NoError(t, err)
+	recorded, err := yaml.YAMLToJSON(b)
+	require.NoError(t, err)
+	var params url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/search", r.URL.Path)
+		params = r.URL.Query()
+		_, _ = w.Write(recorded) // Stand-in ignores parameters, the store must filter.
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL + "/api/search?token=x")
+	s, err := incident.NewStore(u, server.Client())
+	require.NoError(t, err)
+
+	start := time.Date(2024, 8, 7, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	var result graph.ListResult
+	q := incident.Query{Receivers: []string{"pagerduty"}, Labels: map[string]string{"alertname": "KubePodCrashLooping"}}
+	require.NoError(t, s.Get(context.Background(), q, &korrel8r.Constraint{Start: &start, End: &end, Limit: ptr.To(10)}, &result))
+	assert.Equal(t, []string{"INC-1"}, ids(result))
+	assert.Equal(t, url.Values{
+		"token":    {"x"},
+		"receiver": {"pagerduty"},
+		"label":    {"alertname=KubePodCrashLooping"},
+		"start":    {"2024-08-07T00:00:00Z"},
+		"end":      {"2024-08-07T01:00:00Z"},
+		"limit":    {"10"},
+	}, params)
+}
@@ -46,12 +46,12 @@ func NewHTTPClient(s kconfig.Store) (*http.Client, error) {
 }
 
 // GetConfig returns a rest.Config with settings for use by korrel8r.
+// The default client rate limit can be changed by the [kconfig.StoreKeyQPS] and [kconfig.StoreKeyBurst] store keys.
 func GetConfig() (*rest.Config, error) {
 	cfg, err := config.GetConfig()
 	if err != nil {
 		return nil, err
 	}
-	// TODO make these configurable.
 	cfg.QPS = float32(korrel8r.DefaultLimit)
 	cfg.Burst = korrel8r.DefaultLimit
 	cfg.Wrap(auth.Wrap)
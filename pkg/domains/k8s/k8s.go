@@ -19,9 +19,12 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/must"
+	kconfig "github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
 	corev1 "k8s.io/api/core/v1"
@@ -83,10 +86,11 @@ type Store struct {
 
 // Validate interfaces
 var (
-	_ korrel8r.Domain = Domain
-	_ korrel8r.Class  = Class{}
-	_ korrel8r.Object = Object(nil)
-	_ korrel8r.Query  = &Query{}
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.Object      = Object(nil)
+	_ korrel8r.Query       = &Query{}
 )
 
 // domain implementation
@@ -95,11 +99,20 @@ type domain struct{}
 func (d domain) Name() string        { return "k8s" }
 func (d domain) String() string      { return d.Name() }
 func (d domain) Description() string { return "Resource objects in a Kubernetes API server" }
-func (d domain) Store(_ any) (s korrel8r.Store, err error) {
+func (d domain) Store(sc any) (s korrel8r.Store, err error) {
 	cfg, err := GetConfig()
 	if err != nil {
 		return nil, err
 	}
+	if cs, ok := sc.(kconfig.Store); ok { // Store configuration can override the client rate limit.
+		r, err := cs.Rate()
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			cfg.QPS, cfg.Burst = float32(r.QPS), r.GetBurst()
+		}
+	}
 	c, err := NewClient(cfg)
 	if err != nil {
 		return nil, err
@@ -185,6 +198,61 @@ func (c Class) Preview(o korrel8r.Object) string {
 	}
 }
 
+// Severity reports failed, crash-looping or pending pods, and warning events.
+func (c Class) Severity(o korrel8r.Object) korrel8r.Severity {
+	switch o := o.(type) {
+	case *corev1.Pod:
+		if o.Status.Phase == corev1.PodFailed {
+			return korrel8r.SeverityError
+		}
+		for _, cs := range o.Status.ContainerStatuses {
+			if cs.State.Waiting != nil && cs.RestartCount > 0 { // Crash looping
+				return korrel8r.SeverityError
+			}
+		}
+		if o.Status.Phase == corev1.PodPending {
+			return korrel8r.SeverityWarning
+		}
+	case *corev1.Event:
+		if o.Type == corev1.EventTypeWarning {
+			return korrel8r.SeverityWarning
+		}
+	}
+	return korrel8r.SeverityNone
+}
+
+// Timestamp is the interval from creation to deletion of a resource, or the occurrences of an Event.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	switch o := o.(type) {
+	case *corev1.Event:
+		return EventTimestamp(o)
+	case Object:
+		if d := o.GetDeletionTimestamp(); d != nil {
+			end = d.Time
+		}
+		return o.GetCreationTimestamp().Time, end
+	}
+	return time.Time{}, time.Time{}
+}
+
+// EventTimestamp returns the interval from the first to the last occurrence of an event.
+func EventTimestamp(e *corev1.Event) (start, end time.Time) {
+	start, end = e.FirstTimestamp.Time, e.LastTimestamp.Time
+	if start.IsZero() {
+		start = e.EventTime.Time
+	}
+	if start.IsZero() {
+		start = e.CreationTimestamp.Time
+	}
+	if end.IsZero() && e.Series != nil {
+		end = e.Series.LastObservedTime.Time
+	}
+	if end.Before(start) {
+		end = start
+	}
+	return start, end
+}
+
 func (c Class) Domain() korrel8r.Domain { return Domain }
 func (c Class) Unmarshal(b []byte) (korrel8r.Object, error) {
 	if o, err := Scheme.New(schema.GroupVersionKind(c)); err == nil {
@@ -240,8 +308,11 @@ func (s Store) Client() client.Client   { return s.c }
 
 func (s *Store) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.Constraint, result korrel8r.Appender) (err error) {
 	defer func() {
-		if errors.IsNotFound(err) {
+		switch {
+		case errors.IsNotFound(err):
 			err = nil // Finding nothing is not an error.
+		case errors.IsForbidden(err):
+			err = korrel8r.DeniedError{Reason: err.Error()}
 		}
 	}()
 
@@ -249,9 +320,13 @@ func (s *Store) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.Const
 	if err != nil {
 		return err
 	}
+	if q.Namespace != "" && !c.AllowsNamespace(q.Namespace) {
+		return nil // Namespace excluded by constraint.
+	}
 	appender := korrel8r.AppenderFunc(func(o korrel8r.Object) {
-		// Include only objects created before or during the constraint interval.
-		if c.CompareTime(o.(Object).GetCreationTimestamp().Time) <= 0 {
+		// Include only objects that exist or occur during the constraint interval,
+		// in namespaces allowed by the constraint.
+		if c.Overlaps(Class{}.Timestamp(o)) && allowsObject(c, o) {
 			result.Append(o)
 		}
 	})
@@ -262,6 +337,13 @@ func (s *Store) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.Const
 	}
 }
 
+// allowsObject returns true if the namespace of o is allowed by c.
+// Cluster-scoped objects have no namespace and are excluded by a namespace constraint.
+func allowsObject(c *korrel8r.Constraint, o korrel8r.Object) bool {
+	co, _ := o.(client.Object)
+	return co == nil || c.AllowsNamespace(co.GetNamespace())
+}
+
 func setMeta(o Object) Object {
 	gvk := must.Must1(apiutil.GVKForObject(o, Scheme))
 	o.GetObjectKind().SetGroupVersionKind(gvk)
@@ -297,20 +379,38 @@ func (s *Store) getList(ctx context.Context, q *Query, result korrel8r.Appender,
 		return fmt.Errorf("invalid list object %T", o)
 	}
 	var opts []client.ListOption
-	if q.Namespace != "" {
-		opts = append(opts, client.InNamespace(q.Namespace))
-	}
 	if len(q.Labels) > 0 {
 		opts = append(opts, q.Labels)
 	}
 	if len(q.Fields) > 0 {
 		opts = append(opts, q.Fields)
 	}
-	if limit := c.GetLimit(); limit > 0 {
-		opts = append(opts, client.Limit(int64(limit)))
+	namespaces := []string{q.Namespace}
+	if q.Namespace == "" && len(c.GetNamespaces()) > 0 {
+		namespaces = c.GetNamespaces() // List only namespaces allowed by the constraint.
 	}
+	limit, count := c.GetLimit(), 0 // The limit applies to the total over all namespaces.
+	for _, ns := range namespaces {
+		nsOpts := append(slices.Clone(opts), client.InNamespace(ns))
+		if limit > 0 {
+			if count >= limit {
+				break
+			}
+			nsOpts = append(nsOpts, client.Limit(int64(limit-count)))
+		}
+		n, err := s.list(ctx, list, nsOpts, result)
+		if err != nil {
+			return err
+		}
+		count += n
+	}
+	return nil
+}
+
+// list appends the listed objects to result, returns the number of objects listed.
+func (s *Store) list(ctx context.Context, list client.ObjectList, opts []client.ListOption, result korrel8r.Appender) (n int, err error) {
 	if err := s.c.List(ctx, list, opts...); err != nil {
-		return err
+		return 0, err
 	}
 	defer func() { // Handle reflect panics.
 		if r := recover(); r != nil && err == nil {
@@ -321,7 +421,7 @@ func (s *Store) getList(ctx context.Context, q *Query, result korrel8r.Appender,
 	for i := 0; i < items.Len(); i++ {
 		result.Append(setMeta(items.Index(i).Addr().Interface().(client.Object)))
 	}
-	return nil
+	return items.Len(), nil
 }
 
 func NamespacedName(namespace, name string) types.NamespacedName {
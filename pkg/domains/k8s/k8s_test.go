@@ -10,6 +10,7 @@ import (
 
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
@@ -138,6 +139,45 @@ func TestStore_Get(t *testing.T) {
 	// Need to validate labels and all get variations on fake client or env test...
 }
 
+func TestStore_Get_Namespaces(t *testing.T) {
+	pod := func(ns, name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+	}
+	c := fake.NewClientBuilder().
+		WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).
+		WithObjects(pod("x", "fred"), pod("y", "wilma"), pod("z", "barney")).Build()
+	store, err := NewStore(c, &rest.Config{})
+	require.NoError(t, err)
+	constraint := &korrel8r.Constraint{Namespaces: []string{"x", "y"}}
+	for _, x := range []struct {
+		namespace, name string
+		want            []string
+	}{
+		{"", "", []string{"fred", "wilma"}},
+		{"x", "", []string{"fred"}},
+		{"z", "", nil},
+		{"z", "barney", nil},
+		{"y", "wilma", []string{"wilma"}},
+	} {
+		t.Run(x.namespace+"/"+x.name, func(t *testing.T) {
+			var result graph.ListResult
+			require.NoError(t, store.Get(context.Background(), NewQuery(ClassOf(&corev1.Pod{}), x.namespace, x.name, nil, nil), constraint, &result))
+			var got []string
+			for _, v := range result {
+				got = append(got, v.(Object).(*corev1.Pod).GetName())
+			}
+			assert.ElementsMatch(t, x.want, got)
+		})
+	}
+	t.Run("limit", func(t *testing.T) {
+		// The limit applies to the total over all namespaces.
+		var result graph.ListResult
+		c := &korrel8r.Constraint{Namespaces: []string{"x", "y", "z"}, Limit: ptr.To(2)}
+		require.NoError(t, store.Get(context.Background(), NewQuery(ClassOf(&corev1.Pod{}), "", "", nil, nil), c, &result))
+		assert.Len(t, result, 2)
+	})
+}
+
 func TestStore_Get_Constraint(t *testing.T) {
 	// Time range [start,end] and some time points.
 	start := time.Now()
@@ -192,3 +232,28 @@ func TestDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestClass_Timestamp(t *testing.T) {
+	created, deleted := time.Now().Add(-time.Hour), time.Now()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		CreationTimestamp: metav1.NewTime(created),
+		DeletionTimestamp: ptr.To(metav1.NewTime(deleted)),
+	}}
+	start, end := Class{}.Timestamp(pod)
+	assert.True(t, created.Equal(start))
+	assert.True(t, deleted.Equal(end))
+
+	first, last := created.Add(time.Minute), created.Add(2*time.Minute)
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)},
+		FirstTimestamp: metav1.NewTime(first),
+		LastTimestamp:  metav1.NewTime(last),
+	}
+	start, end = Class{}.Timestamp(event)
+	assert.True(t, first.Equal(start))
+	assert.True(t, last.Equal(end))
+	event.FirstTimestamp, event.LastTimestamp = metav1.Time{}, metav1.Time{}
+	start, end = Class{}.Timestamp(event)
+	assert.True(t, created.Equal(start))
+	assert.True(t, created.Equal(end))
+}
@@ -1,23 +1,22 @@
 // Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
 
-// # Template Functions
-//
-// The following template functions are available to rules.
-//
-//	k8sClass
-//	    Takes string arguments (apiVersion, kind).
-//	    Returns the korrel8r.Class implied by the arguments, or an error.
 package k8s
 
 import (
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// TemplateFuncs for this domain. See package description.
-func (domain) TemplateFuncs() map[string]any {
-	return map[string]any{
-		"k8sClass": k8sClass,
+// TemplateFuncs for this domain.
+func (domain) TemplateFuncs() []korrel8r.TemplateFunc {
+	return []korrel8r.TemplateFunc{
+		{
+			Name:        "k8sClass",
+			Func:        k8sClass,
+			Description: "Takes string arguments (apiVersion, kind). Returns the korrel8r.Class implied by the arguments.",
+			Example:     `{{ k8sClass "apps/v1" "Deployment" }} => k8s:Deployment.v1.apps`,
+		},
 	}
 }
 
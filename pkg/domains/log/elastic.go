@@ -0,0 +1,155 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/elastic"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
+)
+
+// NewElasticStore returns a store that uses the Elasticsearch or OpenSearch search API.
+//
+// Logs are expected in the ViaQ indices created by openshift-logging: app-*, infra-* and audit-*.
+func NewElasticStore(base *url.URL, h *http.Client) (korrel8r.Store, error) {
+	return &elasticStore{elastic.New(h, base)}, nil
+}
+
+type elasticStore struct{ *elastic.Client }
+
+func (elasticStore) Domain() korrel8r.Domain { return Domain }
+
+func (s *elasticStore) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) error {
+	q, err := impl.TypeAssert[Query](query)
+	if err != nil {
+		return err
+	}
+	eq, err := ElasticQuery(scopeLogQL(q.Data(), constraint))
+	if err != nil {
+		return err
+	}
+	return s.Client.Search(ctx, elasticIndex(q.class), eq, constraint, func(h *elastic.Hit) {
+		appendObject(constraint, result, NewObject(string(h.Source)))
+	})
+}
+
+// elasticIndex returns the ViaQ index pattern for a log class.
+func elasticIndex(c Class) string {
+	switch c {
+	case Infrastructure:
+		return "infra-*"
+	case Audit:
+		return "audit-*"
+	default:
+		return "app-*"
+	}
+}
+
+var (
+	quoted     = `("(?:[^"\\]|\\.)*"|` + "`[^`]*`)"
+	selectorRe = regexp.MustCompile(`^\s*{([^}]*)}(.*)$`)
+	matcherRe  = regexp.MustCompile(`^\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*` + quoted + `\s*(?:,|$)`)
+	filterRe   = regexp.MustCompile(`^\s*(\|=|!=|\|~|!~)\s*` + quoted)
+)
+
+// ElasticQuery translates a LogQL log query to an Elasticsearch query.
+//
+// Only a subset of LogQL can be translated:
+//   - The stream selector, with label names mapped to ViaQ field names.
+//   - Line filter expressions, applied to the message field.
+//
+// Regular expression line filters (|~ and !~) are not exact: the message field is analysed text,
+// so the expression is matched against each word of the message (lower-cased), not against the whole line.
+// An expression that spans more than one word does not match.
+//
+// Other pipeline stages are not supported and return an error.
+func ElasticQuery(logQL string) (elastic.Query, error) {
+	m := selectorRe.FindStringSubmatch(logQL)
+	if m == nil {
+		return nil, fmt.Errorf("invalid LogQL stream selector: %v", logQL)
+	}
+	var must, mustNot []any
+	add := func(op, field, value string, line bool) {
+		var q elastic.Query
+		switch {
+		case line && (op == "|=" || op == "!="):
+			q = elastic.Query{"match_phrase": map[string]any{field: value}}
+		case op == "=" || op == "!=":
+			q = elastic.Query{"term": map[string]any{field: value}}
+		default: // Regular expression match
+			q = elastic.Query{"regexp": map[string]any{field: value}}
+		}
+		if strings.HasPrefix(op, "!") {
+			mustNot = append(mustNot, q)
+		} else {
+			must = append(must, q)
+		}
+	}
+	for s := m[1]; strings.TrimSpace(s) != ""; {
+		mm := matcherRe.FindStringSubmatch(s)
+		if mm == nil {
+			return nil, fmt.Errorf("invalid LogQL stream selector: %v", logQL)
+		}
+		s = s[len(mm[0]):]
+		value, err := unquote(mm[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid LogQL stream selector: %v: %w", logQL, err)
+		}
+		if field := elasticField(mm[1]); field != "" {
+			add(mm[2], field, value, false)
+		}
+	}
+	for s := m[2]; strings.TrimSpace(s) != ""; {
+		mm := filterRe.FindStringSubmatch(s)
+		if mm == nil {
+			return nil, fmt.Errorf("LogQL pipeline not supported by elasticsearch store: %v", strings.TrimSpace(s))
+		}
+		s = s[len(mm[0]):]
+		value, err := unquote(mm[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid LogQL line filter: %v: %w", logQL, err)
+		}
+		add(mm[1], "message", value, true)
+	}
+	q := map[string]any{}
+	if len(must) > 0 {
+		q["must"] = must
+	}
+	if len(mustNot) > 0 {
+		q["must_not"] = mustNot
+	}
+	if len(q) == 0 {
+		return elastic.Query{"match_all": map[string]any{}}, nil
+	}
+	return elastic.Query{"bool": q}, nil
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "`") {
+		return strings.Trim(s, "`"), nil
+	}
+	return strconv.Unquote(s)
+}
+
+// elasticField returns the ViaQ document field for a Loki stream label,
+// or "" if the label has no equivalent field.
+func elasticField(label string) string {
+	switch {
+	case label == "log_type" || label == "openshift_log_type":
+		return "" // Log type is selected by the index.
+	case label == "kubernetes_host":
+		return "hostname"
+	case strings.HasPrefix(label, "kubernetes_"):
+		return "kubernetes." + strings.TrimPrefix(label, "kubernetes_")
+	default:
+		return label
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/elastic"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticStore(t *testing.T) {
+	recorded, err := os.ReadFile("testdata/elastic_search.json")
+	require.NoError(t, err)
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/es/app-*/_search", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write(recorded)
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/es")
+	s, err := NewElasticStore(u, server.Client())
+	require.NoError(t, err)
+	start := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	constraint := &korrel8r.Constraint{Limit: ptr.To(2), Start: &start, End: &end}
+	var result graph.ListResult
+	require.NoError(t, s.Get(context.Background(), NewQuery(Application, `{kubernetes_namespace_name="foo"} |= "err"`), constraint, &result))
+
+	// Hits are returned in order of increasing time.
+	var messages []string
+	for _, o := range result {
+		messages = append(messages, Preview(o))
+	}
+	assert.Equal(t, []string{"first", "second"}, messages)
+	assert.Equal(t, map[string]any{"namespace_name": "foo", "pod_name": "bar", "container_name": "baz"}, result[0].(Object)["kubernetes"])
+
+	want := `{
+  "query": {"bool": {"filter": [
+    {"bool": {"must": [
+      {"term": {"kubernetes.namespace_name": "foo"}},
+      {"match_phrase": {"message": "err"}}
+    ]}},
+    {"range": {"@timestamp": {"gte": "2024-01-02T09:00:00Z", "lte": "2024-01-02T10:00:00Z"}}}
+  ]}},
+  "size": 2,
+  "sort": [{"@timestamp": {"order": "desc"}}]
+}`
+	got, _ := json.Marshal(body)
+	assert.JSONEq(t, want, string(got))
+}
+
+func TestElasticRequest_size(t *testing.T) {
+	for _, x := range []struct {
+		limit *int
+		want  int
+	}{
+		{ptr.To(5), 5},
+		{nil, elastic.MaxSize},
+		{ptr.To(0), elastic.MaxSize},
+		{ptr.To(elastic.MaxSize + 1), elastic.MaxSize},
+	} {
+		got := elastic.NewRequest(elastic.Query{}, &korrel8r.Constraint{Limit: x.limit})
+		assert.Equal(t, x.want, got.Size)
+	}
+	// No limit must send an explicit size, the server default is 10.
+	b, err := json.Marshal(elastic.NewRequest(elastic.Query{}, nil))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"size":10000`)
+}
+
+func TestElasticStore_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"no such index"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := NewElasticStore(u, server.Client())
+	require.NoError(t, err)
+	var result graph.ListResult
+	err = s.Get(context.Background(), NewQuery(Audit, `{}`), nil, &result)
+	assert.ErrorContains(t, err, "no such index")
+}
+
+func TestElasticQuery(t *testing.T) {
+	for _, x := range []struct {
+		logQL string
+		want  elastic.Query
+	}{
+		{`{}`, elastic.Query{"match_all": map[string]any{}}},
+		{`{log_type="application"}`, elastic.Query{"match_all": map[string]any{}}},
+		{
+			`{ kubernetes_namespace_name="openshift-cluster-version", kubernetes_pod_name=~".*-operator-.*" }`,
+			elastic.Query{"bool": map[string]any{"must": []any{
+				elastic.Query{"term": map[string]any{"kubernetes.namespace_name": "openshift-cluster-version"}},
+				elastic.Query{"regexp": map[string]any{"kubernetes.pod_name": ".*-operator-.*"}},
+			}}},
+		},
+		{
+			`{kubernetes_host!="node-1",level!~"debug|info"} != "health" |~ ` + "`fail(ed)?`",
+			elastic.Query{"bool": map[string]any{
+				"must": []any{
+					elastic.Query{"regexp": map[string]any{"message": "fail(ed)?"}},
+				},
+				"must_not": []any{
+					elastic.Query{"term": map[string]any{"hostname": "node-1"}},
+					elastic.Query{"regexp": map[string]any{"level": "debug|info"}},
+					elastic.Query{"match_phrase": map[string]any{"message": "health"}},
+				},
+			}},
+		},
+	} {
+		t.Run(x.logQL, func(t *testing.T) {
+			got, err := ElasticQuery(x.logQL)
+			require.NoError(t, err)
+			assert.Equal(t, x.want, got)
+		})
+	}
+}
+
+func TestElasticQuery_unsupported(t *testing.T) {
+	for _, logQL := range []string{
+		`kubernetes_namespace_name="x"`,
+		`{kubernetes_namespace_name="x"} | json`,
+		`{kubernetes_namespace_name=x}`,
+	} {
+		t.Run(logQL, func(t *testing.T) {
+			_, err := ElasticQuery(logQL)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestScopeLogQL(t *testing.T) {
+	c := &korrel8r.Constraint{Namespaces: []string{"a", "b.c"}}
+	for _, x := range []struct{ logQL, want string }{
+		{`{}`, `{kubernetes_namespace_name=~"a|b\\.c"}`},
+		{`{ kubernetes_pod_name="x" } |= "y"`, `{kubernetes_namespace_name=~"a|b\\.c", kubernetes_pod_name="x" } |= "y"`},
+		{`no selector`, `no selector`},
+	} {
+		assert.Equal(t, x.want, scopeLogQL(x.logQL, c))
+		assert.Equal(t, x.logQL, scopeLogQL(x.logQL, nil))
+	}
+	q, err := ElasticQuery(scopeLogQL(`{kubernetes_pod_name="x"}`, c))
+	require.NoError(t, err)
+	assert.Equal(t, elastic.Query{"bool": map[string]any{"must": []any{
+		elastic.Query{"regexp": map[string]any{"kubernetes.namespace_name": `a|b\.c`}},
+		elastic.Query{"term": map[string]any{"kubernetes.pod_name": "x"}},
+	}}}, q)
+}
@@ -1,6 +1,6 @@
 // Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
 
-// Package log is a domain for openshift-logging ViaQ logs stored in Loki or LokiStack.
+// Package log is a domain for openshift-logging ViaQ logs stored in Loki, LokiStack, Elasticsearch or OpenSearch.
 //
 // # Class
 //
@@ -32,6 +32,22 @@
 //	domain: log
 //	loki: URL_OF_LOKI
 //
+// To connect to an Elasticsearch or OpenSearch store with ViaQ indices use one of:
+//
+//	domain: log
+//	elasticsearch: URL_OF_ELASTICSEARCH
+//
+//	domain: log
+//	opensearch: URL_OF_OPENSEARCH
+//
+// Elasticsearch and OpenSearch stores only support LogQL queries consisting of
+// a stream selector and optional line filters, for example:
+//
+//	log:application:{kubernetes_namespace_name="foo"} |= "error"
+//
+// Regular expression line filters (|~ and !~) match single words of the message, see [ElasticQuery].
+// Searches return at most 10000 logs, the default Elasticsearch result window.
+//
 // [LogQL]: https://grafana.com/docs/loki/latest/query/
 package log
 
@@ -42,7 +58,9 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/loki"
 	"github.com/korrel8r/korrel8r/pkg/config"
@@ -53,12 +71,14 @@ import (
 
 var (
 	// Verify implementing interfaces.
-	_ korrel8r.Domain    = Domain
-	_ korrel8r.Store     = &store{}
-	_ korrel8r.Store     = &stackStore{}
-	_ korrel8r.Query     = Query{}
-	_ korrel8r.Class     = Class("")
-	_ korrel8r.Previewer = Class("")
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Store       = &store{}
+	_ korrel8r.Store       = &stackStore{}
+	_ korrel8r.Store       = &elasticStore{}
+	_ korrel8r.Query       = Query{}
+	_ korrel8r.Class       = Class("")
+	_ korrel8r.Timestamper = Class("")
+	_ korrel8r.Previewer   = Class("")
 )
 
 // Domain for log records produced by openshift-logging.
@@ -67,6 +87,8 @@ var (
 // - Default LokiStack store on current Openshift cluster: `{}`
 // - Remote LokiStack: `{ "lokiStack": "https://url-of-lokistack"}`
 // - Plain Loki store: `{ "loki": "https://url-of-loki"}`
+// - Elasticsearch store: `{ "elasticsearch": "https://url-of-elasticsearch"}`
+// - OpenSearch store: `{ "opensearch": "https://url-of-opensearch"}`
 var Domain = domain{}
 
 type domain struct{}
@@ -85,8 +107,10 @@ func (d domain) Query(s string) (korrel8r.Query, error) {
 }
 
 const (
-	StoreKeyLoki      = "loki"
-	StoreKeyLokiStack = "lokiStack"
+	StoreKeyLoki          = "loki"
+	StoreKeyLokiStack     = "lokiStack"
+	StoreKeyElasticsearch = "elasticsearch"
+	StoreKeyOpenSearch    = "opensearch"
 )
 
 func (domain) Store(s any) (korrel8r.Store, error) {
@@ -99,28 +123,29 @@ func (domain) Store(s any) (korrel8r.Store, error) {
 		return nil, err
 	}
 
-	loki, lokiStack := cs[StoreKeyLoki], cs[StoreKeyLokiStack]
-	switch {
-
-	case loki != "" && lokiStack != "":
-		return nil, fmt.Errorf("can't set both loki and lokiStack URLs")
-
-	case loki != "":
-		u, err := url.Parse(loki)
-		if err != nil {
-			return nil, err
+	var key, value string
+	for _, k := range []string{StoreKeyLoki, StoreKeyLokiStack, StoreKeyElasticsearch, StoreKeyOpenSearch} {
+		if v := cs[k]; v != "" {
+			if key != "" {
+				return nil, fmt.Errorf("can't set both %v and %v URLs", key, k)
+			}
+			key, value = k, v
 		}
+	}
+	if key == "" {
+		return nil, fmt.Errorf("must set one of loki, lokiStack, elasticsearch or opensearch URLs")
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	switch key {
+	case StoreKeyLoki:
 		return NewPlainLokiStore(u, hc)
-
-	case lokiStack != "":
-		u, err := url.Parse(lokiStack)
-		if err != nil {
-			return nil, err
-		}
+	case StoreKeyLokiStack:
 		return NewLokiStackStore(u, hc)
-
 	default:
-		return nil, fmt.Errorf("must set one of loki or lokiStack URLs")
+		return NewElasticStore(u, hc)
 	}
 }
 
@@ -142,6 +167,30 @@ func Preview(x korrel8r.Object) (line string) {
 	return ""
 }
 
+// Severity uses the Viaq "level" field.
+func (c Class) Severity(o korrel8r.Object) korrel8r.Severity {
+	level, _ := o.(Object)["level"].(string)
+	switch strings.ToLower(level) {
+	case "emerg", "emergency", "alert", "crit", "critical", "err", "error", "fatal", "panic":
+		return korrel8r.SeverityError
+	case "warn", "warning":
+		return korrel8r.SeverityWarning
+	}
+	return korrel8r.SeverityNone
+}
+
+// Timestamp uses the Viaq "@timestamp" field, or "timestamp" if it is missing.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	for _, k := range []string{"@timestamp", "timestamp"} {
+		if s, _ := o.(Object)[k].(string); s != "" {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				return t, t
+			}
+		}
+	}
+	return time.Time{}, time.Time{}
+}
+
 func (c Class) Description() string {
 	switch c {
 	case Application:
@@ -221,7 +270,7 @@ func (s *store) Get(ctx context.Context, query korrel8r.Query, constraint *korre
 	if err != nil {
 		return err
 	}
-	return s.Client.Get(ctx, q.Data(), constraint, func(e *loki.Entry) { result.Append(NewObject(e.Line)) })
+	return s.Client.Get(ctx, scopeLogQL(q.Data(), constraint), constraint, entryAppender(constraint, result))
 }
 
 type stackStore struct{ store }
@@ -231,7 +280,43 @@ func (s *stackStore) Get(ctx context.Context, query korrel8r.Query, constraint *
 	if err != nil {
 		return err
 	}
-	return s.Client.GetStack(ctx, q.Data(), q.Class().Name(), constraint, func(e *loki.Entry) { result.Append(NewObject(e.Line)) })
+	return s.Client.GetStack(ctx, scopeLogQL(q.Data(), constraint), q.Class().Name(), constraint, entryAppender(constraint, result))
+}
+
+// entryAppender appends log entries to result, if their namespace is allowed by the constraint.
+func entryAppender(constraint *korrel8r.Constraint, result korrel8r.Appender) func(e *loki.Entry) {
+	return func(e *loki.Entry) { appendObject(constraint, result, NewObject(e.Line)) }
+}
+
+func appendObject(constraint *korrel8r.Constraint, result korrel8r.Appender, o Object) {
+	if len(constraint.GetNamespaces()) > 0 {
+		k8s, _ := o["kubernetes"].(map[string]any)
+		ns, _ := k8s["namespace_name"].(string)
+		if !constraint.AllowsNamespace(ns) {
+			return
+		}
+	}
+	result.Append(o)
+}
+
+// scopeLogQL adds a namespace matcher to the stream selector of logQL,
+// if the constraint restricts namespaces.
+func scopeLogQL(logQL string, constraint *korrel8r.Constraint) string {
+	namespaces := constraint.GetNamespaces()
+	i := strings.Index(logQL, "{")
+	j := strings.Index(logQL, "}")
+	if len(namespaces) == 0 || i < 0 || j < i {
+		return logQL
+	}
+	patterns := make([]string, len(namespaces))
+	for k, ns := range namespaces {
+		patterns[k] = regexp.QuoteMeta(ns)
+	}
+	matcher := "kubernetes_namespace_name=~" + strconv.Quote(strings.Join(patterns, "|"))
+	if strings.TrimSpace(logQL[i+1:j]) != "" {
+		matcher += ","
+	}
+	return logQL[:i+1] + matcher + logQL[i+1:]
 }
 
 var logTypeRe = regexp.MustCompile(`{[^}]*log_type(=~*)"([^"]+)"}`)
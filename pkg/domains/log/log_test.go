@@ -4,12 +4,28 @@ package log_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/test/domain"
 	"github.com/korrel8r/korrel8r/pkg/domains/log"
+	"github.com/stretchr/testify/assert"
 )
 
 var fixture = domain.Fixture{Query: log.NewQuery(log.Infrastructure, `{kubernetes_namespace_name=~".+"}`)}
 
 func TestLogDomain(t *testing.T)      { fixture.Test(t) }
 func BenchmarLogkDomain(b *testing.B) { fixture.Benchmark(b) }
+
+func TestClass_Timestamp(t *testing.T) {
+	want := time.Date(2024, 8, 7, 0, 0, 57, 951617585, time.UTC)
+	for _, o := range []log.Object{
+		{"@timestamp": "2024-08-07T00:00:57.951617585Z"},
+		{"timestamp": "2024-08-07T00:00:57.951617585Z"},
+	} {
+		start, end := log.Application.Timestamp(o)
+		assert.True(t, want.Equal(start), "%v", o)
+		assert.True(t, want.Equal(end), "%v", o)
+	}
+	start, _ := log.Application.Timestamp(log.Object{"message": "no time"})
+	assert.True(t, start.IsZero())
+}
@@ -1,27 +1,32 @@
 // Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
-//
-// # Template Functions
-//
-//	logTypeForNamespace
-//	    Takes a namespace string argument.
-//	    Returns the log type ("application" or "infrastructure") of a container in the namespace.
-//
-//	logSafeLabel
-//	    Convert the string argument into a  safe label containing only alphanumerics '_' and ':'.
+
 package log
 
-import "regexp"
+import (
+	"regexp"
 
-// TemplateFuncs for this domain. See package description.
-func (domain) TemplateFuncs() map[string]any { return funcs } // TODO document template functions.
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
 
-var (
-	funcs = map[string]any{
-		"logSafeLabel":        SafeLabel,
-		"logTypeForNamespace": logTypeForNamespace,
+// TemplateFuncs for this domain.
+func (domain) TemplateFuncs() []korrel8r.TemplateFunc {
+	return []korrel8r.TemplateFunc{
+		{
+			Name:        "logSafeLabel",
+			Func:        SafeLabel,
+			Description: "Converts the string argument into a safe label containing only alphanumerics '_' and ':'.",
+			Example:     `{{ logSafeLabel "app.kubernetes.io/name" }} => app_kubernetes_io_name`,
+		},
+		{
+			Name:        "logTypeForNamespace",
+			Func:        logTypeForNamespace,
+			Description: `Takes a namespace string argument. Returns the log type ("application" or "infrastructure") of a container in the namespace.`,
+			Example:     `{{ logTypeForNamespace "openshift-monitoring" }} => infrastructure`,
+		},
 	}
-	labelBad = regexp.MustCompile(`^[^a-zA-Z_:]|[^a-zA-Z0-9_:]`)
-)
+}
+
+var labelBad = regexp.MustCompile(`^[^a-zA-Z_:]|[^a-zA-Z0-9_:]`)
 
 // Returns a valid Loki stream label by replacing illegal characters in its argument with "_"
 func SafeLabel(label string) string { return labelBad.ReplaceAllString(label, "_") }
@@ -44,11 +44,12 @@ import (
 var (
 	Domain = domain{}
 	// Validate implementation of interfaces.
-	_ korrel8r.Domain = Domain
-	_ korrel8r.Class  = Class{}
-	_ korrel8r.Query  = Query("")
-	_ korrel8r.Store  = &Store{}
-	_ korrel8r.Object = Object{}
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.Query       = Query("")
+	_ korrel8r.Store       = &Store{}
+	_ korrel8r.Object      = Object{}
 )
 
 type domain struct{}
@@ -92,6 +93,9 @@ func (c Class) ID(o korrel8r.Object) any {
 	return nil
 }
 
+// Timestamp is unknown, a metric object identifies a time-series, not a point in time.
+func (c Class) Timestamp(korrel8r.Object) (start, end time.Time) { return time.Time{}, time.Time{} }
+
 type Object = model.Metric
 
 func Preview(o korrel8r.Object) string {
@@ -126,7 +130,7 @@ func (s *Store) Get(ctx context.Context, kquery korrel8r.Query, c *korrel8r.Cons
 	// NOTE: Store does not use github.com/prometheus/client_golang because the current version v1.19.1
 	// does not allow setting the "limit" query parameter. Hand code the REST query.
 	q := url.Values{}
-	selectors, err := query.Selectors()
+	selectors, err := query.selectors(c.GetNamespaces())
 	if err != nil {
 		return err
 	}
@@ -154,7 +158,9 @@ func (s *Store) Get(ctx context.Context, kquery korrel8r.Query, c *korrel8r.Cons
 		return fmt.Errorf("GET %v: unexpected status: %v", u, r.Status)
 	}
 	for _, m := range r.Data {
-		result.Append(m)
+		if c.AllowsNamespace(string(m[namespaceLabel])) {
+			result.Append(m)
+		}
 	}
 	return nil
 }
@@ -3,8 +3,12 @@
 package metric
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
@@ -17,11 +21,30 @@ import (
 // [PromQL]: https://prometheus.io/docs/prometheus/latest/querying/basics/
 type Query string
 
+// namespaceLabel is the series label for the namespace of a series.
+const namespaceLabel = "namespace"
+
 func (q Query) Class() korrel8r.Class { return Class{} }
 func (q Query) Data() string          { return string(q) }
 func (q Query) String() string        { return impl.QueryString(q) }
 
-func (q Query) Selectors() ([]string, error) {
+// Selectors returns the series selectors used by the query.
+func (q Query) Selectors() ([]string, error) { return q.selectors(nil) }
+
+// selectors returns the series selectors used by the query.
+// If namespaces is not empty, a namespace label matcher is added to each selector.
+func (q Query) selectors(namespaces []string) ([]string, error) {
+	var namespaceMatcher *labels.Matcher
+	if len(namespaces) > 0 {
+		patterns := make([]string, len(namespaces))
+		for i, ns := range namespaces {
+			patterns[i] = regexp.QuoteMeta(ns)
+		}
+		var err error
+		if namespaceMatcher, err = labels.NewMatcher(labels.MatchRegexp, namespaceLabel, strings.Join(patterns, "|")); err != nil {
+			return nil, err
+		}
+	}
 	var selectors []string
 	expr, err := parser.ParseExpr(string(q))
 	if err != nil {
@@ -29,6 +52,9 @@ func (q Query) Selectors() ([]string, error) {
 	}
 	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
 		if vs, ok := node.(*parser.VectorSelector); ok {
+			if namespaceMatcher != nil {
+				vs.LabelMatchers = append(vs.LabelMatchers, namespaceMatcher)
+			}
 			selectors = append(selectors, vs.String())
 		}
 		return nil
@@ -28,3 +28,10 @@ func TestQuery_Selectors(t *testing.T) {
 		})
 	}
 }
+
+func TestQuery_selectors_namespaces(t *testing.T) {
+	got, err := Query(`count(fred{name="x"}) + count(barney)`).selectors([]string{"a", "b.c"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{`fred{name="x",namespace=~"a|b\\.c"}`, `barney{namespace=~"a|b\\.c"}`}, got)
+	}
+}
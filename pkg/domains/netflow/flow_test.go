@@ -0,0 +1,66 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package netflow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_namespaces(t *testing.T) {
+	// Flows between namespaces a, b and c. The fake Loki applies the namespace matchers of the query.
+	flows := [][2]string{{"a", "b"}, {"b", "a"}, {"b", "c"}, {"c", "a"}, {"a", "a"}}
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("query")
+		queries = append(queries, q)
+		var values []string
+		for i, f := range flows {
+			if strings.Contains(q, `SrcK8S_Namespace=~"a"`) && f[0] != "a" || strings.Contains(q, `DstK8S_Namespace=~"a"`) && f[1] != "a" {
+				continue
+			}
+			values = append(values, fmt.Sprintf(`["%v","{\"SrcK8S_Namespace\":\"%v\",\"DstK8S_Namespace\":\"%v\"}"]`, i, f[0], f[1]))
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"streams","result":[{"stream":{},"values":[%v]}]}}`, strings.Join(values, ","))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := NewPlainLokiStore(u, server.Client())
+	require.NoError(t, err)
+	q, err := Domain.Query(`netflow:network:{FlowDirection="1"}`)
+	require.NoError(t, err)
+
+	get := func(c *korrel8r.Constraint) (got [][2]string) {
+		queries = nil
+		r := graph.NewResult(q.Class())
+		require.NoError(t, s.Get(context.Background(), q, c, r))
+		for _, o := range r.List() {
+			got = append(got, [2]string{o.(Object)["SrcK8S_Namespace"].(string), o.(Object)["DstK8S_Namespace"].(string)})
+		}
+		return got
+	}
+
+	// Either end in an allowed namespace, no duplicates.
+	assert.ElementsMatch(t, [][2]string{{"a", "b"}, {"b", "a"}, {"c", "a"}, {"a", "a"}}, get(&korrel8r.Constraint{Namespaces: []string{"a"}}))
+	assert.Equal(t, []string{`{SrcK8S_Namespace=~"a",FlowDirection="1"}`, `{DstK8S_Namespace=~"a",FlowDirection="1"}`}, queries)
+
+	// Limit applies to the total.
+	assert.Len(t, get(&korrel8r.Constraint{Namespaces: []string{"a"}, Limit: ptr.To(3)}), 3)
+	assert.Len(t, get(&korrel8r.Constraint{Namespaces: []string{"a"}, Limit: ptr.To(1)}), 1)
+	assert.Len(t, queries, 1, "limit reached by the first query")
+
+	// No namespace constraint, one query.
+	assert.Len(t, get(nil), len(flows))
+	assert.Equal(t, []string{`{FlowDirection="1"}`}, queries)
+}
@@ -4,18 +4,32 @@
 //
 // # Class
 //
-// There is a single class `netflow:network`
+// There are two classes:
+//
+//	netflow:network
+//	netflow:topology
+//
+// The `network` class returns individual flow records.
+// The `topology` class returns flows aggregated by source and destination workload,
+// with total bytes and packets over the constraint time window.
 //
 // # Object
 //
-// A log object is a JSON `map[string]any` in [NetFlow] format.
+// A `network` object is a JSON `map[string]any` in [NetFlow] format.
+//
+// A `topology` object is a [Topology].
 //
 // # Query
 //
-// A query is a [LogQL] query string, prefixed by `netflow:network:`, for example:
+// A `network` query is a [LogQL] query string, prefixed by `netflow:network:`, for example:
 //
 //	netflow:network:{SrcK8S_Type="Pod", SrcK8S_Namespace="myNamespace"}
 //
+// A `topology` query is a [LogQL] log query selecting the flows to aggregate, prefixed by `netflow:topology:`.
+// The store converts it to a LogQL metric query, so the aggregation is done by the Loki server. For example:
+//
+//	netflow:topology:{SrcK8S_Namespace="myNamespace", SrcK8S_OwnerName="myDeployment"}
+//
 // # Store
 //
 // To connect to a netflow lokiStack store use this configuration:
@@ -33,12 +47,17 @@
 package netflow
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/loki"
 	"github.com/korrel8r/korrel8r/pkg/config"
@@ -50,12 +69,18 @@ import (
 
 var (
 	// Verify implementing interfaces.
-	_ korrel8r.Domain    = Domain
-	_ korrel8r.Store     = &store{}
-	_ korrel8r.Store     = &stackStore{}
-	_ korrel8r.Query     = Query("")
-	_ korrel8r.Class     = Class{}
-	_ korrel8r.Previewer = Class{}
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Store       = &store{}
+	_ korrel8r.Store       = &stackStore{}
+	_ korrel8r.Query       = Query("")
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.Previewer   = Class{}
+	_ korrel8r.Query       = TopologyQuery("")
+	_ korrel8r.Class       = TopologyClass{}
+	_ korrel8r.Previewer   = TopologyClass{}
+	_ korrel8r.IDer        = TopologyClass{}
+	_ korrel8r.Timestamper = TopologyClass{}
 )
 
 // Domain for log records produced by openshift-logging.
@@ -68,16 +93,28 @@ var Domain = domain{}
 
 type domain struct{}
 
-func (domain) Name() string                     { return "netflow" }
-func (d domain) String() string                 { return d.Name() }
-func (domain) Description() string              { return "Network flows from source nodes to destination nodes." }
-func (domain) Class(name string) korrel8r.Class { return Class{} }
-func (domain) Classes() []korrel8r.Class        { return []korrel8r.Class{Class{}} }
+func (domain) Name() string        { return "netflow" }
+func (d domain) String() string    { return d.Name() }
+func (domain) Description() string { return "Network flows from source nodes to destination nodes." }
+func (domain) Class(name string) korrel8r.Class {
+	switch name {
+	case Class{}.Name():
+		return Class{}
+	case TopologyClass{}.Name():
+		return TopologyClass{}
+	default:
+		return nil
+	}
+}
+func (domain) Classes() []korrel8r.Class { return []korrel8r.Class{Class{}, TopologyClass{}} }
 func (d domain) Query(s string) (korrel8r.Query, error) {
-	_, s, err := impl.ParseQuery(d, s)
+	c, s, err := impl.ParseQuery(d, s)
 	if err != nil {
 		return nil, err
 	}
+	if _, ok := c.(TopologyClass); ok {
+		return NewTopologyQuery(s), nil
+	}
 	return Query(s), nil
 }
 
@@ -121,7 +158,7 @@ func (domain) Store(s any) (korrel8r.Store, error) {
 	}
 }
 
-// There is only a single class, named "netflow	".
+// Class for individual network flow records, named "network".
 type Class struct{}
 
 func (c Class) Domain() korrel8r.Domain { return Domain }
@@ -148,6 +185,21 @@ func Preview(x korrel8r.Object) (line string) {
 	return ""
 }
 
+// Timestamp is the interval from TimeFlowStartMs to TimeFlowEndMs.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	ms := func(k string) time.Time {
+		if v, ok := o.(Object)[k].(float64); ok && v > 0 {
+			return time.UnixMilli(int64(v))
+		}
+		return time.Time{}
+	}
+	start, end = ms("TimeFlowStartMs"), ms("TimeFlowEndMs")
+	if end.IsZero() {
+		end = start
+	}
+	return start, end
+}
+
 // Object is a map holding netflow entries
 type Object map[string]any
 
@@ -174,6 +226,125 @@ func (q Query) Class() korrel8r.Class { return Class{} }
 func (q Query) Data() string          { return string(q) }
 func (q Query) String() string        { return impl.QueryString(q) }
 
+// TopologyClass for flows aggregated by source and destination workload, named "topology".
+type TopologyClass struct{}
+
+func (c TopologyClass) Domain() korrel8r.Domain { return Domain }
+func (c TopologyClass) Name() string            { return "topology" }
+func (c TopologyClass) String() string          { return impl.ClassString(c) }
+func (c TopologyClass) Description() string {
+	return "Total bytes and packets sent between a source and destination workload."
+}
+func (c TopologyClass) Unmarshal(data []byte) (korrel8r.Object, error) {
+	return impl.UnmarshalAs[*Topology](data)
+}
+
+// ID of a topology object is the pair of source and destination workloads.
+func (c TopologyClass) ID(o korrel8r.Object) any {
+	if t, _ := o.(*Topology); t != nil {
+		return [2]Workload{t.Src, t.Dst}
+	}
+	return nil
+}
+
+func (c TopologyClass) Preview(o korrel8r.Object) string {
+	return impl.Preview(o, func(t *Topology) string {
+		return fmt.Sprintf("%v -> %v: %v bytes, %v packets", t.Src, t.Dst, t.Bytes, t.Packets)
+	})
+}
+
+// Timestamp is unknown, topology is aggregated over the time window of the query constraint.
+func (c TopologyClass) Timestamp(korrel8r.Object) (start, end time.Time) {
+	return time.Time{}, time.Time{}
+}
+
+// Workload identifies the owner of the source or destination of a flow.
+type Workload struct {
+	Namespace string `json:"namespace,omitempty"`
+	Kind      string `json:"kind,omitempty"` // Kind of the owner, e.g. Deployment, or Pod if there is no owner.
+	Name      string `json:"name,omitempty"`
+}
+
+func (w Workload) String() string { return fmt.Sprintf("%v %v/%v", w.Kind, w.Namespace, w.Name) }
+
+// Topology is a [TopologyClass] object: flows aggregated between two workloads.
+// Bytes and Packets are totals over the time window of the query constraint.
+type Topology struct {
+	Src     Workload `json:"src"`
+	Dst     Workload `json:"dst"`
+	Bytes   float64  `json:"bytes"`
+	Packets float64  `json:"packets"`
+}
+
+// TopologyQuery is a LogQL log query selecting the flows to be aggregated.
+type TopologyQuery string
+
+func NewTopologyQuery(logQL string) korrel8r.Query { return TopologyQuery(strings.TrimSpace(logQL)) }
+
+func (q TopologyQuery) Class() korrel8r.Class { return TopologyClass{} }
+func (q TopologyQuery) Data() string          { return string(q) }
+func (q TopologyQuery) String() string        { return impl.QueryString(q) }
+
+// Flow record fields used to group topology results.
+var (
+	srcLabels = []string{"SrcK8S_Namespace", "SrcK8S_OwnerType", "SrcK8S_OwnerName"}
+	dstLabels = []string{"DstK8S_Namespace", "DstK8S_OwnerType", "DstK8S_OwnerName"}
+	hasJSON   = regexp.MustCompile(`\|\s*json\b`)
+)
+
+// metricLogQL returns a LogQL metric query to sum field over the constraint interval, by workload.
+func (q TopologyQuery) metricLogQL(field string, c *korrel8r.Constraint) string {
+	logQL := q.Data()
+	if !hasJSON.MatchString(logQL) { // Don't parse twice, re-parsing renames the extracted labels.
+		logQL += " | json"
+	}
+	return fmt.Sprintf(`sum by (%v) (sum_over_time(%v | unwrap %v | __error__="" %v))`,
+		strings.Join(append(slices.Clone(srcLabels), dstLabels...), ","), logQL, field, loki.Range(c))
+}
+
+func workload(l loki.Labels, keys []string) Workload {
+	return Workload{Namespace: l[keys[0]], Kind: l[keys[1]], Name: l[keys[2]]}
+}
+
+// metricFunc evaluates a LogQL metric query.
+type metricFunc func(ctx context.Context, logQL string, c *korrel8r.Constraint, collect loki.SampleFunc) error
+
+// getTopology aggregates bytes and packets for a TopologyQuery using metric queries.
+func getTopology(ctx context.Context, q TopologyQuery, c *korrel8r.Constraint, result korrel8r.Appender, metric metricFunc) error {
+	var topologies []*Topology
+	byID := map[any]*Topology{}
+	collect := func(set func(*Topology, float64)) loki.SampleFunc {
+		return func(s *loki.Sample) {
+			t := &Topology{Src: workload(s.Labels, srcLabels), Dst: workload(s.Labels, dstLabels)}
+			id := TopologyClass{}.ID(t)
+			if byID[id] == nil {
+				byID[id] = t
+				topologies = append(topologies, t)
+			}
+			set(byID[id], s.Value)
+		}
+	}
+	if err := metric(ctx, q.metricLogQL("Bytes", c), c, collect(func(t *Topology, v float64) { t.Bytes = v })); err != nil {
+		return err
+	}
+	if err := metric(ctx, q.metricLogQL("Packets", c), c, collect(func(t *Topology, v float64) { t.Packets = v })); err != nil {
+		return err
+	}
+	// A flow is allowed if either end is in an allowed namespace.
+	topologies = slices.DeleteFunc(topologies, func(t *Topology) bool {
+		return !c.AllowsNamespace(t.Src.Namespace) && !c.AllowsNamespace(t.Dst.Namespace)
+	})
+	// Largest flows first, so the limit keeps the most significant.
+	slices.SortStableFunc(topologies, func(a, b *Topology) int { return cmp.Compare(b.Bytes, a.Bytes) })
+	if limit := c.GetLimit(); limit > 0 && len(topologies) > limit {
+		topologies = topologies[:limit]
+	}
+	for _, t := range topologies {
+		result.Append(t)
+	}
+	return nil
+}
+
 // NewLokiStackStore returns a store that uses a LokiStack observatorium-style URLs.
 func NewLokiStackStore(base *url.URL, h *http.Client) (korrel8r.Store, error) {
 	return &stackStore{store: store{loki.New(h, base)}}, nil
@@ -188,21 +359,88 @@ type store struct{ *loki.Client }
 
 func (store) Domain() korrel8r.Domain { return Domain }
 func (s *store) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.Constraint, result korrel8r.Appender) error {
+	if q, ok := query.(TopologyQuery); ok {
+		return getTopology(ctx, q, c, result, s.Client.Metric)
+	}
 	q, err := impl.TypeAssert[Query](query)
 	if err != nil {
 		return err
 	}
-	return s.Client.Get(ctx, q.Data(), c, func(e *loki.Entry) { result.Append(NewObject(e)) })
+	return getFlows(q, c, result, func(logQL string, collect loki.CollectFunc) error {
+		return s.Client.Get(ctx, logQL, c, collect)
+	})
 }
 
 type stackStore struct{ store }
 
 func (stackStore) Domain() korrel8r.Domain { return Domain }
 func (s *stackStore) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.Constraint, result korrel8r.Appender) error {
+	if q, ok := query.(TopologyQuery); ok {
+		metric := func(ctx context.Context, logQL string, c *korrel8r.Constraint, collect loki.SampleFunc) error {
+			return s.Client.MetricStack(ctx, logQL, tenant, c, collect)
+		}
+		return getTopology(ctx, q, c, result, metric)
+	}
 	q, err := impl.TypeAssert[Query](query)
 	if err != nil {
 		return err
 	}
+	return getFlows(q, c, result, func(logQL string, collect loki.CollectFunc) error {
+		return s.Client.GetStack(ctx, logQL, tenant, c, collect)
+	})
+}
 
-	return s.Client.GetStack(ctx, q.Data(), "network", c, func(e *loki.Entry) { result.Append(NewObject(e)) })
+// getFlows gets flows with either end in a namespace allowed by the constraint.
+// Stream selectors can't express "either", so a namespace constraint needs two queries:
+// one for flows from allowed namespaces, one for flows to allowed namespaces from elsewhere.
+// The total number of flows is limited by the constraint.
+func getFlows(q Query, c *korrel8r.Constraint, result korrel8r.Appender, get func(logQL string, collect loki.CollectFunc) error) error {
+	namespaces := c.GetNamespaces()
+	if len(namespaces) == 0 {
+		return get(q.Data(), func(e *loki.Entry) { result.Append(NewObject(e)) })
+	}
+	limit, count := c.GetLimit(), 0
+	appendIf := func(allow func(src, dst string) bool) loki.CollectFunc {
+		return func(e *loki.Entry) {
+			o := NewObject(e)
+			src, _ := o["SrcK8S_Namespace"].(string)
+			dst, _ := o["DstK8S_Namespace"].(string)
+			if allow(src, dst) && (limit <= 0 || count < limit) {
+				count++
+				result.Append(o)
+			}
+		}
+	}
+	if err := get(scopeLogQL(q.Data(), "SrcK8S_Namespace", namespaces), appendIf(func(src, _ string) bool {
+		return c.AllowsNamespace(src)
+	})); err != nil {
+		return err
+	}
+	if limit > 0 && count >= limit {
+		return nil
+	}
+	return get(scopeLogQL(q.Data(), "DstK8S_Namespace", namespaces), appendIf(func(src, dst string) bool {
+		return !c.AllowsNamespace(src) && c.AllowsNamespace(dst) // Flows from allowed namespaces were already added.
+	}))
+}
+
+// scopeLogQL adds a matcher for label to the stream selector of logQL, matching any of namespaces.
+func scopeLogQL(logQL, label string, namespaces []string) string {
+	i := strings.Index(logQL, "{")
+	j := strings.Index(logQL, "}")
+	if i < 0 || j < i {
+		return logQL
+	}
+	patterns := make([]string, len(namespaces))
+	for k, ns := range namespaces {
+		patterns[k] = regexp.QuoteMeta(ns)
+	}
+	matcher := label + "=~" + strconv.Quote(strings.Join(patterns, "|"))
+	if strings.TrimSpace(logQL[i+1:j]) != "" {
+		matcher += ","
+	}
+	return logQL[:i+1] + matcher + logQL[i+1:]
 }
+
+// tenant for netflow records in a LokiStack.
+const tenant = "network"
@@ -0,0 +1,72 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package netflow
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const vector = `{"status":"success","data":{"resultType":"vector","result":[
+{"metric":{"SrcK8S_Namespace":"a","SrcK8S_OwnerType":"Deployment","SrcK8S_OwnerName":"x","DstK8S_Namespace":"b","DstK8S_OwnerType":"StatefulSet","DstK8S_OwnerName":"y"},"value":[1722989751.985,"%v"]},
+{"metric":{"SrcK8S_Namespace":"b","SrcK8S_OwnerType":"StatefulSet","SrcK8S_OwnerName":"y","DstK8S_Namespace":"a","DstK8S_OwnerType":"Deployment","DstK8S_OwnerName":"x"},"value":[1722989751.985,"%v"]}
+]}}`
+
+func TestTopologyStore(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/logs/v1/network/loki/api/v1/query", r.URL.Path)
+		queries = append(queries, r.URL.Query())
+		if strings.Contains(r.URL.Query().Get("query"), "unwrap Bytes") {
+			fmt.Fprintf(w, vector, 100, 2000)
+		} else {
+			fmt.Fprintf(w, vector, 1, 20)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := NewLokiStackStore(u, server.Client())
+	require.NoError(t, err)
+
+	q, err := Domain.Query(`netflow:topology:{SrcK8S_Namespace="a"}`)
+	require.NoError(t, err)
+	end := time.Unix(1722989751, 0)
+	c := &korrel8r.Constraint{Start: ptr.To(end.Add(-time.Minute)), End: &end}
+	r := graph.NewResult(q.Class())
+	require.NoError(t, s.Get(context.Background(), q, c, r))
+
+	x := Workload{Namespace: "a", Kind: "Deployment", Name: "x"}
+	y := Workload{Namespace: "b", Kind: "StatefulSet", Name: "y"}
+	assert.Equal(t, []korrel8r.Object{
+		&Topology{Src: y, Dst: x, Bytes: 2000, Packets: 20},
+		&Topology{Src: x, Dst: y, Bytes: 100, Packets: 1},
+	}, r.List())
+	require.Len(t, queries, 2)
+	assert.Equal(t, `sum by (SrcK8S_Namespace,SrcK8S_OwnerType,SrcK8S_OwnerName,DstK8S_Namespace,DstK8S_OwnerType,DstK8S_OwnerName) (sum_over_time({SrcK8S_Namespace="a"} | json | unwrap Bytes | __error__="" [60s]))`, queries[0].Get("query"))
+	assert.Equal(t, "1722989751000000000", queries[0].Get("time"))
+
+	// Limit keeps the largest flows, de-duplication merges repeated results.
+	c.Limit = ptr.To(1)
+	r = graph.NewResult(q.Class())
+	require.NoError(t, s.Get(context.Background(), q, c, r))
+	require.NoError(t, s.Get(context.Background(), q, c, r))
+	assert.Equal(t, []korrel8r.Object{&Topology{Src: y, Dst: x, Bytes: 2000, Packets: 20}}, r.List())
+}
+
+func TestTopologyQuery_metricLogQL(t *testing.T) {
+	q := TopologyQuery(`{SrcK8S_Namespace="a"} | json | SrcK8S_Name="p"`)
+	assert.Equal(t, `sum by (SrcK8S_Namespace,SrcK8S_OwnerType,SrcK8S_OwnerName,DstK8S_Namespace,DstK8S_OwnerType,DstK8S_OwnerName) (sum_over_time({SrcK8S_Namespace="a"} | json | SrcK8S_Name="p" | unwrap Packets | __error__="" [3600s]))`,
+		q.metricLogQL("Packets", nil))
+}
@@ -0,0 +1,223 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package profile is a domain for continuous profiles stored in a [Pyroscope] compatible store.
+//
+// # Class
+//
+// There is a single class `profile:profile`.
+//
+// # Object
+//
+// A profile object describes a profile series: the profile type, the labels identifying the profiled process,
+// and the time interval of the query. The profile data itself is not included.
+// The object includes a URL to render the profile from the store.
+//
+// # Query
+//
+// A query is a Pyroscope label selector, for example:
+//
+//	profile:profile:{namespace="foo", pod="bar"}
+//
+// The special label `__profile_type__` selects a profile type, for example:
+//
+//	profile:profile:{service_name="foo", __profile_type__="process_cpu:cpu:nanoseconds:cpu:nanoseconds"}
+//
+// # Store
+//
+// The profile domain requires a "pyroscope" field with the base URL of the Pyroscope server.
+//
+//	domain: profile
+//	pyroscope: URL_OF_PYROSCOPE
+//
+// [Pyroscope]: https://grafana.com/docs/pyroscope/latest/
+package profile
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/pyroscope"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
+	"golang.org/x/exp/maps"
+)
+
+var (
+	// Verify implementing interfaces.
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Store       = &store{}
+	_ korrel8r.Query       = Query("")
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
+	_ korrel8r.IDer        = Class{}
+	_ korrel8r.Previewer   = Class{}
+)
+
+// Domain for continuous profiles.
+var Domain = domain{}
+
+type domain struct{}
+
+func (domain) Name() string                     { return "profile" }
+func (d domain) String() string                 { return d.Name() }
+func (domain) Description() string              { return "Continuous profiles of running processes." }
+func (domain) Class(name string) korrel8r.Class { return Class{} }
+func (domain) Classes() []korrel8r.Class        { return []korrel8r.Class{Class{}} }
+func (d domain) Query(s string) (korrel8r.Query, error) {
+	_, s, err := impl.ParseQuery(d, s)
+	if err != nil {
+		return nil, err
+	}
+	return NewQuery(s), nil
+}
+
+const StoreKeyPyroscope = "pyroscope"
+
+func (domain) Store(s any) (korrel8r.Store, error) {
+	cs, err := impl.TypeAssert[config.Store](s)
+	if err != nil {
+		return nil, err
+	}
+	hc, err := k8s.NewHTTPClient(cs)
+	if err != nil {
+		return nil, err
+	}
+	pyroscope := cs[StoreKeyPyroscope]
+	if pyroscope == "" {
+		return nil, fmt.Errorf("must set pyroscope URL")
+	}
+	u, err := url.Parse(pyroscope)
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(u, hc)
+}
+
+// Class singleton `profile:profile` for profile series.
+type Class struct{}
+
+func (c Class) Domain() korrel8r.Domain                     { return Domain }
+func (c Class) Name() string                                { return "profile" }
+func (c Class) String() string                              { return impl.ClassString(c) }
+func (c Class) Description() string                         { return "A profile series for a profiled process." }
+func (c Class) Unmarshal(b []byte) (korrel8r.Object, error) { return impl.UnmarshalAs[Object](b) }
+
+// ID is the selector for the profile series.
+func (c Class) ID(o korrel8r.Object) any {
+	if p, _ := o.(Object); p != nil {
+		return p.Selector()
+	}
+	return nil
+}
+
+func (c Class) Preview(o korrel8r.Object) string {
+	return impl.Preview(o, func(p Object) string { return p.Selector() })
+}
+
+// Timestamp is the time interval of the profile series.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	if p, _ := o.(Object); p != nil {
+		return p.Start, p.End
+	}
+	return time.Time{}, time.Time{}
+}
+
+// Object is a profile series.
+type Object = *Profile
+
+// Profile describes a series of profiles of the same type from the same process.
+type Profile struct {
+	// Type is the Pyroscope profile type, for example "process_cpu:cpu:nanoseconds:cpu:nanoseconds".
+	Type string `json:"type"`
+	// Labels identifying the profiled process.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Start of the time interval.
+	Start time.Time `json:"start"`
+	// End of the time interval.
+	End time.Time `json:"end"`
+	// URL to render the profile for the time interval.
+	URL string `json:"url,omitempty"`
+}
+
+// Selector is a Pyroscope query selecting this profile series.
+func (p *Profile) Selector() string {
+	b := &strings.Builder{}
+	b.WriteString(p.Type)
+	b.WriteString("{")
+	keys := maps.Keys(p.Labels)
+	slices.Sort(keys)
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(b, "%v=%v", k, strconv.Quote(p.Labels[k]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Query is a Pyroscope label selector.
+type Query string
+
+func NewQuery(selector string) korrel8r.Query { return Query(strings.TrimSpace(selector)) }
+
+func (q Query) Class() korrel8r.Class { return Class{} }
+func (q Query) Data() string          { return string(q) }
+func (q Query) String() string        { return impl.QueryString(q) }
+
+// NewStore returns a store for a Pyroscope server.
+func NewStore(base *url.URL, h *http.Client) (korrel8r.Store, error) {
+	return &store{pyroscope.New(h, base)}, nil
+}
+
+type store struct{ *pyroscope.Client }
+
+func (store) Domain() korrel8r.Domain { return Domain }
+
+const profileTypeLabel = "__profile_type__"
+
+func (s *store) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) error {
+	q, err := impl.TypeAssert[Query](query)
+	if err != nil {
+		return err
+	}
+	end := constraint.GetEnd()
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := constraint.GetStart()
+	if start.IsZero() {
+		start = end.Add(-korrel8r.DefaultDuration)
+	}
+	series, err := s.Client.Series(ctx, q.Data(), start, end)
+	if err != nil {
+		return err
+	}
+	n := 0
+	for _, labels := range series {
+		if limit := constraint.GetLimit(); limit > 0 && n >= limit {
+			break
+		}
+		if !constraint.AllowsNamespace(labels["namespace"]) {
+			continue
+		}
+		n++
+		p := &Profile{Type: labels[profileTypeLabel], Labels: map[string]string{}, Start: start, End: end}
+		for k, v := range labels {
+			if !strings.HasPrefix(k, "__") { // Omit internal labels
+				p.Labels[k] = v
+			}
+		}
+		p.URL = s.Client.RenderURL(p.Selector(), start, end).String()
+		result.Append(p)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package profile_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/domain"
+	"github.com/korrel8r/korrel8r/pkg/domains/profile"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixture = domain.Fixture{Query: profile.NewQuery(`{namespace="pyroscope-demo"}`), SkipCluster: true}
+
+func TestProfileDomain(t *testing.T)      { fixture.Test(t) }
+func BenchmarkProfileDomain(b *testing.B) { fixture.Benchmark(b) }
+
+func TestStore_Get(t *testing.T) {
+	recorded, err := os.ReadFile("testdata/series.json")
+	require.NoError(t, err)
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/querier.v1.QuerierService/Series", r.URL.Path)
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write(recorded)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := profile.NewStore(u, server.Client())
+	require.NoError(t, err)
+
+	end := time.Date(2024, 8, 7, 0, 15, 51, 0, time.UTC)
+	start := end.Add(-time.Hour)
+	var result graph.ListResult
+	q := profile.NewQuery(`{namespace="pyroscope-demo", pod="ride-sharing-app-0"}`)
+	require.NoError(t, s.Get(context.Background(), q, &korrel8r.Constraint{Start: &start, End: &end, Limit: ptr.To(2)}, &result))
+
+	assert.Equal(t, map[string]any{
+		"matchers": []any{`{namespace="pyroscope-demo", pod="ride-sharing-app-0"}`},
+		"start":    float64(start.UnixMilli()),
+		"end":      float64(end.UnixMilli()),
+	}, body)
+	require.Len(t, result, 2)
+	p := result[0].(profile.Object)
+	labels := map[string]string{"namespace": "pyroscope-demo", "pod": "ride-sharing-app-0", "service_name": "pyroscope-demo/ride-sharing-app"}
+	assert.Equal(t, &profile.Profile{
+		Type:   "process_cpu:cpu:nanoseconds:cpu:nanoseconds",
+		Labels: labels,
+		Start:  start,
+		End:    end,
+		URL: server.URL + "/pyroscope/render?from=1722986151&query=" +
+			url.QueryEscape(`process_cpu:cpu:nanoseconds:cpu:nanoseconds{namespace="pyroscope-demo",pod="ride-sharing-app-0",service_name="pyroscope-demo/ride-sharing-app"}`) +
+			"&until=1722989751",
+	}, p)
+	assert.Equal(t, "memory:alloc_space:bytes:space:bytes", result[1].(profile.Object).Type)
+}
+
+func TestStore_Get_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code":"invalid_argument","message":"parse error"}`, http.StatusBadRequest)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	s, err := profile.NewStore(u, server.Client())
+	require.NoError(t, err)
+	var result graph.ListResult
+	assert.ErrorContains(t, s.Get(context.Background(), profile.NewQuery(`{`), nil, &result), "parse error")
+}
@@ -63,6 +63,8 @@ func (c *client) GetStack(ctx context.Context, traceQL string, constraint *korre
 const ( // Tempo query keywords and field names
 	query      = "q"
 	statusAttr = "status"
+
+	namespaceAttr = "resource.k8s.namespace.name"
 )
 
 var (
@@ -83,6 +85,7 @@ var (
 		"resource.net.peer.name",
 		"resource.net.peer.port",
 		"resource.service.name",
+		"span.pyroscope.profile.id", // Links spans to profiles, see the profile rules.
 	}, ",")
 )
 
@@ -94,11 +97,24 @@ func defaultSelect(traceQL string) string {
 	return traceQL
 }
 
+// scopeTraceQL adds a namespace filter to the query if the constraint restricts namespaces.
+func scopeTraceQL(traceQL string, constraint *korrel8r.Constraint) string {
+	namespaces := constraint.GetNamespaces()
+	if len(namespaces) == 0 {
+		return traceQL
+	}
+	patterns := make([]string, len(namespaces))
+	for i, ns := range namespaces {
+		patterns[i] = regexp.QuoteMeta(ns)
+	}
+	return fmt.Sprintf("%v | { %v =~ %v }", traceQL, namespaceAttr, strconv.Quote(strings.Join(patterns, "|")))
+}
+
 func formatTime(t time.Time) string { return strconv.FormatInt(t.UTC().Unix(), 10) }
 
 func (c *client) get(ctx context.Context, traceQL string, constraint *korrel8r.Constraint, collect func(*Span)) error {
 	u := *c.base // Copy, don't modify base.
-	v := url.Values{query: []string{defaultSelect(traceQL)}}
+	v := url.Values{query: []string{defaultSelect(scopeTraceQL(traceQL, constraint))}}
 	if limit := constraint.GetLimit(); limit > 0 {
 		v.Add("limit", strconv.Itoa(limit)) // Limit is max number of traces, not spans.
 	}
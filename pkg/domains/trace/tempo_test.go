@@ -3,10 +3,17 @@
 package trace
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -119,3 +126,27 @@ func TestUnmarshal(t *testing.T) {
 	}
 	assert.Equal(t, want, spans)
 }
+
+func TestScopeTraceQL(t *testing.T) {
+	assert.Equal(t, `{}`, scopeTraceQL(`{}`, nil))
+	assert.Equal(t, `{} | { resource.k8s.namespace.name =~ "a|b" }`,
+		scopeTraceQL(`{}`, &korrel8r.Constraint{Namespaces: []string{"a", "b"}}))
+}
+
+func TestClient_defaultSelect(t *testing.T) {
+	// Fake Tempo returns span attributes only if they are selected.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var attrs []string
+		if strings.Contains(r.URL.Query().Get(query), "span.pyroscope.profile.id") {
+			attrs = append(attrs, `{"key":"pyroscope.profile.id","value":{"stringValue":"563d623c76514f8e"}}`)
+		}
+		fmt.Fprintf(w, `{"traces":[{"traceID":"1","rootServiceName":"svc","spanSet":{"spans":[{"spanID":"563d623c76514f8e","attributes":[%v]}]}}]}`,
+			strings.Join(attrs, ","))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	var spans []*Span
+	require.NoError(t, newClient(server.Client(), u).get(context.Background(), `{}`, nil, func(s *Span) { spans = append(spans, s) }))
+	require.Len(t, spans, 1)
+	assert.Equal(t, "563d623c76514f8e", spans[0].Attributes["pyroscope.profile.id"])
+}
@@ -31,10 +31,11 @@ import (
 
 var (
 	// Verify implementing interfaces.
-	_ korrel8r.Domain = Domain
-	_ korrel8r.Store  = &stackStore{}
-	_ korrel8r.Query  = Query("")
-	_ korrel8r.Class  = Class{}
+	_ korrel8r.Domain      = Domain
+	_ korrel8r.Store       = &stackStore{}
+	_ korrel8r.Query       = Query("")
+	_ korrel8r.Class       = Class{}
+	_ korrel8r.Timestamper = Class{}
 )
 
 var Domain = domain{}
@@ -101,6 +102,22 @@ func (c Class) ID(o korrel8r.Object) any {
 	return nil
 }
 
+// Severity is an error for spans with status code "Error".
+func (c Class) Severity(o korrel8r.Object) korrel8r.Severity {
+	if span, _ := o.(Object); span != nil && span.Status.Code == StatusError {
+		return korrel8r.SeverityError
+	}
+	return korrel8r.SeverityNone
+}
+
+// Timestamp is the interval from the start to the end of the span.
+func (c Class) Timestamp(o korrel8r.Object) (start, end time.Time) {
+	if span, _ := o.(Object); span != nil {
+		return span.StartTime, span.EndTime
+	}
+	return time.Time{}, time.Time{}
+}
+
 // Object represents an OpenTelemetry [span]
 //
 // A trace is simply a set of spans with the same trace-id.
@@ -204,5 +221,12 @@ func (s *stackStore) Get(ctx context.Context, query korrel8r.Query, c *korrel8r.
 		return err
 	}
 
-	return s.client.GetStack(ctx, q.Data(), c, func(s *Span) { result.Append(s) })
+	return s.client.GetStack(ctx, q.Data(), c, func(s *Span) {
+		if len(c.GetNamespaces()) > 0 {
+			if ns, _ := s.Attributes["k8s.namespace.name"].(string); !c.AllowsNamespace(ns) {
+				return
+			}
+		}
+		result.Append(s)
+	})
 }
@@ -2,9 +2,11 @@
 
 // # Template Functions
 //
-//	query
-//	    Executes its argument as a korrel8r query, returns []any.
-//	    May return an error.
+// Rule templates can use the [sprig] functions, the engine functions `query` and `timeAdd`,
+// and functions provided by domains that implement [korrel8r.TemplateFuncer].
+// See [Engine.TemplateFuncs] or the `korrel8r functions` command for a list.
+//
+// [sprig]: https://masterminds.github.io/sprig/
 package engine
 
 import (
@@ -23,19 +25,60 @@ import (
 // Builder initializes the state of an engine.
 // Engine() returns the immutable engine instance.
 type Builder struct {
-	e   *Engine
-	err error
+	e      *Engine
+	err    error
+	replay string // Directory to replay, see [Builder.Replay]
 }
 
 func Build() *Builder {
 	e := &Engine{
-		domains:     map[string]korrel8r.Domain{},
-		stores:      map[korrel8r.Domain]*stores{},
-		rulesByName: map[string]korrel8r.Rule{},
+		domains:       map[string]korrel8r.Domain{},
+		stores:        map[korrel8r.Domain]*stores{},
+		rulesByName:   map[string]korrel8r.Rule{},
+		templateFuncs: template.FuncMap{},
+		funcs:         map[string]korrel8r.TemplateFunc{},
+		costs:         newCosts(),
+	}
+	b := &Builder{e: e}
+	for name, f := range sprig.TxtFuncMap() {
+		b.templateFuncs(korrel8r.TemplateFunc{Name: name, Func: f, Description: sprigDescription, Source: sprigSource})
+	}
+	b.templateFuncs(korrel8r.TemplateFunc{
+		Name:        "timeAdd",
+		Func:        timeAdd,
+		Description: "Adds a duration to a time.Time or an RFC 3339 time string, returns an RFC 3339 string, or blank if the time is missing or blank. Used in rule constraint templates.",
+		Example:     `{{ timeAdd "-5m" .timestamp }}`,
+		Source:      engineSource,
+	})
+	b.templateFuncs(korrel8r.TemplateFunc{
+		Name:        "query",
+		Func:        e.query,
+		Description: "Executes its argument as a korrel8r query, returns []any. May return an error. Denied in rules if per-user authorization is enabled.",
+		Example:     `{{ range query "k8s:Pod.v1.:{\"namespace\":\"x\"}" }}...{{ end }}`,
+		Source:      engineSource,
+	})
+	return b
+}
+
+const (
+	sprigSource      = "sprig"
+	engineSource     = "engine"
+	sprigDescription = "Sprig function, see https://masterminds.github.io/sprig/"
+)
+
+// templateFuncs registers template functions, it is an error if a function name is already registered.
+func (b *Builder) templateFuncs(funcs ...korrel8r.TemplateFunc) {
+	for _, f := range funcs {
+		if b.err != nil {
+			return
+		}
+		if f2, ok := b.e.funcs[f.Name]; ok {
+			b.err = fmt.Errorf("template function %v from %v: already defined by %v", f.Name, f.Source, f2.Source)
+			return
+		}
+		b.e.funcs[f.Name] = f
+		b.e.templateFuncs[f.Name] = f.Func
 	}
-	e.templateFuncs = template.FuncMap{"query": e.query}
-	maps.Copy(e.templateFuncs, sprig.TxtFuncMap())
-	return &Builder{e: e}
 }
 
 func (b *Builder) Domains(domains ...korrel8r.Domain) *Builder {
@@ -45,8 +88,11 @@ func (b *Builder) Domains(domains ...korrel8r.Domain) *Builder {
 		case nil:
 			b.e.domains[d.Name()] = d
 			b.e.stores[d] = newStores(b.e, d)
-			if tf, ok := d.(interface{ TemplateFuncs() map[string]any }); ok {
-				maps.Copy(b.e.templateFuncs, tf.TemplateFuncs())
+			if tf, ok := d.(korrel8r.TemplateFuncer); ok {
+				for _, f := range tf.TemplateFuncs() {
+					f.Source = d.Name()
+					b.templateFuncs(f)
+				}
 			}
 		default:
 			b.err = fmt.Errorf("Duplicate domain name: %v", d.Name())
@@ -103,11 +149,43 @@ func (b *Builder) Rules(rules ...korrel8r.Rule) *Builder {
 	return b
 }
 
+// Authorizer checks every [Engine.Get] with a, see [Authorizer].
+func (b *Builder) Authorizer(a Authorizer) *Builder {
+	b.e.authorizer = a
+	return b
+}
+
+// Record the results of every [Engine.Get] in directory dir.
+// The recording can be replayed by an engine built with [Builder.Replay].
+func (b *Builder) Record(dir string) *Builder {
+	if b.err == nil {
+		b.e.recorder, b.err = newRecorder(dir)
+	}
+	return b
+}
+
+// Replay a recording made with [Builder.Record] in directory dir.
+// Each recorded domain gets a mock store that returns the recorded results,
+// all other store configuration is ignored.
+func (b *Builder) Replay(dir string) *Builder {
+	b.replay = dir
+	return b
+}
+
 // Config an engine.Builder.
+// Aliases in the configuration are expanded, the configs parameter is not modified.
 func (b *Builder) Config(configs config.Configs) *Builder {
 	if b.err != nil {
 		return b
 	}
+	configs, b.err = configs.Expand()
+	if b.err != nil {
+		return b
+	}
+	if len(configs) > 0 && configs[0].Tuning != nil { // Only the main config has a tuning section.
+		maps.Copy(b.e.costs.domains, configs[0].Tuning.DomainCosts)
+		b.e.costs.learn = configs[0].Tuning.LearnCosts
+	}
 	for source, c := range configs {
 		b.config(c.Source, &c)
 		if b.err != nil {
@@ -130,6 +208,15 @@ func (b *Builder) ConfigFile(file string) *Builder {
 // Engine returns the final engine, which can no longer be modified.
 // The Builder must not be used after calling Engine()
 func (b *Builder) Engine() (*Engine, error) {
+	if b.replay != "" && b.err == nil {
+		var stores []config.Store
+		if stores, b.err = replayStores(b.replay); b.err == nil {
+			for d := range b.e.stores {
+				b.e.stores[d] = newStores(b.e, d) // Replace configured stores.
+			}
+			b.StoreConfigs(stores...)
+		}
+	}
 	e := b.e
 	b.e = nil
 	// Create all stores to report problems early.
@@ -161,8 +248,29 @@ func (b *Builder) config(source string, c *config.Config) {
 		if b.err != nil {
 			return
 		}
-		b.Rules(rules.NewTemplateRule(start, goal, tmpl))
+		if c := r.Result.Constraint; c != nil {
+			startTime, endTime := b.timeTemplate(r.Name+".start", c.Start), b.timeTemplate(r.Name+".end", c.End)
+			if b.err != nil {
+				return
+			}
+			b.Rules(rules.NewIntervalTemplateRule(start, goal, tmpl, startTime, endTime))
+		} else {
+			b.Rules(rules.NewTemplateRule(start, goal, tmpl))
+		}
+		if r.Cost > 0 {
+			b.e.costs.rules[r.Name] = r.Cost
+		}
+	}
+}
+
+// timeTemplate parses a constraint time template, returns nil if text is empty.
+func (b *Builder) timeTemplate(name, text string) *template.Template {
+	if b.err != nil || text == "" {
+		return nil
 	}
+	var tmpl *template.Template
+	tmpl, b.err = b.e.NewTemplate(name).Parse(text)
+	return tmpl
 }
 
 func (b *Builder) classes(spec *config.ClassSpec) []korrel8r.Class {
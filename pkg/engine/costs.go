@@ -0,0 +1,78 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// Units used to convert observed store behaviour into a learned domain cost.
+const (
+	latencyUnit = time.Second // Each second of mean latency adds 1 to the cost.
+	countUnit   = 1000        // Each countUnit of mean results adds 1 to the cost.
+)
+
+// costs holds configured costs for rules and domains, and learns domain costs from store statistics.
+// Concurrency: configured costs are immutable once built, learned statistics are protected by a lock.
+type costs struct {
+	rules   map[string]float64 // Configured rule costs by rule name.
+	domains map[string]float64 // Configured domain costs by domain name.
+	learn   bool               // Learn costs for domains without a configured cost.
+
+	m     sync.Mutex
+	stats map[string]*storeStats // Observed statistics by domain name.
+}
+
+type storeStats struct {
+	n       int
+	latency time.Duration // Total latency.
+	count   int           // Total results.
+}
+
+func newCosts() *costs {
+	return &costs{rules: map[string]float64{}, domains: map[string]float64{}, stats: map[string]*storeStats{}}
+}
+
+func (c *costs) rule(name string) float64 {
+	if cost, ok := c.rules[name]; ok {
+		return cost
+	}
+	return 1
+}
+
+// domain returns the configured cost, or a learned cost, or 1.
+// The learned cost is 1 plus mean latency in latencyUnit plus mean result count in countUnit.
+func (c *costs) domain(name string) float64 {
+	if cost, ok := c.domains[name]; ok {
+		return cost
+	}
+	if !c.learn {
+		return 1
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	s := c.stats[name]
+	if s == nil || s.n == 0 {
+		return 1
+	}
+	n := float64(s.n)
+	return 1 + float64(s.latency)/float64(latencyUnit)/n + float64(s.count)/countUnit/n
+}
+
+// observe records the latency and result count of a successful query.
+func (c *costs) observe(domain string, latency time.Duration, count int) {
+	if !c.learn {
+		return
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	s := c.stats[domain]
+	if s == nil {
+		s = &storeStats{}
+		c.stats[domain] = s
+	}
+	s.n++
+	s.latency += latency
+	s.count += count
+}
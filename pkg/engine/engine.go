@@ -13,10 +13,12 @@ import (
 	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/logging"
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
 	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r/impl"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/maps"
 )
 
@@ -28,8 +30,19 @@ type Engine struct {
 	domains       map[string]korrel8r.Domain
 	stores        map[korrel8r.Domain]*stores
 	templateFuncs template.FuncMap
+	funcs         map[string]korrel8r.TemplateFunc
 	rulesByName   map[string]korrel8r.Rule
 	rules         []korrel8r.Rule
+	costs         *costs
+	recorder      *recorder  // Records store results if not nil.
+	authorizer    Authorizer // Authorizes store queries if not nil.
+}
+
+// Authorizer checks that the caller identified by a context may get the results of a query.
+type Authorizer interface {
+	// Authorize returns the constraint to use for the query, possibly narrowed to what the caller may see.
+	// Returns a [korrel8r.DeniedError] if the caller may not see any results of the query.
+	Authorize(ctx context.Context, q korrel8r.Query, c *korrel8r.Constraint) (*korrel8r.Constraint, error)
 }
 
 // Domain returns the named domain or nil if not found.
@@ -111,8 +124,31 @@ func (e *Engine) Rule(name string) korrel8r.Rule { return e.rulesByName[name] }
 // Graph creates a new graph of the engine's rules.
 func (e *Engine) Graph() *graph.Graph { return graph.NewData(e.Rules()...).FullGraph() }
 
+// Cost returns the cost of following a line in a graph of the engine's rules.
+// It is the configured cost of the rule multiplied by the cost of the goal domain.
+// Cost can be used with [graph.Graph.CheapestPaths].
+func (e *Engine) Cost(l *graph.Line) float64 {
+	return e.costs.rule(l.Rule.Name()) * e.DomainCost(l.Goal().Class.Domain())
+}
+
+// DomainCost returns the cost of getting a query from a domain.
+// The cost is configured, or learned from store latency and result counts if enabled, otherwise 1.
+func (e *Engine) DomainCost(d korrel8r.Domain) float64 { return e.costs.domain(d.Name()) }
+
 // Get results for query from all stores for the query domain.
+//
+// If the query class is a [korrel8r.Timestamper], objects outside the constraint time interval are
+// dropped, regardless of how the store filters by time.
 func (e *Engine) Get(ctx context.Context, query korrel8r.Query, constraint *korrel8r.Constraint, result korrel8r.Appender) (err error) {
+	count := 0 // Count results
+	ctx, span := tracing.Tracer().Start(ctx, "korrel8r.engine.get", trace.WithAttributes(
+		tracing.Domain.String(query.Class().Domain().Name()),
+		tracing.Class.String(query.Class().String()),
+		tracing.Query.String(query.String())))
+	defer func() {
+		span.SetAttributes(tracing.Count.Int(count))
+		tracing.End(span, err)
+	}()
 	constraint = constraint.Default()
 	if timeout := constraint.GetTimeout(); timeout > 0 {
 		var cancel func()
@@ -123,22 +159,54 @@ func (e *Engine) Get(ctx context.Context, query korrel8r.Query, constraint *korr
 	if ss == nil {
 		return korrel8r.StoreNotFoundError{Domain: query.Class().Domain()}
 	}
-	r := result
-	if log.V(3).Enabled() { // Don't add overhead unless trace logging is enabled.
-		start := time.Now() // Measure latency
-		count := 0          // Count results
-		r = korrel8r.AppenderFunc(func(o korrel8r.Object) { result.Append(o); count++ })
-		defer func() {
-			if err == nil {
-				log.V(3).Info("Engine: Get OK", "n", count, "t", time.Since(start), "query", query)
+	if e.authorizer != nil {
+		if constraint, err = e.authorizer.Authorize(ctx, query, constraint); err != nil {
+			if korrel8r.IsDenied(err) {
+				queriesDenied.WithLabelValues(query.Class().Domain().Name()).Inc()
 			}
-		}()
+			return err
+		}
 	}
+	start := time.Now() // Measure latency
+	class := query.Class()
+	var recorded []korrel8r.Object // Objects returned by stores, if recording.
+	r := korrel8r.AppenderFunc(func(o korrel8r.Object) {
+		if e.recorder != nil {
+			recorded = append(recorded, o)
+		}
+		if constraint.Overlaps(korrel8r.TimestampOf(class, o)) {
+			result.Append(o)
+			count++
+		}
+	})
+	defer func() {
+		if e.recorder != nil {
+			if rerr := e.recorder.record(query, constraint, recorded, err); rerr != nil {
+				log.Error(rerr, "Engine: recording failed", "query", query)
+			}
+		}
+		if err == nil {
+			latency := time.Since(start)
+			e.costs.observe(query.Class().Domain().Name(), latency, count)
+			log.V(3).Info("Engine: Get OK", "n", count, "t", latency, "query", query)
+		}
+	}()
 	return ss.Get(ctx, query, constraint, r)
 }
 
 // query implements the template function 'query'.
+// Rule templates are executed without the caller's identity, so an engine with an [Authorizer]
+// denies all template queries rather than returning objects the caller may not see.
 func (e *Engine) query(query string) ([]korrel8r.Object, error) {
+	if e.authorizer != nil {
+		return nil, korrel8r.DeniedError{Reason: "template function query is disabled with per-user authorization"}
+	}
+	return e.storeQuery(query)
+}
+
+// storeQuery implements the template function 'query' for store configuration templates.
+// Store configuration is set up by the server, not by a caller, and is allowed to query with the server's credentials.
+func (e *Engine) storeQuery(query string) ([]korrel8r.Object, error) {
 	q, err := e.Query(query)
 	if err != nil {
 		return nil, err
@@ -148,6 +216,13 @@ func (e *Engine) query(query string) ([]korrel8r.Object, error) {
 	return results.List(), err
 }
 
+// TemplateFuncs returns descriptions of all template functions available to rules, sorted by name.
+func (e *Engine) TemplateFuncs() []korrel8r.TemplateFunc {
+	funcs := maps.Values(e.funcs)
+	slices.SortFunc(funcs, func(a, b korrel8r.TemplateFunc) int { return strings.Compare(a.Name, b.Name) })
+	return funcs
+}
+
 // NewTemplate returns a template set up with options and funcs for this engine.
 // See package documentation for more.
 func (e *Engine) NewTemplate(name string) *template.Template {
@@ -156,9 +231,9 @@ func (e *Engine) NewTemplate(name string) *template.Template {
 	return template.New(name).Funcs(e.templateFuncs).Option("missingkey=error")
 }
 
-// execTemplate is a convenience to call NewTemplate, execute the template and stringify the result.
+// execTemplate is a convenience to call NewTemplate, execute a store configuration template and stringify the result.
 func (e *Engine) execTemplate(name, tmplString string, data any) (string, error) {
-	tmpl, err := e.NewTemplate(name).Parse(tmplString)
+	tmpl, err := e.NewTemplate(name).Funcs(template.FuncMap{"query": e.storeQuery}).Parse(tmplString)
 	if err != nil {
 		return "", err
 	}
@@ -168,3 +243,33 @@ func (e *Engine) execTemplate(name, tmplString string, data any) (string, error)
 	}
 	return w.String(), nil
 }
+
+// timeAdd implements the template function 'timeAdd'.
+func timeAdd(duration string, when any) (string, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return "", err
+	}
+	var t time.Time
+	switch w := when.(type) {
+	case nil: // Missing field.
+		return "", nil
+	case time.Time:
+		t = w
+	case *time.Time:
+		if w == nil {
+			return "", nil
+		}
+		t = *w
+	case string:
+		if w == "" {
+			return "", nil
+		}
+		if t, err = time.Parse(time.RFC3339Nano, w); err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("timeAdd: expected time, got %T", when)
+	}
+	return t.Add(d).Format(time.RFC3339Nano), nil
+}
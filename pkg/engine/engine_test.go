@@ -4,12 +4,18 @@ package engine_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
 	"github.com/korrel8r/korrel8r/pkg/graph"
@@ -159,3 +165,255 @@ type obj struct {
 	Name string
 	Time time.Time
 }
+
+type funcDomain struct {
+	mock.Domain
+	funcs []korrel8r.TemplateFunc
+}
+
+func (d *funcDomain) TemplateFuncs() []korrel8r.TemplateFunc { return d.funcs }
+
+func TestEngine_TemplateFuncs(t *testing.T) {
+	hello := func(s string) string { return "hello " + s }
+	d := &funcDomain{Domain: mock.Domain("a"), funcs: []korrel8r.TemplateFunc{{Name: "aHello", Func: hello, Description: "say hello"}}}
+	e, err := engine.Build().Domains(d).Engine()
+	require.NoError(t, err)
+	i := slices.IndexFunc(e.TemplateFuncs(), func(f korrel8r.TemplateFunc) bool { return f.Name == "aHello" })
+	require.GreaterOrEqual(t, i, 0)
+	f := e.TemplateFuncs()[i]
+	assert.Equal(t, "a", f.Source)
+	assert.Equal(t, "func(string) string", f.Signature())
+	tmpl, err := e.NewTemplate("x").Parse(`{{aHello "world"}}`)
+	require.NoError(t, err)
+	w := &strings.Builder{}
+	require.NoError(t, tmpl.Execute(w, nil))
+	assert.Equal(t, "hello world", w.String())
+
+	// Collisions between domains, or with engine and sprig functions.
+	for _, name := range []string{"aHello", "query", "upper"} {
+		d2 := &funcDomain{Domain: mock.Domain("b"), funcs: []korrel8r.TemplateFunc{{Name: name, Func: hello}}}
+		_, err = engine.Build().Domains(d, d2).Engine()
+		assert.ErrorContains(t, err, "template function "+name+" from b: already defined by ")
+	}
+}
+
+func TestEngine_Cost(t *testing.T) {
+	x, y := mock.Domain("x"), mock.Domain("y")
+	s := mock.NewStore(x)
+	s.AddQuery("x:c:1", 1)
+	e, err := engine.Build().Domains(x, y).Stores(s).Config(config.Configs{{
+		Rules: []config.Rule{
+			{
+				Name:   "ab",
+				Start:  config.ClassSpec{Domain: "x", Classes: []string{"a"}},
+				Goal:   config.ClassSpec{Domain: "y", Classes: []string{"b"}},
+				Result: config.ResultSpec{Query: "y:b:1"},
+				Cost:   2,
+			},
+			{
+				Name:   "ac",
+				Start:  config.ClassSpec{Domain: "x", Classes: []string{"a"}},
+				Goal:   config.ClassSpec{Domain: "x", Classes: []string{"c"}},
+				Result: config.ResultSpec{Query: "x:c:1"},
+			},
+		},
+		Tuning: &config.Tuning{DomainCosts: map[string]float64{"y": 3}, LearnCosts: true},
+	}}).Engine()
+	require.NoError(t, err)
+	costs := map[string]float64{}
+	e.Graph().EachLine(func(l *graph.Line) { costs[l.Rule.Name()] = e.Cost(l) })
+	assert.Equal(t, map[string]float64{"ab": 6, "ac": 1}, costs)
+
+	a, b, c := x.Class("a"), y.Class("b"), x.Class("c")
+	rules := func(g *graph.Graph) (names []string) {
+		g.EachLine(func(l *graph.Line) { names = append(names, l.Rule.Name()) })
+		slices.Sort(names)
+		return names
+	}
+	assert.Equal(t, []string{"ab", "ac"}, rules(e.Graph().CheapestPaths(a, list(b, c), e.Cost, 0)))
+	assert.Equal(t, []string{"ac"}, rules(e.Graph().CheapestPaths(a, list(b, c), e.Cost, 5)))
+
+	// Learn a cost from the store.
+	q, err := e.Query("x:c:1")
+	require.NoError(t, err)
+	require.NoError(t, e.Get(context.Background(), q, nil, graph.NewListResult()))
+	assert.Greater(t, e.DomainCost(x), 1.0)
+	assert.Equal(t, 3.0, e.DomainCost(y))
+}
+
+func TestEngine_RuleConstraint(t *testing.T) {
+	x := mock.Domain("x")
+	e, err := engine.Build().Domains(x).Config(config.Configs{{
+		Rules: []config.Rule{{
+			Name:  "ab",
+			Start: config.ClassSpec{Domain: "x", Classes: []string{"a"}},
+			Goal:  config.ClassSpec{Domain: "x", Classes: []string{"b"}},
+			Result: config.ResultSpec{
+				Query: "x:b:1",
+				Constraint: &config.ConstraintSpec{
+					Start: `{{timeAdd "-5m" .timestamp}}`,
+					End:   `{{timeAdd "5m" .timestamp}}`,
+				},
+			},
+		}},
+	}}).Engine()
+	require.NoError(t, err)
+	r, ok := e.Rule("ab").(korrel8r.Constrainer)
+	require.True(t, ok)
+
+	when := time.Date(2024, 1, 1, 10, 3, 0, 0, time.UTC)
+	start, end := when.Add(-time.Hour), when.Add(time.Hour)
+	c := &korrel8r.Constraint{Start: &start, End: &end}
+	got, err := r.Constrain(map[string]any{"timestamp": when.Format(time.RFC3339)}, c)
+	require.NoError(t, err)
+	assert.Equal(t, when.Add(-5*time.Minute), got.GetStart())
+	assert.Equal(t, when.Add(5*time.Minute), got.GetEnd())
+	assert.Equal(t, start, c.GetStart(), "original must not be modified")
+
+	// Blank timestamp leaves the constraint unchanged.
+	got, err = r.Constrain(map[string]any{"timestamp": ""}, c)
+	require.NoError(t, err)
+	assert.Equal(t, start, got.GetStart())
+	assert.Equal(t, end, got.GetEnd())
+}
+
+type allowAll struct{}
+
+func (allowAll) Authorize(_ context.Context, _ korrel8r.Query, c *korrel8r.Constraint) (*korrel8r.Constraint, error) {
+	return c, nil
+}
+
+func TestEngine_queryAuthorizer(t *testing.T) {
+	x, y := mock.Domain("x"), mock.Domain("y")
+	file := filepath.Join(t.TempDir(), "y.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`y:b:1: ["found"]`), 0o600))
+	xs := mock.NewStore(x)
+	xs.AddQuery("x:a:file", file)
+	configs := config.Configs{{
+		// Store configuration can use query.
+		Stores: []config.Store{{config.StoreKeyDomain: "y", config.StoreKeyMock: `{{query "x:a:file" | first}}`}},
+		Rules: []config.Rule{{
+			Name:   "ab",
+			Start:  config.ClassSpec{Domain: "x", Classes: []string{"a"}},
+			Goal:   config.ClassSpec{Domain: "y", Classes: []string{"b"}},
+			Result: config.ResultSpec{Query: `y:b:{{query "x:a:file" | len}}`},
+		}},
+	}}
+	for _, authorizer := range []engine.Authorizer{nil, allowAll{}} {
+		t.Run(fmt.Sprintf("%T", authorizer), func(t *testing.T) {
+			b := engine.Build().Domains(x, y).Stores(xs).Config(configs)
+			if authorizer != nil {
+				b.Authorizer(authorizer)
+			}
+			e, err := b.Engine()
+			require.NoError(t, err)
+			result := graph.NewListResult()
+			require.NoError(t, e.Get(context.Background(), mock.NewQuery(y.Class("b"), "1"), nil, result))
+			assert.Equal(t, []korrel8r.Object{"found"}, result.List())
+
+			q, err := e.Rule("ab").Apply("a")
+			if authorizer == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "y:b:1", q.String())
+			} else {
+				assert.True(t, korrel8r.IsDenied(err), "rule templates must not query with an authorizer: %v", err)
+			}
+		})
+	}
+}
+
+// timeClass is a mock class with int objects that are hour offsets from base.
+type timeClass struct {
+	mock.Class
+	base time.Time
+}
+
+func (c timeClass) Timestamp(o korrel8r.Object) (time.Time, time.Time) {
+	t := c.base.Add(time.Duration(o.(int)) * time.Hour)
+	return t, t
+}
+
+func TestEngine_Get_enforceTimestamp(t *testing.T) {
+	d := mock.Domain("mock")
+	base := time.Now().Add(-24 * time.Hour)
+	c := timeClass{Class: d.Class("a").(mock.Class), base: base}
+	e, err := engine.Build().Domains(d).Stores(mock.NewStore(d)).Engine()
+	require.NoError(t, err)
+	q := mock.NewQuery(c, "all", 0, 1, 2, 3, 4)
+	start, end := base.Add(time.Hour), base.Add(3*time.Hour)
+	result := graph.NewListResult()
+	require.NoError(t, e.Get(context.Background(), q, &korrel8r.Constraint{Start: &start, End: &end}, result))
+	assert.Equal(t, []korrel8r.Object{1, 2, 3}, result.List())
+}
+
+func TestEngine_RecordReplay(t *testing.T) {
+	d := mock.Domain("mock")
+	dir := t.TempDir()
+	s := mock.NewStore(d)
+	s.AddQuery("mock:a:x", []korrel8r.Object{"x1", "x2"})
+	s.AddQuery("mock:a:err", errors.New("oops"))
+	e, err := engine.Build().Domains(d).Stores(s).Record(dir).Engine()
+	require.NoError(t, err)
+	q, err := e.Query("mock:a:x")
+	require.NoError(t, err)
+	qErr, err := e.Query("mock:a:err")
+	require.NoError(t, err)
+	limit := 10
+	constraint := &korrel8r.Constraint{Limit: &limit}
+	require.NoError(t, e.Get(context.Background(), q, constraint, graph.NewListResult()))
+	require.NoError(t, e.Get(context.Background(), q, nil, graph.NewListResult())) // Not duplicated.
+	require.Error(t, e.Get(context.Background(), qErr, nil, graph.NewListResult()))
+
+	b, err := os.ReadFile(filepath.Join(dir, "mock.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "mock:a:err: []\nmock:a:x:\n- x1\n- x2\n", string(b))
+	b, err = os.ReadFile(filepath.Join(dir, "queries.ndjson"))
+	require.NoError(t, err)
+	var lines []engine.RecordedGet
+	for _, l := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var rg engine.RecordedGet
+		require.NoError(t, json.Unmarshal([]byte(l), &rg))
+		rg.Time, rg.Constraint = time.Time{}, nil
+		lines = append(lines, rg)
+	}
+	assert.Equal(t, []engine.RecordedGet{
+		{Domain: "mock", Query: "mock:a:x", Count: 2},
+		{Domain: "mock", Query: "mock:a:x", Count: 2},
+		{Domain: "mock", Query: "mock:a:err", Error: "oops"},
+	}, lines)
+
+	// Replay ignores configured stores.
+	other := mock.NewStore(d)
+	other.AddQuery("mock:a:x", []korrel8r.Object{"wrong"})
+	e, err = engine.Build().Domains(d).Stores(other).Replay(dir).Engine()
+	require.NoError(t, err)
+	result := graph.NewListResult()
+	require.NoError(t, e.Get(context.Background(), q, constraint, result))
+	assert.Equal(t, []korrel8r.Object{"x1", "x2"}, result.List())
+
+	_, err = engine.Build().Domains(d).Replay(t.TempDir()).Engine()
+	assert.ErrorContains(t, err, "no recorded domains")
+}
+
+func TestEngine_StoreRate(t *testing.T) {
+	d := mock.Domain("mock")
+	e, err := engine.Build().Domains(d).StoreConfigs(config.Store{
+		config.StoreKeyDomain: "mock",
+		config.StoreKeyMock:   "testdata/mock_store.yaml",
+		config.StoreKeyQPS:    "10",
+		config.StoreKeyBurst:  "2",
+	}).Engine()
+	require.NoError(t, err)
+	q, err := e.Query("mock:foo:hello")
+	require.NoError(t, err)
+	start := time.Now()
+	for range 4 { // Burst of 2, then 2 more at 10 QPS takes at least 200ms.
+		require.NoError(t, e.Get(context.Background(), q, nil, graph.NewResult(q.Class())))
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// Not enough time left before the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Error(t, e.Get(ctx, q, nil, graph.NewResult(q.Class())))
+}
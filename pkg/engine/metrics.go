@@ -0,0 +1,36 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package engine
+
+import (
+	"github.com/korrel8r/korrel8r/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Store metrics are labeled by domain name and store index, the position of the store in its domain.
+var (
+	storeQueryDuration = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "store",
+		Name:      "query_duration_seconds",
+		Help:      "Latency of store queries.",
+	}, []string{"domain", "store"})
+	storeQueryErrors = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "store",
+		Name:      "query_errors_total",
+		Help:      "Store queries that returned an error.",
+	}, []string{"domain", "store"})
+	storeObjects = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "store",
+		Name:      "objects_total",
+		Help:      "Objects returned by store queries.",
+	}, []string{"domain", "store"})
+	queriesDenied = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "engine",
+		Name:      "queries_denied_total",
+		Help:      "Queries denied by the engine authorizer.",
+	}, []string{"domain"})
+)
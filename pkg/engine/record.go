@@ -0,0 +1,132 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/unique"
+	"sigs.k8s.io/yaml"
+)
+
+// Files written by a recording.
+//
+// For each domain, DOMAIN.yaml maps query strings to the objects returned by stores.
+// This is the format loaded by mock stores, see [config.StoreKeyMock].
+//
+// The log file has a JSON line for each Get, with the constraint, result count and error.
+const (
+	recordExt = ".yaml"
+	recordLog = "queries.ndjson"
+)
+
+// RecordedGet is a line in the recording log.
+type RecordedGet struct {
+	Time       time.Time            `json:"time"`
+	Domain     string               `json:"domain"`
+	Query      string               `json:"query"`
+	Constraint *korrel8r.Constraint `json:"constraint,omitempty"`
+	Count      int                  `json:"count"`
+	Error      string               `json:"error,omitempty"`
+}
+
+// recorder records store results in a directory.
+type recorder struct {
+	dir     string
+	lock    sync.Mutex
+	domains map[string]map[string]*recordedResult // Results by domain and query string.
+}
+
+// recordedResult is a list of JSON objects without duplicates.
+type recordedResult struct {
+	seen    unique.Set[string]
+	objects []json.RawMessage
+}
+
+func newRecorder(dir string) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recorder{dir: dir, domains: map[string]map[string]*recordedResult{}}, nil
+}
+
+// record the objects returned by stores for a query, and write the recording files.
+func (r *recorder) record(q korrel8r.Query, constraint *korrel8r.Constraint, objects []korrel8r.Object, getErr error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	domain := q.Class().Domain().Name()
+	queries := r.domains[domain]
+	if queries == nil {
+		queries = map[string]*recordedResult{}
+		r.domains[domain] = queries
+	}
+	result := queries[q.String()]
+	if result == nil {
+		result = &recordedResult{seen: unique.Set[string]{}, objects: []json.RawMessage{}}
+		queries[q.String()] = result
+	}
+	for _, o := range objects {
+		b, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		if !result.seen.Has(string(b)) {
+			result.seen.Add(string(b))
+			result.objects = append(result.objects, b)
+		}
+	}
+	if err := r.writeDomain(domain); err != nil {
+		return err
+	}
+	line := RecordedGet{Time: time.Now(), Domain: domain, Query: q.String(), Constraint: constraint, Count: len(objects)}
+	if getErr != nil {
+		line.Error = getErr.Error()
+	}
+	return r.writeLog(line)
+}
+
+func (r *recorder) writeDomain(domain string) error {
+	data := map[string][]json.RawMessage{}
+	for q, result := range r.domains[domain] {
+		data[q] = result.objects
+	}
+	b, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, domain+recordExt), b, 0o644)
+}
+
+func (r *recorder) writeLog(line RecordedGet) error {
+	f, err := os.OpenFile(filepath.Join(r.dir, recordLog), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(line)
+}
+
+// replayStores returns mock store configurations for each domain recorded in dir.
+func replayStores(dir string) ([]config.Store, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+recordExt))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded domains in %v", dir)
+	}
+	var stores []config.Store
+	for _, f := range files {
+		domain := strings.TrimSuffix(filepath.Base(f), recordExt)
+		stores = append(stores, config.Store{config.StoreKeyDomain: domain, config.StoreKeyMock: f})
+	}
+	return stores, nil
+}
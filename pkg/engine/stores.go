@@ -12,10 +12,12 @@ import (
 	"slices"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
 	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"golang.org/x/time/rate"
 )
 
 var (
@@ -33,8 +35,10 @@ type store struct {
 	Err      error          // Last non-nil error from Store.Get() or Domain.Store()
 	ErrCount int            // Count of errors from Store.Get() and Domain.Store()
 
-	domain korrel8r.Domain
-	expand func(string) (string, error) // Expand template configuration
+	domain  korrel8r.Domain
+	index   string                       // Position in the domain stores, used as a metric label.
+	expand  func(string) (string, error) // Expand template configuration
+	limiter *rate.Limiter                // Query rate budget, nil if unlimited.
 }
 
 func (s *store) Domain() korrel8r.Domain { return s.domain }
@@ -46,8 +50,17 @@ func (s *store) Get(ctx context.Context, q korrel8r.Query, constraint *korrel8r.
 	if _, err := s.ensure(); err != nil {
 		return err
 	}
-	err = s.Store.Get(ctx, q, constraint, result)
+	if s.limiter != nil {
+		if err := s.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	start, count := time.Now(), 0
+	err = s.Store.Get(ctx, q, constraint, korrel8r.AppenderFunc(func(o korrel8r.Object) { result.Append(o); count++ }))
+	storeQueryDuration.WithLabelValues(s.domain.Name(), s.index).Observe(time.Since(start).Seconds())
+	storeObjects.WithLabelValues(s.domain.Name(), s.index).Add(float64(count))
 	if err != nil {
+		storeQueryErrors.WithLabelValues(s.domain.Name(), s.index).Inc()
 		s.Err = err
 		s.ErrCount++
 		if s.Original != nil { // Only re-create if there is some configuration.
@@ -90,6 +103,9 @@ func (s *store) ensure() (korrel8r.Store, error) {
 		}
 		s.Expanded[k] = v
 	}
+	if err = s.setRate(); err != nil {
+		return nil, err
+	}
 	// Create the store
 	if _, ok := s.Expanded[config.StoreKeyMock]; ok {
 		// Special case for mock store, any domain can have a mock store.
@@ -103,6 +119,22 @@ func (s *store) ensure() (korrel8r.Store, error) {
 	return s.Store, err
 }
 
+// setRate updates the query rate budget from the expanded configuration.
+// An existing limiter is updated, not replaced, so re-creating a store does not reset its budget.
+func (s *store) setRate() error {
+	r, err := s.Expanded.Rate()
+	switch {
+	case err != nil || r == nil:
+		s.limiter = nil
+	case s.limiter == nil:
+		s.limiter = rate.NewLimiter(rate.Limit(r.QPS), r.GetBurst())
+	default:
+		s.limiter.SetLimit(rate.Limit(r.QPS))
+		s.limiter.SetBurst(r.GetBurst())
+	}
+	return err
+}
+
 // stores contains multiple configured stores and iterates over them in Get.
 type stores struct {
 	domain korrel8r.Domain
@@ -124,6 +156,7 @@ func (ss *stores) Domain() korrel8r.Domain { return ss.domain }
 
 func (ss *stores) Add(newStore *store) error {
 	newStore.expand = ss.expand
+	newStore.index = strconv.Itoa(len(ss.stores))
 	// Check for duplicate configuration
 	if newStore.Original != nil && slices.ContainsFunc(ss.stores,
 		func(s *store) bool { return reflect.DeepEqual(s.Original, newStore.Original) }) {
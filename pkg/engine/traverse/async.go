@@ -7,6 +7,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
@@ -27,15 +28,21 @@ func NewAsync(e *engine.Engine, g *graph.Graph) Traverser {
 
 // Goals runs a goal-directed search.
 // Results and Queries are filled in on graph.
-func (a *async) Goals(ctx context.Context, start Start, goals []korrel8r.Class) (*graph.Graph, error) {
+func (a *async) Goals(ctx context.Context, start Start, goals []korrel8r.Class) (g *graph.Graph, err error) {
+	ctx, end := instrument(ctx, searchGoals, start, tracing.Goals.StringSlice(classNames(goals)))
+	defer func() { end(g, err) }()
 	log.V(2).Info("Async: Goal search", "start", start, "goals", goals)
-	traverse := func(v graph.Visitor) { a.graph.GoalSearch(start.Class, goals, v) }
+	traverse := func(v graph.Visitor) {
+		a.graph.CheapestGoalSearch(start.Class, goals, a.engine.Cost, MaxCostFrom(ctx), v)
+	}
 	return a.run(ctx, start, traverse)
 }
 
 // Goals runs a neighbourhood.
 // Results and Queries are filled in on graph.
-func (a *async) Neighbours(ctx context.Context, start Start, depth int) (*graph.Graph, error) {
+func (a *async) Neighbours(ctx context.Context, start Start, depth int) (g *graph.Graph, err error) {
+	ctx, end := instrument(ctx, searchNeighbours, start, tracing.Depth.Int(depth))
+	defer func() { end(g, err) }()
 	log.V(2).Info("Async: Neighbours search", "start", start, "depth", depth)
 	traverse := func(v graph.Visitor) { a.graph.Neighbours(start.Class, depth, v) }
 	return a.run(ctx, start, traverse)
@@ -83,7 +90,7 @@ func (a *async) run(ctx context.Context, start Start, traverse func(v graph.Visi
 	startNode.Sending()     // Notify the start node that we are sending.
 	defer startNode.Close() // Notify the start node we are done.
 	for _, q := range start.Queries {
-		startNode.queryChan <- lineQuery{Query: q}
+		startNode.queryChan <- lineQuery{goalQuery: goalQuery{Query: q}}
 	}
 
 	// will return when all goroutines have called busy.Done.
@@ -99,6 +106,7 @@ func (a *async) ensureNode(g *graph.Graph, n *graph.Node) *node {
 			g:          g,
 			queryChan:  make(chan lineQuery, 1),
 			queriesOut: unique.Set[string]{},
+			done:       unique.Set[string]{},
 			errs:       a.errs,
 		}
 		g.MergeNode(n)
@@ -115,13 +123,14 @@ type node struct {
 	queryChan  chan lineQuery     // Incoming queries.
 	senders    atomic.Int64       // Count of senders to queryChan.
 	queriesOut unique.Set[string] // Deduplicate outgoing queries.
+	done       unique.Set[string] // Incoming queries already processed.
 	errs       *Errors
 }
 
 // lineQuery is a query and the line it arrived on.
 type lineQuery struct {
-	Line  *graph.Line
-	Query korrel8r.Query
+	goalQuery
+	Line *graph.Line
 }
 
 // getNode gets the async.node attached to a graph.Node
@@ -162,23 +171,30 @@ func (n *node) Run(ctx context.Context) {
 		}
 
 		l, q := lq.Line, lq.Query
-		if n.Queries.Has(q) {
+		k := lq.key()
+		if n.done.Has(k) {
 			continue // Already processed this query.
 		}
+		n.done.Add(k)
 		before := len(n.Result.List())
-		err := n.engine.Get(ctx, q, korrel8r.ConstraintFrom(ctx), n.Result)
+		qctx, span := startQuery(ctx, q, l)
+		err := n.engine.Get(qctx, q, lq.constraint(ctx), n.Result)
+		span.SetAttributes(tracing.Count.Int(len(n.Result.List()) - before))
+		tracing.End(span, err)
 		if n.errs.Add(err) { // Report each new error once at V(1)
 			log.V(1).Info("Async: Get failed", "error", err, "query", q)
 		} else if err != nil { // Report all errors at V(3)
 			log.V(3).Info("Async: Get failed", "error", err, "query", q)
 		}
 		result := n.Result.List()[before:]
+		ExplanationFrom(ctx).get(q, len(result), err)
 		for _, o := range result {
 			n.applyRules(ctx, o)
 		}
-		n.Queries.Set(q, len(result))
+		// The same query may arrive again with a different constraint, accumulate counts.
+		n.Queries.Set(q, max(n.Queries.Get(q), 0)+len(result))
 		if l != nil { // Initial queries don't have a line
-			l.Queries.Set(q, len(result))
+			l.Queries.Set(q, max(l.Queries.Get(q), 0)+len(result))
 		}
 	}
 }
@@ -191,30 +207,31 @@ func (n *node) applyRules(ctx context.Context, o korrel8r.Object) {
 	//
 	// SO: remember rules applied on the wrong line, send them on the correct line.
 	applied := map[korrel8r.Rule]struct {
-		q   korrel8r.Query
+		gq  goalQuery
 		err error
 	}{}
 	n.g.EachLineFrom(n.Node, func(l *graph.Line) {
 		qe, ok := applied[l.Rule] // Already applied?
-		if !ok {                  // No, apply now
-			qe.q, qe.err = l.Rule.Apply(o)
-		}
-		if qe.q != nil && qe.q.Class() != l.Goal().Class { // Wrong line, save for later
+		if !ok {                  // No, apply now and remember the result for other lines.
+			qe.gq, qe.err = applyRule(ctx, l.Rule, n.Class, o)
 			applied[l.Rule] = qe
+		}
+		q := qe.gq.Query
+		if q != nil && q.Class() != l.Goal().Class { // Wrong line, send on the correct line.
 			return
 		}
-		if qe.q != nil { // De-duplicate query
-			qs := qe.q.String()
-			if n.queriesOut.Has(qs) {
+		if q != nil { // De-duplicate query
+			k := qe.gq.key()
+			if n.queriesOut.Has(k) {
 				return // This query has been sent before.
 			}
-			n.queriesOut.Add(qs)
+			n.queriesOut.Add(k)
 		}
-		if qe.err != nil || qe.q == nil {
+		if qe.err != nil || q == nil {
 			log.V(4).Info("Async: Cannot apply", "rule", l.Rule.Name(), "error", qe.err)
 		} else {
-			log.V(4).Info("Async: Applied", "rule", l.Rule.Name(), "query", qe.q)
-			getNode(l.Goal()).queryChan <- lineQuery{Query: qe.q, Line: l}
+			log.V(4).Info("Async: Applied", "rule", l.Rule.Name(), "query", q)
+			getNode(l.Goal()).queryChan <- lineQuery{goalQuery: qe.gq, Line: l}
 		}
 	})
 }
@@ -6,15 +6,17 @@ import (
 	"errors"
 	"sync"
 
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/unique"
 )
 
 // Errors is a goroutine-safe collection of errors.
 type Errors struct {
-	m    sync.Mutex
-	err  error
-	ok   int
-	seen unique.Set[string]
+	m      sync.Mutex
+	err    error
+	ok     int
+	denied bool // Some queries were denied, see [korrel8r.DeniedError].
+	seen   unique.Set[string]
 }
 
 func NewErrors() *Errors {
@@ -26,7 +28,10 @@ func (e *Errors) Add(err error) bool {
 	defer e.m.Unlock()
 	if err == nil {
 		e.ok++
-	} else if !e.seen.Has(err.Error()) {
+		return false
+	}
+	e.denied = e.denied || korrel8r.IsDenied(err)
+	if !e.seen.Has(err.Error()) {
 		e.err = errors.Join(e.err, err)
 		e.seen.Add(err.Error())
 		return true
@@ -35,12 +40,13 @@ func (e *Errors) Add(err error) bool {
 }
 
 // Err returns the resulting error which may be a PartialError.
+// Denied queries always give a PartialError, the results the caller is allowed to see are complete.
 // Must not be called concurrently.
 func (e *Errors) Err() error {
 	switch {
 	case e.err == nil:
 		return nil
-	case e.ok > 0:
+	case e.ok > 0 || e.denied:
 		return &PartialError{e.err}
 	default:
 		return e.err
@@ -0,0 +1,144 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package traverse
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
+
+// Outcome of applying a rule to a start object.
+type Outcome string
+
+const (
+	Applied       Outcome = "applied"       // Rule generated a query.
+	NotApplicable Outcome = "notApplicable" // Rule does not apply to the start object.
+	Failed        Outcome = "error"         // Rule template or query failed.
+)
+
+// Step records a rule applied to one start object, and the result of getting the query.
+type Step struct {
+	Rule    korrel8r.Rule
+	Start   korrel8r.Class
+	Object  string // ID or preview of the start object, may be empty.
+	Outcome Outcome
+	Query   korrel8r.Query // Query generated by the rule, nil unless Outcome is Applied.
+	Count   int            // Count of results for Query, -1 if the query was not executed.
+	Err     error          // Error applying the rule or getting the query.
+}
+
+// Goal returns the goal class of the step, nil if no query was generated.
+func (s *Step) Goal() korrel8r.Class {
+	if s.Query == nil {
+		return nil
+	}
+	return s.Query.Class()
+}
+
+// Explanation records each step of a traversal, to explain why goals were or were not reached.
+// It is safe for concurrent use. Methods on a nil *Explanation do nothing.
+type Explanation struct {
+	m     sync.Mutex
+	steps []Step
+	gets  map[string]queryResult
+}
+
+type queryResult struct {
+	count int
+	err   error
+}
+
+func NewExplanation() *Explanation { return &Explanation{gets: map[string]queryResult{}} }
+
+type explanationKey struct{}
+
+// WithExplanation returns a context that causes a traversal to record steps in x.
+func WithExplanation(ctx context.Context, x *Explanation) context.Context {
+	return context.WithValue(ctx, explanationKey{}, x)
+}
+
+// ExplanationFrom returns the explanation from a context, or nil.
+func ExplanationFrom(ctx context.Context) *Explanation {
+	x, _ := ctx.Value(explanationKey{}).(*Explanation)
+	return x
+}
+
+// Steps returns the steps recorded so far, sorted by start class, rule, object and query.
+func (x *Explanation) Steps() []Step {
+	if x == nil {
+		return nil
+	}
+	x.m.Lock()
+	defer x.m.Unlock()
+	steps := slices.Clone(x.steps)
+	for i := range steps {
+		s := &steps[i]
+		if s.Query == nil {
+			continue
+		}
+		if r, ok := x.gets[s.Query.String()]; ok {
+			s.Count, s.Err = r.count, r.err
+		}
+	}
+	slices.SortStableFunc(steps, func(a, b Step) int {
+		return cmp.Or(
+			cmp.Compare(a.Start.String(), b.Start.String()),
+			cmp.Compare(a.Rule.Name(), b.Rule.Name()),
+			cmp.Compare(a.Object, b.Object),
+			cmp.Compare(queryString(a.Query), queryString(b.Query)))
+	})
+	return steps
+}
+
+// apply records the result of applying rule to object.
+func (x *Explanation) apply(rule korrel8r.Rule, start korrel8r.Class, object korrel8r.Object, q korrel8r.Query, err error) {
+	if x == nil {
+		return
+	}
+	s := Step{Rule: rule, Start: start, Object: objectName(start, object), Query: q, Count: -1, Err: err}
+	switch {
+	case q != nil && err == nil:
+		s.Outcome = Applied
+	case errors.Is(err, korrel8r.ErrNotApplicable):
+		s.Outcome = NotApplicable
+	default:
+		s.Outcome = Failed
+	}
+	x.m.Lock()
+	defer x.m.Unlock()
+	x.steps = append(x.steps, s)
+}
+
+// get records the result of getting a query.
+func (x *Explanation) get(q korrel8r.Query, count int, err error) {
+	if x == nil {
+		return
+	}
+	x.m.Lock()
+	defer x.m.Unlock()
+	if _, ok := x.gets[q.String()]; !ok {
+		x.gets[q.String()] = queryResult{count: count, err: err}
+	}
+}
+
+func objectName(class korrel8r.Class, o korrel8r.Object) string {
+	if id := korrel8r.GetID(class, o); id != "" {
+		return id
+	}
+	if p, ok := class.(korrel8r.Previewer); ok {
+		return p.Preview(o)
+	}
+	return ""
+}
+
+func queryString(q korrel8r.Query) string {
+	if q == nil {
+		return ""
+	}
+	return q.String()
+}
@@ -0,0 +1,92 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package traverse
+
+import (
+	"github.com/korrel8r/korrel8r/internal/pkg/metrics"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Values of the "search" metric label.
+const (
+	searchGoals      = "goals"
+	searchNeighbours = "neighbours"
+)
+
+// Values of the "outcome" metric label for rule applications.
+const (
+	ruleQuery = "query" // The rule returned a query.
+	ruleNone  = "none"  // The rule does not apply to the start object.
+	ruleError = "error" // The rule failed.
+)
+
+var (
+	traversals = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "traverse",
+		Name:      "searches_total",
+		Help:      "Graph traversals by type of search.",
+	}, []string{"search"})
+	traversalPartial = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "traverse",
+		Name:      "partial_results_total",
+		Help:      "Graph traversals that returned a partial result.",
+	}, []string{"search"})
+	traversalErrors = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "traverse",
+		Name:      "errors_total",
+		Help:      "Graph traversals that failed with no result.",
+	}, []string{"search"})
+	traversalNodes = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "traverse",
+		Name:      "nodes",
+		Help:      "Number of classes with results in a traversal result graph.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+	}, []string{"search"})
+	traversalObjects = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "traverse",
+		Name:      "objects",
+		Help:      "Number of objects in a traversal result graph.",
+		Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"search"})
+	ruleApplications = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "traverse",
+		Name:      "rule_applications_total",
+		Help:      "Rule applications by rule name and outcome: query, none or error.",
+	}, []string{"rule", "outcome"})
+)
+
+// observe records metrics for a completed traversal.
+func observe(search string, g *graph.Graph, err error) {
+	traversals.WithLabelValues(search).Inc()
+	switch {
+	case IsPartial(err):
+		traversalPartial.WithLabelValues(search).Inc()
+	case err != nil:
+		traversalErrors.WithLabelValues(search).Inc()
+		return
+	}
+	if g == nil {
+		return
+	}
+	nodes, objects := size(g)
+	traversalNodes.WithLabelValues(search).Observe(float64(nodes))
+	traversalObjects.WithLabelValues(search).Observe(float64(objects))
+}
+
+// size returns the number of nodes with results and the total number of objects in g.
+func size(g *graph.Graph) (nodes, objects int) {
+	g.EachNode(func(n *graph.Node) {
+		if n := len(n.Result.List()); n > 0 {
+			nodes++
+			objects += n
+		}
+	})
+	return nodes, objects
+}
@@ -0,0 +1,137 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package traverse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/korrel8r/korrel8r/pkg/engine"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
+
+// Search runs a traversal from a single start, see [GoalSearch] and [NeighbourSearch].
+type Search func(ctx context.Context, t Traverser, start Start) (*graph.Graph, error)
+
+// GoalSearch returns a Search that calls [Traverser.Goals].
+func GoalSearch(goals []korrel8r.Class) Search {
+	return func(ctx context.Context, t Traverser, start Start) (*graph.Graph, error) {
+		return t.Goals(ctx, start, goals)
+	}
+}
+
+// NeighbourSearch returns a Search that calls [Traverser.Neighbours].
+func NeighbourSearch(depth int) Search {
+	return func(ctx context.Context, t Traverser, start Start) (*graph.Graph, error) {
+		return t.Neighbours(ctx, start, depth)
+	}
+}
+
+// MultiResult is the result of searching from multiple starts.
+type MultiResult struct {
+	// Graphs are the result graphs for each start, in the same order as the starts.
+	Graphs []*graph.Graph
+	// Intersections are classes reached from more than one start, in class name order.
+	Intersections []Intersection
+}
+
+// Intersection is a class with results from more than one start.
+type Intersection struct {
+	Class korrel8r.Class
+	// Starts are the indices of the starts that reached Class.
+	Starts []int
+	// Objects are the objects of Class reached from more than one start.
+	// Objects are compared by ID if Class is a [korrel8r.IDer], by JSON serialization otherwise.
+	// May be empty if each start reached different objects.
+	Objects []korrel8r.Object
+}
+
+// MultiStart runs search from each of the starts concurrently, and finds the intersections of the results.
+// Each search uses a new Traverser (see [New]) on a new graph of the engine rules.
+//
+// Returns a [PartialError] if some searches failed and others succeeded.
+func MultiStart(ctx context.Context, e *engine.Engine, starts []Start, search Search) (*MultiResult, error) {
+	if len(starts) == 0 {
+		return nil, fmt.Errorf("no start for search")
+	}
+	r := &MultiResult{Graphs: make([]*graph.Graph, len(starts))}
+	errs := NewErrors()
+	var busy sync.WaitGroup
+	for i, start := range starts {
+		busy.Add(1)
+		go func() {
+			defer busy.Done()
+			g, err := search(ctx, New(e, e.Graph()), start)
+			r.Graphs[i] = g
+			errs.Add(err)
+		}()
+	}
+	busy.Wait()
+	r.Intersections = intersect(r.Graphs)
+	return r, errs.Err()
+}
+
+// intersect finds classes with results in more than one graph, and objects common to more than one graph.
+func intersect(graphs []*graph.Graph) []Intersection {
+	type reached struct {
+		class   korrel8r.Class
+		starts  []int
+		objects map[string][]int // Start indices by object key.
+		first   map[string]korrel8r.Object
+		order   []string // Object keys in order of discovery.
+	}
+	byClass := map[string]*reached{}
+	for i, g := range graphs {
+		if g == nil {
+			continue
+		}
+		g.EachNode(func(n *graph.Node) {
+			if n.Empty() {
+				return
+			}
+			r := byClass[n.Class.String()]
+			if r == nil {
+				r = &reached{class: n.Class, objects: map[string][]int{}, first: map[string]korrel8r.Object{}}
+				byClass[n.Class.String()] = r
+			}
+			r.starts = append(r.starts, i)
+			for _, o := range n.Result.List() {
+				k := objectKey(n.Class, o)
+				if _, ok := r.first[k]; !ok {
+					r.first[k], r.order = o, append(r.order, k)
+				}
+				if s := r.objects[k]; len(s) == 0 || s[len(s)-1] != i { // Count each start once.
+					r.objects[k] = append(s, i)
+				}
+			}
+		})
+	}
+	var result []Intersection
+	for _, r := range byClass {
+		if len(r.starts) < 2 {
+			continue
+		}
+		x := Intersection{Class: r.class, Starts: r.starts}
+		for _, k := range r.order {
+			if len(r.objects[k]) > 1 {
+				x.Objects = append(x.Objects, r.first[k])
+			}
+		}
+		result = append(result, x)
+	}
+	slices.SortFunc(result, func(a, b Intersection) int { return strings.Compare(a.Class.String(), b.Class.String()) })
+	return result
+}
+
+func objectKey(class korrel8r.Class, o korrel8r.Object) string {
+	if id := korrel8r.GetID(class, o); id != "" {
+		return id
+	}
+	b, _ := json.Marshal(o)
+	return string(b)
+}
@@ -4,12 +4,15 @@ package traverse
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/unique"
 )
 
 type appliedRule struct {
@@ -24,33 +27,49 @@ type seq struct {
 
 	ctx      context.Context
 	subGraph *graph.Graph
-	// temporary store for results of rules that need to be saved for a later line.
-	rules map[appliedRule]graph.Queries
+	// temporary store for results of rules that need to be saved for a later line, by goalQuery key.
+	rules map[appliedRule]map[string]goalQuery
+	// keys of goal queries that have been evaluated.
+	done unique.Set[string]
+	// denied queries, reported as a partial result.
+	denied error
 }
 
 // NewSync returns a synchronous Traverser that evaluates queries sequentially.
 func NewSync(e *engine.Engine, g *graph.Graph) Traverser {
-	return &seq{Engine: e, Graph: g, subGraph: g.Data.EmptyGraph(), rules: map[appliedRule]graph.Queries{}}
+	return &seq{Engine: e, Graph: g, subGraph: g.Data.EmptyGraph(), rules: map[appliedRule]map[string]goalQuery{}, done: unique.Set[string]{}}
 }
 
-func (t *seq) Goals(ctx context.Context, start Start, goals []korrel8r.Class) (*graph.Graph, error) {
+func (t *seq) Goals(ctx context.Context, start Start, goals []korrel8r.Class) (g *graph.Graph, err error) {
+	ctx, end := instrument(ctx, searchGoals, start, tracing.Goals.StringSlice(classNames(goals)))
+	defer func() { end(g, err) }()
 	t.ctx = ctx
 	log.V(2).Info("Sync: goal search", "start", start.Class, "goals", goals)
 	if err := t.startNode(t.Graph.NodeFor(start.Class), start.Objects, start.Queries); err != nil {
 		return nil, err
 	}
-	t.Graph.GoalSearch(start.Class, goals, t)
-	return t.subGraph, nil
+	t.Graph.CheapestGoalSearch(start.Class, goals, t.Engine.Cost, MaxCostFrom(ctx), t)
+	return t.subGraph, t.err()
 }
 
-func (t *seq) Neighbours(ctx context.Context, start Start, depth int) (*graph.Graph, error) {
+func (t *seq) Neighbours(ctx context.Context, start Start, depth int) (g *graph.Graph, err error) {
+	ctx, end := instrument(ctx, searchNeighbours, start, tracing.Depth.Int(depth))
+	defer func() { end(g, err) }()
 	t.ctx = ctx
 	log.V(2).Info("Sync: neighbours search", "start", start, "depth", depth)
 	if err := t.startNode(t.Graph.NodeFor(start.Class), start.Objects, start.Queries); err != nil {
 		return nil, err
 	}
 	t.Graph.Neighbours(start.Class, depth, t)
-	return t.subGraph, nil
+	return t.subGraph, t.err()
+}
+
+// err returns a partial error if some queries were denied.
+func (t *seq) err() error {
+	if t.denied != nil {
+		return &PartialError{Err: t.denied}
+	}
+	return nil
 }
 
 // Line a line gets all queries provided by Visit() on the From node,
@@ -60,29 +79,29 @@ func (t *seq) Line(l *graph.Line) bool {
 	// Apply rule to each start object unless it was already applied to this start class.
 	key := appliedRule{Start: start.Class, Rule: l.Rule}
 	if _, applied := t.rules[key]; !applied { // Not yet applied.
-		t.rules[key] = graph.Queries{}
+		t.rules[key] = map[string]goalQuery{}
 		for _, s := range start.Result.List() {
-			q, err := l.Rule.Apply(s)
-			if q == nil { // Rule does  not apply
+			gq, err := applyRule(t.ctx, l.Rule, start.Class, s)
+			if gq.Query == nil { // Rule does  not apply
 				log.V(4).Info("Sync: Rule failed", "rule", l.Rule.Name(), "error", err, "id", korrel8r.GetID(start.Class, s))
 			} else {
-				t.rules[key].Set(q, -1)
-				log.V(4).Info("Sync: Rule applied", "rule", l.Rule.Name(), "query", q, "id", korrel8r.GetID(start.Class, s))
+				t.rules[key][gq.key()] = gq
+				log.V(4).Info("Sync: Rule applied", "rule", l.Rule.Name(), "query", gq.Query, "id", korrel8r.GetID(start.Class, s))
 			}
 		}
 	}
 
 	// Process and remove queries that match this line's goal, leave the rest.
-	maps.DeleteFunc(t.rules[key], func(s string, qc graph.QueryCount) bool {
-		q := qc.Query
+	maps.DeleteFunc(t.rules[key], func(k string, gq goalQuery) bool {
+		q := gq.Query
 		switch {
 		case q.Class() != goal.Class: // Wrong goal, leave it for another line.
 			return false
-		case goal.Queries.Has(q): // Already evaluated on goal node.
-			l.Queries.Set(q, qc.Count) // Record on the count
+		case t.done.Has(k): // Already evaluated on goal node.
+			l.Queries.Set(q, goal.Queries.Get(q)) // Record on the count
 			return true
 		default: // Evaluate the query and store the results
-			count, _ := t.getQuery(t.ctx, goal, q)
+			count, _ := t.getQuery(t.ctx, goal, gq, l)
 			l.Queries.Set(q, count)
 			return true
 		}
@@ -104,17 +123,28 @@ func (t *seq) startNode(start *graph.Node, objects []korrel8r.Object, queries []
 		if query.Class() != start.Class {
 			return fmt.Errorf("class mismatch in query %v: expected class %v", query, start)
 		}
-		if _, err := t.getQuery(t.ctx, start, query); err != nil {
+		if _, err := t.getQuery(t.ctx, start, goalQuery{Query: query}, nil); err != nil && !korrel8r.IsDenied(err) {
 			return err
 		}
 	}
 	return nil
 }
 
-func (t *seq) getQuery(ctx context.Context, goal *graph.Node, q korrel8r.Query) (int, error) {
-	count := 0
-	result := korrel8r.AppenderFunc(func(o korrel8r.Object) { goal.Result.Append(o); count++ })
-	err := t.Engine.Get(ctx, q, korrel8r.ConstraintFrom(t.ctx), result)
+// getQuery gets a goal query, the count is accumulated if the same query was evaluated with a different constraint.
+// l is the line the query arrived on, nil for start queries.
+func (t *seq) getQuery(ctx context.Context, goal *graph.Node, gq goalQuery, l *graph.Line) (int, error) {
+	q, count := gq.Query, max(goal.Queries.Get(gq.Query), 0)
+	n := 0
+	result := korrel8r.AppenderFunc(func(o korrel8r.Object) { goal.Result.Append(o); count++; n++ })
+	t.done.Add(gq.key())
+	ctx, span := startQuery(ctx, q, l)
+	err := t.Engine.Get(ctx, q, gq.constraint(ctx), result)
+	span.SetAttributes(tracing.Count.Int(n))
+	tracing.End(span, err)
 	goal.Queries.Set(q, count)
+	ExplanationFrom(ctx).get(q, count, err)
+	if korrel8r.IsDenied(err) {
+		t.denied = errors.Join(t.denied, err)
+	}
 	return count, err
 }
@@ -0,0 +1,45 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package traverse
+
+import (
+	"context"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrument starts a span for a search.
+// Returns a context for the search and a function to end the span and record metrics.
+func instrument(ctx context.Context, search string, start Start, attrs ...attribute.KeyValue) (context.Context, func(*graph.Graph, error)) {
+	attrs = append(attrs, tracing.Class.String(start.Class.String()))
+	ctx, span := tracing.Tracer().Start(ctx, "korrel8r.traverse."+search, trace.WithAttributes(attrs...))
+	return ctx, func(g *graph.Graph, err error) {
+		observe(search, g, err)
+		if g != nil {
+			nodes, objects := size(g)
+			span.SetAttributes(tracing.Classes.Int(nodes), tracing.Count.Int(objects))
+		}
+		tracing.End(span, err)
+	}
+}
+
+// startQuery starts a span for a query evaluated on a graph node, l is the line it arrived on or nil.
+func startQuery(ctx context.Context, q korrel8r.Query, l *graph.Line) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{tracing.Class.String(q.Class().String()), tracing.Query.String(q.String())}
+	if l != nil {
+		attrs = append(attrs, tracing.Rule.String(l.Rule.Name()))
+	}
+	return tracing.Tracer().Start(ctx, "korrel8r.traverse.query", trace.WithAttributes(attrs...))
+}
+
+func classNames(classes []korrel8r.Class) []string {
+	names := make([]string, len(classes))
+	for i, c := range classes {
+		names[i] = c.String()
+	}
+	return names
+}
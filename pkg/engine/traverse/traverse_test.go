@@ -5,13 +5,17 @@ package traverse
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/test"
 	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/graph"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/ptr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -110,16 +114,169 @@ func TestPartialError(t *testing.T) {
 	).Stores(s).Engine()
 	require.NoError(t, err)
 	start := Start{Class: ca, Objects: []korrel8r.Object{0}}
+	partial := testutil.ToFloat64(traversalPartial.WithLabelValues(searchNeighbours))
+	applied := testutil.ToFloat64(ruleApplications.WithLabelValues("ab", ruleQuery))
 	g, err := New(e, e.Graph()).Neighbours(context.Background(), start, 3)
 	var pe *PartialError
 	assert.ErrorContains(t, err, "no good")
 	assert.ErrorAs(t, err, &pe)
+	assert.Equal(t, partial+1, testutil.ToFloat64(traversalPartial.WithLabelValues(searchNeighbours)))
+	assert.Equal(t, applied+1, testutil.ToFloat64(ruleApplications.WithLabelValues("ab", ruleQuery)))
 	assert.ElementsMatch(t, g.NodesFor(ca, cb, cc), graph.NodesOf(g.Nodes()))
 	assert.Equal(t, []any{0}, g.NodeFor(ca).Result.List())
 	assert.Equal(t, []any{1, 2}, g.NodeFor(cb).Result.List())
 	assert.Empty(t, g.NodeFor(cc).Result.List())
 }
 
+func TestTraverserExplain(t *testing.T) {
+	d := mock.Domain("mock")
+	s := mock.NewStore(d)
+	c := d.Class
+	ca, cb, cc, cd := c("a"), c("b"), c("c"), c("d")
+	e, err := engine.Build().Rules(
+		r("ab", ca, cb, mock.NewQuery(cb, "1,2", 1, 2)),
+		r("ac", ca, cc, func(korrel8r.Object) (korrel8r.Query, error) { return nil, korrel8r.ErrNotApplicable }),
+		r("ad", ca, cd, func(korrel8r.Object) (korrel8r.Query, error) { return nil, errors.New("bad template") }),
+	).Stores(s).Engine()
+	require.NoError(t, err)
+
+	type step struct {
+		Rule, Object string
+		Outcome      Outcome
+		Query        string
+		Count        int
+		Err          string
+	}
+	want := []step{
+		{Rule: "ab", Object: "0", Outcome: Applied, Query: "mock:b:1,2", Count: 2},
+		{Rule: "ac", Object: "0", Outcome: NotApplicable, Count: -1, Err: korrel8r.ErrNotApplicable.Error()},
+		{Rule: "ad", Object: "0", Outcome: Failed, Count: -1, Err: "bad template"},
+	}
+	for _, x := range []struct {
+		name string
+		t    Traverser
+	}{
+		{name: "sync", t: NewSync(e, e.Graph())},
+		{name: "async", t: NewAsync(e, e.Graph())},
+	} {
+		t.Run(x.name, func(t *testing.T) {
+			explain := NewExplanation()
+			ctx := WithExplanation(context.Background(), explain)
+			start := Start{Class: ca, Objects: []korrel8r.Object{0}}
+			_, err := x.t.Goals(ctx, start, list(cb, cc, cd))
+			assert.NoError(t, err)
+			var got []step
+			for _, s := range explain.Steps() {
+				assert.Equal(t, ca, s.Start)
+				g := step{Rule: s.Rule.Name(), Object: s.Object, Outcome: s.Outcome, Count: s.Count}
+				if s.Query != nil {
+					g.Query = s.Query.String()
+				}
+				if s.Err != nil {
+					g.Err = s.Err.Error()
+				}
+				got = append(got, g)
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+	assert.Nil(t, ExplanationFrom(context.Background()).Steps())
+}
+
+// windowRule narrows the constraint to an hour either side of the start object, an int hour offset from t0.
+type windowRule struct {
+	*mock.Rule
+	t0 time.Time
+}
+
+func (r windowRule) Constrain(start korrel8r.Object, c *korrel8r.Constraint) (*korrel8r.Constraint, error) {
+	at := r.t0.Add(time.Duration(start.(int)) * time.Hour)
+	n, ok := c.Narrow(at.Add(-time.Hour), at.Add(time.Hour))
+	if !ok {
+		return nil, korrel8r.ErrNotApplicable
+	}
+	return n, nil
+}
+
+func TestTraverserConstrain(t *testing.T) {
+	t0 := time.Now().Truncate(time.Hour)
+	hour := func(o korrel8r.Object) time.Time { return t0.Add(time.Duration(o.(int)) * time.Hour) }
+	d := mock.Domain("mock")
+	s := mock.NewStore(d)
+	s.ConstraintFunc = func(c *korrel8r.Constraint, o korrel8r.Object) bool { return c.CompareTime(hour(o)) == 0 }
+	c := d.Class
+	ca, cb, cc := c("a"), c("b"), c("c")
+	all := []korrel8r.Object{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	qb, qc := mock.NewQuery(cb, "all", all...), mock.NewQuery(cc, "all", all...)
+	e, err := engine.Build().Rules(
+		windowRule{Rule: mock.NewRule("ab", list(ca), list(cb), qb), t0: t0},
+		r("ac", ca, cc, qc),
+	).Stores(s).Engine()
+	require.NoError(t, err)
+
+	for _, x := range []struct {
+		name string
+		t    Traverser
+	}{
+		{name: "sync", t: NewSync(e, e.Graph())},
+		{name: "async", t: NewAsync(e, e.Graph())},
+	} {
+		t.Run(x.name, func(t *testing.T) {
+			explain := NewExplanation()
+			ctx, cancel := korrel8r.WithConstraint(WithExplanation(context.Background(), explain), &korrel8r.Constraint{Start: ptr.To(t0), End: ptr.To(hour(8))})
+			defer cancel()
+			start := Start{Class: ca, Objects: []korrel8r.Object{0, 5, 12}}
+			g, err := x.t.Goals(ctx, start, list(cb, cc))
+			require.NoError(t, err)
+			// Same query with a different window for each start object.
+			assert.ElementsMatch(t, []korrel8r.Object{0, 1, 4, 5, 6}, g.NodeFor(cb).Result.List())
+			assert.Equal(t, 5, g.NodeFor(cb).Queries.Get(qb))
+			// Rule without a window uses the request constraint.
+			assert.ElementsMatch(t, all[:9], g.NodeFor(cc).Result.List())
+			// Window outside the request constraint, no query.
+			i := slices.IndexFunc(explain.Steps(), func(s Step) bool { return s.Rule.Name() == "ab" && s.Object == "12" })
+			require.GreaterOrEqual(t, i, 0)
+			assert.Equal(t, NotApplicable, explain.Steps()[i].Outcome)
+			assert.Nil(t, explain.Steps()[i].Query)
+		})
+	}
+}
+
+func TestMultiStart(t *testing.T) {
+	d := mock.Domain("mock")
+	s := mock.NewStore(d)
+	c := d.Class
+	ca, cb, cc, cz := c("a"), c("b"), c("c"), c("z")
+	e, err := engine.Build().Rules(
+		r("ac", ca, cc, mock.NewQuery(cc, "1,2", 1, 2)),
+		r("bc", cb, cc, mock.NewQuery(cc, "2,3", 2, 3)),
+		r("az", ca, cz, mock.NewQuery(cz, "9", 9)),
+	).Stores(s).Engine()
+	require.NoError(t, err)
+	starts := []Start{
+		{Class: ca, Objects: []korrel8r.Object{0}},
+		{Class: cb, Objects: []korrel8r.Object{0}},
+	}
+	for _, x := range []struct {
+		name   string
+		search Search
+	}{
+		{name: "neighbours", search: NeighbourSearch(1)},
+		{name: "goals", search: GoalSearch(list(cc, cz))},
+	} {
+		t.Run(x.name, func(t *testing.T) {
+			result, err := MultiStart(context.Background(), e, starts, x.search)
+			require.NoError(t, err)
+			require.Len(t, result.Graphs, 2)
+			assert.ElementsMatch(t, []korrel8r.Object{1, 2}, result.Graphs[0].NodeFor(cc).Result.List())
+			assert.ElementsMatch(t, []korrel8r.Object{2, 3}, result.Graphs[1].NodeFor(cc).Result.List())
+			assert.Equal(t, []Intersection{{Class: cc, Starts: []int{0, 1}, Objects: []korrel8r.Object{2}}}, result.Intersections)
+		})
+	}
+	_, err = MultiStart(context.Background(), e, nil, NeighbourSearch(1))
+	assert.Error(t, err)
+}
+
 func TestErrors(t *testing.T) {
 	assert.NoError(t, NewErrors().Err())
 
@@ -3,7 +3,10 @@
 package traverse
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/korrel8r/korrel8r/internal/pkg/logging"
 	"github.com/korrel8r/korrel8r/pkg/engine"
@@ -13,6 +16,8 @@ import (
 
 // Traverser traverses a graph, filling in [Node.Result], [Node.Queries] and [Line.Queries].
 // A korrel8r.Constraint can be set on the context if needed.
+// Rules that implement [korrel8r.Constrainer] narrow the constraint for their own goal queries.
+// Goal searches follow the lowest-cost paths according to [engine.Engine.Cost], see also [WithMaxCost].
 type Traverser interface {
 	// Goals traverses all paths from start objects to all goal classes.
 	Goals(ctx context.Context, start Start, goals []korrel8r.Class) (*graph.Graph, error)
@@ -30,4 +35,67 @@ type Start struct {
 	Queries []korrel8r.Query  // Queries for start objects, must be of Start class.
 }
 
+type maxCostKey struct{}
+
+// WithMaxCost returns a context that limits goal searches to goals with a lowest path cost no more than maxCost.
+// See [engine.Engine.Cost] for how costs are determined.
+func WithMaxCost(ctx context.Context, maxCost float64) context.Context {
+	return context.WithValue(ctx, maxCostKey{}, maxCost)
+}
+
+// MaxCostFrom returns the maximum goal search cost from a context, 0 means no limit.
+func MaxCostFrom(ctx context.Context) float64 {
+	maxCost, _ := ctx.Value(maxCostKey{}).(float64)
+	return maxCost
+}
+
+// goalQuery is a query generated by a rule, with the constraint to use when getting it.
+type goalQuery struct {
+	Query korrel8r.Query
+	// Constraint narrowed by the rule, nil means use the constraint from the context.
+	Constraint *korrel8r.Constraint
+}
+
+// key identifies a goal query by query string and narrowed time interval, if there is one.
+// The same query may be generated with different intervals from different start objects.
+func (gq goalQuery) key() string {
+	if c := gq.Constraint; c != nil {
+		return fmt.Sprintf("%v [%v,%v]", gq.Query, c.GetStart().Format(time.RFC3339Nano), c.GetEnd().Format(time.RFC3339Nano))
+	}
+	return gq.Query.String()
+}
+
+// constraint returns the constraint to use when getting the query.
+func (gq goalQuery) constraint(ctx context.Context) *korrel8r.Constraint {
+	return cmp.Or(gq.Constraint, korrel8r.ConstraintFrom(ctx))
+}
+
+// applyRule applies rule to a start object.
+// The constraint from ctx is narrowed for the goal query if the rule is a [korrel8r.Constrainer],
+// the query is skipped if the narrowed interval is empty.
+// Returns a goalQuery with nil Query if the rule does not apply.
+func applyRule(ctx context.Context, rule korrel8r.Rule, start korrel8r.Class, o korrel8r.Object) (gq goalQuery, err error) {
+	gq.Query, err = rule.Apply(o)
+	if c, ok := rule.(korrel8r.Constrainer); ok && gq.Query != nil && err == nil {
+		ctxConstraint := korrel8r.ConstraintFrom(ctx)
+		narrow, err2 := c.Constrain(o, ctxConstraint)
+		switch {
+		case err2 != nil:
+			gq.Query, err = nil, err2
+		case narrow != ctxConstraint:
+			gq.Constraint = narrow.Default() // Fill in defaults so the key does not change.
+		}
+	}
+	ExplanationFrom(ctx).apply(rule, start, o, gq.Query, err)
+	switch {
+	case err != nil:
+		ruleApplications.WithLabelValues(rule.Name(), ruleError).Inc()
+	case gq.Query == nil:
+		ruleApplications.WithLabelValues(rule.Name(), ruleNone).Inc()
+	default:
+		ruleApplications.WithLabelValues(rule.Name(), ruleQuery).Inc()
+	}
+	return gq, err
+}
+
 var log = logging.Log()
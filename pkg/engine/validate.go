@@ -0,0 +1,228 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/unique"
+)
+
+// Validate checks configurations against a set of domains, and reports all problems found.
+//
+// In addition to [config.Configs.Validate] it reports:
+//   - errors: unknown domains or classes, rule templates that fail to parse,
+//     rule templates that refer to fields that do not exist on the Go type of a start class.
+//   - warnings: classes that start a rule but are not the goal of any rule, so they are unreachable.
+//
+// Stores are not created, problems connecting to stores are not detected.
+func Validate(configs config.Configs, domains ...korrel8r.Domain) config.Problems {
+	l := config.NewLocator()
+	problems := configs.Validate(l)
+	if problems.Errors() > 0 {
+		return problems // Can't expand aliases.
+	}
+	expanded, err := configs.Expand()
+	if err != nil {
+		return append(problems, config.Problem{Message: err.Error()})
+	}
+	e := Build().Domains(domains...).e
+	starts, goals := unique.NewList[korrel8r.Class](), unique.NewSet[korrel8r.Class]()
+	for _, c := range expanded {
+		for i, r := range c.Rules {
+			problem := func(format string, args ...any) {
+				problems = append(problems, l.Problem(c.Source, config.SectionRules, i, false, "rule %q: %v", r.Name, fmt.Sprintf(format, args...)))
+			}
+			start, err := validateClasses(e, &r.Start)
+			if err != nil {
+				problem("start: %v", err)
+			}
+			goal, err := validateClasses(e, &r.Goal)
+			if err != nil {
+				problem("goal: %v", err)
+			}
+			starts.Append(start...)
+			for _, c := range goal {
+				goals.Add(c)
+			}
+			if c := r.Result.Constraint; c != nil {
+				for _, text := range []string{c.Start, c.End} {
+					if _, err := e.NewTemplate(r.Name).Parse(text); err != nil {
+						problem("constraint: %v", err)
+					}
+				}
+			}
+			tmpl, err := e.NewTemplate(r.Name).Parse(r.Result.Query)
+			if err != nil {
+				problem("%v", err)
+				continue
+			}
+			if len(r.Start.Classes) > 0 {
+				for _, class := range start {
+					for _, field := range missingFields(class, tmpl.Tree) {
+						problem("template refers to %v, not found in %v", field, class)
+					}
+				}
+			} else {
+				// Rule applies to all classes in the domain, only report fields missing in every class.
+				count := map[string]int{}
+				for _, class := range start {
+					for _, field := range missingFields(class, tmpl.Tree) {
+						count[field]++
+					}
+				}
+				for _, field := range slices.Sorted(maps.Keys(count)) {
+					if count[field] == len(start) {
+						problem("template refers to %v, not found in any class of domain %v", field, r.Start.Domain)
+					}
+				}
+			}
+		}
+		for i, s := range c.Stores {
+			if _, err := e.DomainErr(s[config.StoreKeyDomain]); err != nil && s[config.StoreKeyDomain] != "" {
+				problems = append(problems, l.Problem(c.Source, config.SectionStores, i, false, "store: %v", err))
+			}
+		}
+	}
+	for _, class := range starts.List {
+		if !goals.Has(class) {
+			problems = append(problems, config.Problem{Warning: true, Message: fmt.Sprintf("class %v: unreachable, not the goal of any rule", class)})
+		}
+	}
+	return problems
+}
+
+func validateClasses(e *Engine, spec *config.ClassSpec) ([]korrel8r.Class, error) {
+	d, err := e.DomainErr(spec.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Classes) == 0 {
+		return d.Classes(), nil
+	}
+	var classes []korrel8r.Class
+	var unknown []string
+	for _, name := range spec.Classes {
+		if c := d.Class(name); c != nil {
+			classes = append(classes, c)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return classes, fmt.Errorf("unknown classes in domain %v: %v", d, strings.Join(unknown, ", "))
+	}
+	return classes, nil
+}
+
+// missingFields returns field references in tree that do not exist in the Go type of objects of class.
+// Only references to the top-level object are checked, the type of dot is unknown inside range and with.
+func missingFields(class korrel8r.Class, tree *parse.Tree) []string {
+	o, err := class.Unmarshal([]byte("{}"))
+	if err != nil || o == nil {
+		return nil // Can't determine the Go type.
+	}
+	root := reflect.TypeOf(o)
+	missing := unique.NewList[string]()
+
+	check := func(t reflect.Type, prefix string, idents []string) {
+		for i, name := range idents {
+			if t == nil {
+				return // Unknown type, can't check.
+			}
+			var ok bool
+			if t, ok = fieldType(t, name); !ok {
+				missing.Append(prefix + strings.Join(idents[:i+1], "."))
+				return
+			}
+		}
+	}
+	var walk func(n parse.Node, dot reflect.Type)
+	walkPipe := func(p *parse.PipeNode, dot reflect.Type) {
+		if p == nil {
+			return
+		}
+		for _, cmd := range p.Cmds {
+			for _, arg := range cmd.Args {
+				switch arg := arg.(type) {
+				case *parse.FieldNode:
+					check(dot, ".", arg.Ident)
+				case *parse.VariableNode:
+					if arg.Ident[0] == "$" && len(arg.Ident) > 1 {
+						check(root, "$.", arg.Ident[1:])
+					}
+				case *parse.PipeNode:
+					walk(arg, dot)
+				}
+			}
+		}
+	}
+	walk = func(n parse.Node, dot reflect.Type) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, n := range n.Nodes {
+					walk(n, dot)
+				}
+			}
+		case *parse.PipeNode:
+			walkPipe(n, dot)
+		case *parse.ActionNode:
+			walkPipe(n.Pipe, dot)
+		case *parse.TemplateNode:
+			walkPipe(n.Pipe, dot)
+		case *parse.IfNode:
+			walkPipe(n.Pipe, dot)
+			walk(n.List, dot)
+			walk(n.ElseList, dot)
+		case *parse.RangeNode:
+			walkPipe(n.Pipe, dot)
+			walk(n.List, nil)
+			walk(n.ElseList, dot)
+		case *parse.WithNode:
+			walkPipe(n.Pipe, dot)
+			walk(n.List, nil)
+			walk(n.ElseList, dot)
+		}
+	}
+	walk(tree.Root, root)
+	return missing.List
+}
+
+// fieldType returns the type of .name applied to a value of type t, using the same lookup as text/template.
+// Returns a nil type if the field exists but its type is unknown.
+func fieldType(t reflect.Type, name string) (reflect.Type, bool) {
+	if t.Kind() == reflect.Interface {
+		return nil, true // Dynamic type, can't check.
+	}
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+		t = reflect.PointerTo(t) // Include methods with pointer receivers.
+	}
+	if m, ok := t.MethodByName(name); ok {
+		if m.Type.NumOut() == 0 {
+			return nil, true
+		}
+		return m.Type.Out(0), true
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if f, ok := t.FieldByName(name); ok && f.IsExported() {
+			return f.Type, true
+		}
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Interface:
+		return nil, true
+	}
+	return nil, false
+}
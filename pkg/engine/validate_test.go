@@ -0,0 +1,36 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/domains/incident"
+	"github.com/korrel8r/korrel8r/pkg/domains/k8s"
+	"github.com/korrel8r/korrel8r/pkg/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	configs, err := config.Read("testdata/validate.yaml")
+	require.NoError(t, err)
+	var got []string
+	for _, p := range engine.Validate(configs, incident.Domain, k8s.Domain, mock.Domain("mock")) {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{
+		`testdata/validate.yaml:26: warning: rule "duplicate": duplicate of rule defined at testdata/validate.yaml:6`,
+		`testdata/validate.yaml:2: warning: alias "unused": not used`,
+		`testdata/validate.yaml:10: error: rule "badField": template refers to .Nonesuch, not found in incident:incident`,
+		`testdata/validate.yaml:10: error: rule "badField": template refers to $.Bad, not found in incident:incident`,
+		`testdata/validate.yaml:10: error: rule "badField": template refers to .CreatedAt.Bad, not found in incident:incident`,
+		`testdata/validate.yaml:14: error: rule "badTemplate": template: badTemplate:1: unclosed action`,
+		`testdata/validate.yaml:18: error: rule "badClass": start: unknown classes in domain k8s: Nonesuch`,
+		`testdata/validate.yaml:22: error: rule "badDomain": start: domain not found: "nonesuch"`,
+		`testdata/validate.yaml:31: error: store: domain not found: "nonesuch"`,
+		`warning: class incident:incident: unreachable, not the goal of any rule`,
+	}, got)
+}
@@ -0,0 +1,338 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package graph
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/encoding/dot"
+	"gonum.org/v1/gonum/graph/multi"
+)
+
+// Format is a named encoding for graphs.
+//
+// Encoders include result counts: the number of objects in each node and the query counts for each line.
+// Counts are omitted for a rule graph that has no results.
+type Format struct {
+	Name      string                            // Name used to select the format, e.g. by a command line flag.
+	MediaType string                            // MediaType for HTTP content negotiation.
+	Encode    func(w io.Writer, g *Graph) error // Encode writes g to w.
+}
+
+var (
+	// DOT is the GraphViz format.
+	DOT = &Format{Name: "dot", MediaType: "text/vnd.graphviz", Encode: encodeDOT}
+	// Mermaid is a Mermaid flowchart.
+	Mermaid = &Format{Name: "mermaid", MediaType: "text/vnd.mermaid", Encode: encodeMermaid}
+	// Cytoscape is Cytoscape.js JSON elements.
+	Cytoscape = &Format{Name: "cytoscape", MediaType: "application/vnd.cytoscape+json", Encode: encodeCytoscape}
+	// GraphML is the GraphML XML format.
+	GraphML = &Format{Name: "graphml", MediaType: "application/graphml+xml", Encode: encodeGraphML}
+)
+
+var formats = []*Format{DOT, Mermaid, Cytoscape, GraphML}
+
+// RegisterFormat adds a format, replacing any existing format with the same name.
+// It is not safe to call concurrently with other Format functions, call it during initialization.
+func RegisterFormat(f *Format) {
+	formats = slices.DeleteFunc(formats, func(x *Format) bool { return x.Name == f.Name })
+	formats = append(formats, f)
+}
+
+// Formats returns the registered formats.
+func Formats() []*Format { return slices.Clone(formats) }
+
+// FormatNames returns the names of the registered formats.
+func FormatNames() (names []string) {
+	for _, f := range formats {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// FormatNamed returns the format with name, or nil if there is none.
+func FormatNamed(name string) *Format {
+	i := slices.IndexFunc(formats, func(f *Format) bool { return f.Name == name })
+	if i < 0 {
+		return nil
+	}
+	return formats[i]
+}
+
+// FormatFor returns the format for a media type, or nil if there is none.
+func FormatFor(mediaType string) *Format {
+	i := slices.IndexFunc(formats, func(f *Format) bool { return f.MediaType == mediaType })
+	if i < 0 {
+		return nil
+	}
+	return formats[i]
+}
+
+// NonEmpty returns a new graph with the nodes of g that have results, and the lines between them.
+func (g *Graph) NonEmpty() *Graph {
+	sub := g.Select(func(l *Line) bool { return !l.Start().Empty() && !l.Goal().Empty() })
+	g.EachNode(func(n *Node) {
+		if !n.Empty() {
+			sub.MergeNode(n)
+		}
+	})
+	return sub
+}
+
+// sortedNodes returns the nodes of g in ID order, for stable output.
+func sortedNodes(g *Graph) (nodes []*Node) {
+	g.EachNode(func(n *Node) { nodes = append(nodes, n) })
+	slices.SortFunc(nodes, func(a, b *Node) int { return cmp.Compare(a.ID(), b.ID()) })
+	return nodes
+}
+
+// sortedLines returns the lines of g in ID order, for stable output.
+func sortedLines(g *Graph) (lines []*Line) {
+	g.EachLine(func(l *Line) { lines = append(lines, l) })
+	slices.SortFunc(lines, func(a, b *Line) int { return cmp.Compare(a.ID(), b.ID()) })
+	return lines
+}
+
+// hasResults is true if any node or line in g has results or queries.
+func hasResults(g *Graph) (ok bool) {
+	g.EachNode(func(n *Node) { ok = ok || !n.Empty() || len(n.Queries) > 0 })
+	g.EachLine(func(l *Line) { ok = ok || len(l.Queries) > 0 })
+	return ok
+}
+
+// label returns a name with a count, or just the name if counts are not shown.
+func label(name string, count int, counts bool) string {
+	if !counts {
+		return name
+	}
+	return fmt.Sprintf("%v (%v)", name, count)
+}
+
+func nodeCount(n *Node) int { return len(n.Result.List()) }
+
+// dotNode and dotLine add result labels to the GraphViz attributes of a node or line.
+type dotNode struct {
+	*Node
+	counts bool
+}
+
+func (n dotNode) Attributes() []encoding.Attribute {
+	return append(n.Attrs.Attributes(), encoding.Attribute{Key: "label", Value: label(n.Class.String(), nodeCount(n.Node), n.counts)})
+}
+
+type dotLine struct {
+	multi.Line
+	line   *Line
+	counts bool
+}
+
+func (l dotLine) DOTID() string { return l.line.DOTID() }
+func (l dotLine) Attributes() []encoding.Attribute {
+	return append(l.line.Attrs.Attributes(), encoding.Attribute{Key: "label", Value: label(l.line.Rule.Name(), l.line.Queries.Total(), l.counts)})
+}
+
+// dotGraph is a copy of a Graph with dotNode and dotLine values.
+type dotGraph struct {
+	*multi.DirectedGraph
+	g *Graph
+}
+
+func (g dotGraph) DOTID() string { return g.g.DOTID() }
+func (g dotGraph) DOTAttributers() (graph, node, edge encoding.Attributer) {
+	return g.g.DOTAttributers()
+}
+
+func encodeDOT(w io.Writer, g *Graph) error {
+	counts := hasResults(g)
+	dg := dotGraph{DirectedGraph: multi.NewDirectedGraph(), g: g}
+	nodes := map[int64]dotNode{}
+	for _, n := range sortedNodes(g) {
+		nodes[n.ID()] = dotNode{Node: n, counts: counts}
+		dg.AddNode(nodes[n.ID()])
+	}
+	for _, l := range sortedLines(g) {
+		dg.SetLine(dotLine{
+			Line:   multi.Line{F: nodes[l.Start().ID()], T: nodes[l.Goal().ID()], UID: l.ID()},
+			line:   l,
+			counts: counts,
+		})
+	}
+	b, err := dot.MarshalMulti(dg, "", "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func encodeMermaid(w io.Writer, g *Graph) error {
+	counts := hasResults(g)
+	quote := strings.NewReplacer(`"`, "#quot;").Replace
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "flowchart LR")
+	for _, n := range sortedNodes(g) {
+		fmt.Fprintf(b, "  n%v[\"%v\"]\n", n.ID(), quote(label(n.Class.String(), nodeCount(n), counts)))
+	}
+	for _, l := range sortedLines(g) {
+		fmt.Fprintf(b, "  n%v -->|\"%v\"| n%v\n", l.Start().ID(), quote(label(l.Rule.Name(), l.Queries.Total(), counts)), l.Goal().ID())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// cyElements is the Cytoscape.js JSON elements format.
+type cyElements struct {
+	Nodes []cyElement `json:"nodes"`
+	Edges []cyElement `json:"edges"`
+}
+
+type cyElement struct {
+	Data cyData `json:"data"`
+}
+
+type cyData struct {
+	ID      string    `json:"id"`
+	Source  string    `json:"source,omitempty"`
+	Target  string    `json:"target,omitempty"`
+	Label   string    `json:"label"`
+	Class   string    `json:"class,omitempty"`
+	Rule    string    `json:"rule,omitempty"`
+	Count   *int      `json:"count,omitempty"`
+	Queries []cyQuery `json:"queries,omitempty"`
+}
+
+type cyQuery struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+func cyQueries(qs Queries) (cqs []cyQuery) {
+	for _, qc := range qs {
+		cqs = append(cqs, cyQuery{Query: qc.Query.String(), Count: qc.Count})
+	}
+	slices.SortFunc(cqs, func(a, b cyQuery) int { return cmp.Compare(a.Query, b.Query) })
+	return cqs
+}
+
+func encodeCytoscape(w io.Writer, g *Graph) error {
+	counts := hasResults(g)
+	count := func(n int) *int {
+		if counts {
+			return &n
+		}
+		return nil
+	}
+	elements := cyElements{Nodes: []cyElement{}, Edges: []cyElement{}}
+	for _, n := range sortedNodes(g) {
+		elements.Nodes = append(elements.Nodes, cyElement{Data: cyData{
+			ID:      n.Class.String(),
+			Label:   n.Class.String(),
+			Class:   n.Class.String(),
+			Count:   count(nodeCount(n)),
+			Queries: cyQueries(n.Queries),
+		}})
+	}
+	for _, l := range sortedLines(g) {
+		elements.Edges = append(elements.Edges, cyElement{Data: cyData{
+			ID:      fmt.Sprintf("e%v", l.ID()),
+			Source:  l.Start().Class.String(),
+			Target:  l.Goal().Class.String(),
+			Label:   l.Rule.Name(),
+			Rule:    l.Rule.Name(),
+			Count:   count(l.Queries.Total()),
+			Queries: cyQueries(l.Queries),
+		}})
+	}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(elements)
+}
+
+// GraphML document types.
+type (
+	gmlDoc struct {
+		XMLName xml.Name `xml:"graphml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Keys    []gmlKey `xml:"key"`
+		Graph   gmlGraph `xml:"graph"`
+	}
+	gmlKey struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	gmlGraph struct {
+		ID          string    `xml:"id,attr"`
+		EdgeDefault string    `xml:"edgedefault,attr"`
+		Nodes       []gmlNode `xml:"node"`
+		Edges       []gmlEdge `xml:"edge"`
+	}
+	gmlNode struct {
+		ID   string    `xml:"id,attr"`
+		Data []gmlData `xml:"data"`
+	}
+	gmlEdge struct {
+		ID     string    `xml:"id,attr"`
+		Source string    `xml:"source,attr"`
+		Target string    `xml:"target,attr"`
+		Data   []gmlData `xml:"data"`
+	}
+	gmlData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+func encodeGraphML(w io.Writer, g *Graph) error {
+	counts := hasResults(g)
+	doc := gmlDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []gmlKey{
+			{ID: "class", For: "node", Name: "class", Type: "string"},
+			{ID: "rule", For: "edge", Name: "rule", Type: "string"},
+		},
+		Graph: gmlGraph{ID: cmp.Or(g.DOTID(), "korrel8r"), EdgeDefault: "directed"},
+	}
+	if counts {
+		doc.Keys = append(doc.Keys,
+			gmlKey{ID: "nodeCount", For: "node", Name: "count", Type: "int"},
+			gmlKey{ID: "edgeCount", For: "edge", Name: "count", Type: "int"})
+	}
+	for _, n := range sortedNodes(g) {
+		gn := gmlNode{ID: fmt.Sprintf("n%v", n.ID()), Data: []gmlData{{Key: "class", Value: n.Class.String()}}}
+		if counts {
+			gn.Data = append(gn.Data, gmlData{Key: "nodeCount", Value: fmt.Sprint(nodeCount(n))})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gn)
+	}
+	for _, l := range sortedLines(g) {
+		ge := gmlEdge{
+			ID:     fmt.Sprintf("e%v", l.ID()),
+			Source: fmt.Sprintf("n%v", l.Start().ID()),
+			Target: fmt.Sprintf("n%v", l.Goal().ID()),
+			Data:   []gmlData{{Key: "rule", Value: l.Rule.Name()}},
+		}
+		if counts {
+			ge.Data = append(ge.Data, gmlData{Key: "edgeCount", Value: fmt.Sprint(l.Queries.Total())})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, ge)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
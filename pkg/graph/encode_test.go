@@ -0,0 +1,103 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func list[T any](x ...T) []T { return x }
+
+// encodeGraph returns a result graph 1->2->3 where 3 has no results.
+func encodeGraph() *Graph {
+	r12 := mock.NewRule("r12", list(c(1)), list(c(2)), mock.NewQuery(c(2), "q2", "a", "b"))
+	r23 := mock.NewRule("r23", list(c(2)), list(c(3)), mock.NewQuery(c(3), "q3"))
+	g := testGraph(list[rule](r12, r23))
+	g.NodeFor(c(1)).Result.Append("x")
+	g.NodeFor(c(2)).Result.Append("a", "b")
+	q2, _ := r12.Apply(nil)
+	g.NodeFor(c(2)).Queries.Set(q2, 2)
+	g.EachLine(func(l *Line) {
+		if l.Rule == r12 {
+			l.Queries.Set(q2, 2)
+		}
+	})
+	return g
+}
+
+func encode(t *testing.T, f *Format, g *Graph) string {
+	t.Helper()
+	var b bytes.Buffer
+	require.NoError(t, f.Encode(&b, g))
+	return b.String()
+}
+
+func TestFormat_Mermaid(t *testing.T) {
+	g := encodeGraph()
+	assert.Equal(t, `flowchart LR
+  n0["graphmock:1 (1)"]
+  n1["graphmock:2 (2)"]
+  n2["graphmock:3 (0)"]
+  n0 -->|"r12 (2)"| n1
+  n1 -->|"r23 (0)"| n2
+`, encode(t, Mermaid, g))
+	assert.Equal(t, `flowchart LR
+  n0["graphmock:1 (1)"]
+  n1["graphmock:2 (2)"]
+  n0 -->|"r12 (2)"| n1
+`, encode(t, Mermaid, g.NonEmpty()))
+	// Rule graph without results has no counts.
+	assert.Equal(t, `flowchart LR
+  n0["graphmock:1"]
+  n1["graphmock:2"]
+  n0 -->|"r12"| n1
+`, encode(t, Mermaid, testGraph(list[rule](mock.NewRule("r12", list(c(1)), list(c(2)), mock.NewQuery(c(2), "q2"))))))
+}
+
+func TestFormat_Cytoscape(t *testing.T) {
+	assert.JSONEq(t, `{
+  "nodes": [
+    {"data": {"id": "graphmock:1", "label": "graphmock:1", "class": "graphmock:1", "count": 1}},
+    {"data": {"id": "graphmock:2", "label": "graphmock:2", "class": "graphmock:2", "count": 2, "queries": [{"query": "graphmock:2:q2", "count": 2}]}}
+  ],
+  "edges": [
+    {"data": {"id": "e0", "source": "graphmock:1", "target": "graphmock:2", "label": "r12", "rule": "r12", "count": 2, "queries": [{"query": "graphmock:2:q2", "count": 2}]}}
+  ]
+}`, encode(t, Cytoscape, encodeGraph().NonEmpty()))
+}
+
+func TestFormat_GraphML(t *testing.T) {
+	var doc gmlDoc
+	require.NoError(t, xml.Unmarshal([]byte(encode(t, GraphML, encodeGraph().NonEmpty())), &doc))
+	assert.Equal(t, "http://graphml.graphdrawing.org/xmlns", doc.XMLNS)
+	assert.Equal(t, []gmlNode{
+		{ID: "n0", Data: []gmlData{{Key: "class", Value: "graphmock:1"}, {Key: "nodeCount", Value: "1"}}},
+		{ID: "n1", Data: []gmlData{{Key: "class", Value: "graphmock:2"}, {Key: "nodeCount", Value: "2"}}},
+	}, doc.Graph.Nodes)
+	assert.Equal(t, []gmlEdge{
+		{ID: "e0", Source: "n0", Target: "n1", Data: []gmlData{{Key: "rule", Value: "r12"}, {Key: "edgeCount", Value: "2"}}},
+	}, doc.Graph.Edges)
+}
+
+func TestFormat_DOT(t *testing.T) {
+	s := encode(t, DOT, encodeGraph().NonEmpty())
+	assert.Contains(t, s, `"graphmock:1" [label="graphmock:1 (1)"];`)
+	assert.Contains(t, s, `"graphmock:2" [label="graphmock:2 (2)"];`)
+	assert.Contains(t, s, `"graphmock:1" -> "graphmock:2" [`)
+	assert.Contains(t, s, `label="r12 (2)"`)
+}
+
+func TestFormatNamed(t *testing.T) {
+	for _, f := range Formats() {
+		assert.Equal(t, f, FormatNamed(f.Name))
+		assert.Equal(t, f, FormatFor(f.MediaType))
+	}
+	assert.Nil(t, FormatNamed("nonesuch"))
+	assert.Equal(t, []string{"dot", "mermaid", "cytoscape", "graphml"}, FormatNames())
+}
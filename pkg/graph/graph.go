@@ -4,6 +4,8 @@
 package graph
 
 import (
+	"math"
+
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 	"github.com/korrel8r/korrel8r/pkg/unique"
 
@@ -144,15 +146,37 @@ func (g *Graph) DOTAttributers() (graph, node, edge encoding.Attributer) {
 
 // ShortestPaths returns a new sub-graph containing all shortest paths between start and goals.
 func (g *Graph) ShortestPaths(start korrel8r.Class, goals ...korrel8r.Class) *Graph {
-	paths := path.DijkstraAllFrom(g.NodeFor(start), g)
+	return g.CheapestPaths(start, goals, nil, 0)
+}
+
+// Cost returns the cost of following a line, it must not be negative.
+type Cost func(*Line) float64
+
+// CheapestPaths returns a new sub-graph containing all lowest-cost paths between start and goals.
+// If cost is nil, every line has cost 1 so the cheapest paths are the shortest paths.
+//
+// Where there are multiple lines between two nodes on a path, only the lowest-cost lines are included.
+// If budget > 0, goals with a lowest path cost greater than budget are not included.
+func (g *Graph) CheapestPaths(start korrel8r.Class, goals []korrel8r.Class, cost Cost, budget float64) *Graph {
+	if cost == nil {
+		cost = func(*Line) float64 { return 1 }
+	}
+	w := weighted{Graph: g, cost: cost}
+	paths := path.DijkstraAllFrom(g.NodeFor(start), w)
 	sub := g.Data.EmptyGraph()
 	for _, goal := range goals {
 		v := g.NodeFor(goal).ID()
+		if budget > 0 && paths.WeightTo(v) > budget {
+			continue
+		}
 		paths.AllToFunc(v, func(path []graph.Node) {
 			for i := 1; i < len(path); i++ {
+				min, _ := w.Weight(path[i-1].ID(), path[i].ID())
 				lines := g.Lines(path[i-1].ID(), path[i].ID())
 				for lines.Next() {
-					sub.SetLine(lines.Line())
+					if cost(lines.Line().(*Line)) == min {
+						sub.SetLine(lines.Line())
+					}
 				}
 			}
 		})
@@ -160,6 +184,24 @@ func (g *Graph) ShortestPaths(start korrel8r.Class, goals ...korrel8r.Class) *Gr
 	return sub
 }
 
+// weighted implements [path.Weighted], the weight of an edge is the lowest cost of its lines.
+type weighted struct {
+	*Graph
+	cost Cost
+}
+
+func (w weighted) Weight(xid, yid int64) (float64, bool) {
+	if xid == yid {
+		return 0, true
+	}
+	min := math.Inf(1)
+	lines := w.Lines(xid, yid)
+	for lines.Next() {
+		min = math.Min(min, w.cost(lines.Line().(*Line)))
+	}
+	return min, !math.IsInf(min, 1)
+}
+
 func (g *Graph) MergeNode(n *Node) {
 	if g.Node(n.ID()) == nil {
 		g.AddNode(n)
@@ -153,3 +153,33 @@ func TestGraph_ShortestPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestGraph_CheapestPaths(t *testing.T) {
+	rm := ruleMap{}
+	r := func(i, j int) korrel8r.Rule { return rm.r(i, j) }
+	costs := map[string]float64{"1_13": 5, "3_13": 2}
+	cost := func(l *Line) float64 {
+		if c, ok := costs[l.Rule.Name()]; ok {
+			return c
+		}
+		return 1
+	}
+	graph := []rule{r(1, 2), r(1, 3), r(1, 13), r(2, 13), r(3, 13)}
+	for _, x := range []struct {
+		name   string
+		budget float64
+		want   []rule
+	}{
+		{name: "no budget", want: []rule{r(1, 2), r(2, 13)}},
+		{name: "within budget", budget: 2, want: []rule{r(1, 2), r(2, 13)}},
+		{name: "over budget", budget: 1.5, want: nil},
+	} {
+		t.Run(x.name, func(t *testing.T) {
+			paths := testGraph(graph).CheapestPaths(c(1), []korrel8r.Class{c(13)}, cost, x.budget)
+			mock.SortRules(x.want)
+			assert.Equal(t, x.want, graphRules(paths))
+		})
+	}
+	// Uniform cost is the same as shortest paths.
+	assert.Equal(t, []rule{r(1, 13)}, graphRules(testGraph(graph).CheapestPaths(c(1), []korrel8r.Class{c(13)}, nil, 0)))
+}
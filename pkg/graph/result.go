@@ -4,7 +4,6 @@ package graph
 
 import (
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
-	"github.com/korrel8r/korrel8r/pkg/unique"
 )
 
 // Result is an Appender that stores objects in order.
@@ -31,13 +30,15 @@ func (r ListResult) List() []korrel8r.Object            { return []korrel8r.Obje
 func (r *ListResult) Add(o korrel8r.Object) bool        { r.Append(o); return true }
 
 // SetResult de-duplicates the result using an IDer, it ignores second and subsequent objects with the same ID.
+// It counts the number of times each object was added, see [SetResult.Count].
 type SetResult struct {
-	dedup unique.Deduplicator[any, korrel8r.Object]
+	id    korrel8r.IDer
+	count map[any]int
 	list  []korrel8r.Object
 }
 
 func NewSetResult(id korrel8r.IDer) *SetResult {
-	return &SetResult{dedup: unique.NewDeduplicator(id.ID)}
+	return &SetResult{id: id, count: map[any]int{}}
 }
 func (r SetResult) List() []korrel8r.Object { return r.list }
 
@@ -47,9 +48,15 @@ func (r *SetResult) Append(objects ...korrel8r.Object) {
 	}
 }
 func (r *SetResult) Add(o korrel8r.Object) bool {
-	ok := r.dedup.Unique(o)
+	k := r.id.ID(o)
+	r.count[k]++
+	ok := r.count[k] == 1
 	if ok {
 		r.list = append(r.list, o)
 	}
 	return ok
 }
+
+// Count returns the number of times an object with the same ID as o was added.
+// For a correlation result, this is the number of distinct queries that returned the object.
+func (r *SetResult) Count(o korrel8r.Object) int { return r.count[r.id.ID(o)] }
@@ -84,7 +84,13 @@ func (g *Graph) DepthFirst(start korrel8r.Class, v Visitor, until func(*Node) bo
 
 // GoalSearch traverses the shortest paths from start to all goals, in breadth first order.
 func (g *Graph) GoalSearch(start korrel8r.Class, goals []korrel8r.Class, v Visitor) {
-	g.ShortestPaths(start, goals...).BreadthFirst(start, v, nil)
+	g.CheapestGoalSearch(start, goals, nil, 0, v)
+}
+
+// CheapestGoalSearch traverses the lowest-cost paths from start to all goals, in breadth first order.
+// See [Graph.CheapestPaths] for the meaning of cost and budget.
+func (g *Graph) CheapestGoalSearch(start korrel8r.Class, goals []korrel8r.Class, cost Cost, budget float64, v Visitor) {
+	g.CheapestPaths(start, goals, cost, budget).BreadthFirst(start, v, nil)
 }
 
 // Neighbours traverses a breadth-first neighbourhood of start up to depth.
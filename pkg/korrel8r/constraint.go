@@ -4,6 +4,7 @@ package korrel8r
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/korrel8r/korrel8r/pkg/ptr"
@@ -15,6 +16,9 @@ type Constraint struct {
 	Timeout *time.Duration `json:"timeout,omitempty" swaggertype:"string"`                                          // Timeout per request, h/m/s/ms/ns format
 	Start   *time.Time     `json:"start,omitempty" swaggertype:"string" format:"date-time" extensions:"x-nullable"` // Start of time interval, quoted RFC 3339 format.
 	End     *time.Time     `json:"end,omitempty" swaggertype:"string" format:"date-time" extensions:"x-nullable"`   // End of time interval, quoted RFC 3339 format.
+	// Namespaces if not empty restricts results to objects in one of the listed namespaces.
+	// Objects that do not belong to any namespace are excluded.
+	Namespaces []string `json:"namespaces,omitempty"`
 }
 
 // CompareTime returns -1 if t is before the constraint interval, +1 if it is after,
@@ -32,6 +36,66 @@ func (c *Constraint) CompareTime(when time.Time) int {
 	return 0
 }
 
+// Overlaps returns true if the interval [start, end] overlaps the constraint interval.
+// A zero end means the interval is not finished, a zero start means the time is not known.
+// Returns true if the time is not known, or if there is no constraint interval.
+// Safe to call with c == nil
+func (c *Constraint) Overlaps(start, end time.Time) bool {
+	if start.IsZero() {
+		return true
+	}
+	return c.CompareTime(start) <= 0 && (end.IsZero() || c.CompareTime(end) >= 0)
+}
+
+// Narrow returns a copy of c with the time interval narrowed to its intersection with [start, end].
+// A zero start or end does not narrow that end of the interval.
+// Returns false if the intersection is empty.
+// Safe to call with c == nil.
+func (c *Constraint) Narrow(start, end time.Time) (*Constraint, bool) {
+	var n Constraint
+	if c != nil {
+		n = *c
+	}
+	if !start.IsZero() && (n.Start == nil || start.After(*n.Start)) {
+		n.Start = &start
+	}
+	if !end.IsZero() && (n.End == nil || end.Before(*n.End)) {
+		n.End = &end
+	}
+	return &n, n.Start == nil || n.End == nil || !n.Start.After(*n.End)
+}
+
+// AllowsNamespace returns true if objects in namespace ns are allowed by the constraint.
+// Safe to call with c == nil.
+func (c *Constraint) AllowsNamespace(ns string) bool {
+	return c == nil || len(c.Namespaces) == 0 || slices.Contains(c.Namespaces, ns)
+}
+
+// GetNamespaces returns the namespace list or nil, safe to call with c == nil.
+func (c *Constraint) GetNamespaces() []string {
+	if c != nil {
+		return c.Namespaces
+	}
+	return nil
+}
+
+// Scope returns a copy of c with namespaces restricted to those in allowed.
+// If c has no namespaces, the copy allows all of allowed.
+// Returns false if none of the namespaces of c are in allowed.
+// Safe to call with c == nil.
+func (c *Constraint) Scope(allowed []string) (*Constraint, bool) {
+	var n Constraint
+	if c != nil {
+		n = *c
+	}
+	if len(n.Namespaces) == 0 {
+		n.Namespaces = slices.Clone(allowed)
+	} else {
+		n.Namespaces = slices.DeleteFunc(slices.Clone(n.Namespaces), func(ns string) bool { return !slices.Contains(allowed, ns) })
+	}
+	return &n, len(n.Namespaces) > 0
+}
+
 // Default values can be modified in init() or main(), but not after korrel8r functions are called.
 var (
 	// DefaultDuration is the global default duration for query constraints.
@@ -97,11 +161,14 @@ func (c *Constraint) MarshalLog() any {
 		Limit      *int
 		Timeout    *time.Duration
 		Start, End string
+		Namespaces []string `json:",omitempty"`
 	}{
 		Limit:   c.Limit,
 		Timeout: c.Timeout,
 		Start:   c.Start.Format(time.RFC3339Nano),
 		End:     c.End.Format(time.RFC3339Nano),
+
+		Namespaces: c.Namespaces,
 	}
 }
 
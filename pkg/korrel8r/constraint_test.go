@@ -20,3 +20,89 @@ func TestConstraint(t *testing.T) {
 	assert.Less(t, c.CompareTime(early), 0)
 	assert.Greater(t, c.CompareTime(late), 0)
 }
+
+func TestConstraint_Narrow(t *testing.T) {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	c := &Constraint{Start: &start, End: &end}
+	inside, after := start.Add(time.Minute), end.Add(time.Minute)
+
+	n, ok := c.Narrow(inside, after)
+	assert.True(t, ok)
+	assert.Equal(t, inside, *n.Start)
+	assert.Equal(t, end, *n.End)
+	assert.Equal(t, start, *c.Start, "original must not be modified")
+
+	n, ok = c.Narrow(time.Time{}, inside)
+	assert.True(t, ok)
+	assert.Equal(t, start, *n.Start)
+	assert.Equal(t, inside, *n.End)
+
+	n, ok = (*Constraint)(nil).Narrow(inside, time.Time{})
+	assert.True(t, ok)
+	assert.Equal(t, inside, *n.Start)
+	assert.Nil(t, n.End)
+
+	// Disjoint intervals have an empty intersection.
+	_, ok = c.Narrow(after, after.Add(time.Minute))
+	assert.False(t, ok, "interval after the constraint")
+	_, ok = c.Narrow(time.Time{}, start.Add(-time.Minute))
+	assert.False(t, ok, "interval before the constraint")
+	_, ok = c.Narrow(end, time.Time{})
+	assert.True(t, ok, "touching intervals intersect")
+}
+
+func TestConstraint_Overlaps(t *testing.T) {
+	start := time.Now()
+	end := start.Add(time.Hour)
+	c := &Constraint{Start: &start, End: &end}
+	before, inside, after := start.Add(-time.Minute), start.Add(time.Minute), end.Add(time.Minute)
+	for _, x := range []struct {
+		start, end time.Time
+		want       bool
+	}{
+		{inside, inside, true},
+		{before, inside, true},
+		{before, after, true},
+		{before, time.Time{}, true}, // Not finished.
+		{time.Time{}, time.Time{}, true},
+		{before, before, false},
+		{after, after, false},
+		{after, time.Time{}, false},
+	} {
+		assert.Equal(t, x.want, c.Overlaps(x.start, x.end), "%v", x)
+	}
+	assert.True(t, (*Constraint)(nil).Overlaps(before, before))
+}
+
+func TestConstraint_Namespaces(t *testing.T) {
+	assert.True(t, (*Constraint)(nil).AllowsNamespace("x"))
+	assert.True(t, (&Constraint{}).AllowsNamespace(""))
+	c := &Constraint{Namespaces: []string{"a", "b"}}
+	assert.True(t, c.AllowsNamespace("a"))
+	assert.False(t, c.AllowsNamespace("c"))
+	assert.False(t, c.AllowsNamespace(""), "objects without a namespace are excluded")
+
+	s, ok := c.Scope([]string{"b", "c"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"b"}, s.Namespaces)
+	assert.Equal(t, []string{"a", "b"}, c.Namespaces, "original must not be modified")
+	_, ok = c.Scope([]string{"c"})
+	assert.False(t, ok)
+	s, ok = (*Constraint)(nil).Scope([]string{"x"})
+	assert.True(t, ok)
+	assert.Equal(t, []string{"x"}, s.Namespaces)
+}
+
+// timeClass is a partial Class that uses time.Time objects as their own timestamp.
+type timeClass struct{ Class }
+
+func (timeClass) Timestamp(o Object) (time.Time, time.Time) { t, _ := o.(time.Time); return t, t }
+
+func TestSortByTime(t *testing.T) {
+	t0 := time.Now()
+	t1, t2 := t0.Add(time.Minute), t0.Add(time.Hour)
+	objects := []Object{t2, "unknown", t0, t1}
+	SortByTime(timeClass{}, objects)
+	assert.Equal(t, []Object{t0, t1, t2, "unknown"}, objects)
+}
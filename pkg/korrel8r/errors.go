@@ -3,9 +3,13 @@
 package korrel8r
 
 import (
+	"errors"
 	"fmt"
 )
 
+// ErrNotApplicable is returned by [Rule.Apply] when the rule does not apply to the start object.
+var ErrNotApplicable = errors.New("No query generated")
+
 type DomainNotFoundError struct{ Domain string }
 
 func (e DomainNotFoundError) Error() string { return fmt.Sprintf("domain not found: %q", e.Domain) }
@@ -26,3 +30,12 @@ type StoreNotFoundError struct {
 func (e StoreNotFoundError) Error() string {
 	return fmt.Sprintf("no stores found for domain %v", e.Domain)
 }
+
+// DeniedError is returned when the caller is not authorized to get the results of a query.
+// Searches report denied queries as partial results, not as failures.
+type DeniedError struct{ Reason string }
+
+func (e DeniedError) Error() string { return fmt.Sprintf("access denied: %v", e.Reason) }
+
+// IsDenied returns true if err contains a [DeniedError].
+func IsDenied(err error) bool { return errors.As(err, &DeniedError{}) }
@@ -9,6 +9,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
 )
 
 // Get and decode a REST response, for stores that use raw HTTP clients.
@@ -24,10 +26,14 @@ func Get[T any](ctx context.Context, u *url.URL, hc *http.Client, body T) (err e
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode/100 != 2 {
-		if b, err := io.ReadAll(resp.Body); err == nil && len(b) > 0 {
-			return fmt.Errorf("%v: %v", resp.Status, string(b))
+		err := fmt.Errorf("%v", resp.Status)
+		if b, rerr := io.ReadAll(resp.Body); rerr == nil && len(b) > 0 {
+			err = fmt.Errorf("%v: %v", resp.Status, string(b))
+		}
+		if resp.StatusCode == http.StatusForbidden {
+			return korrel8r.DeniedError{Reason: err.Error()}
 		}
-		return fmt.Errorf("%v", resp.Status)
+		return err
 	}
 	return json.NewDecoder(resp.Body).Decode(body)
 }
@@ -17,6 +17,9 @@ package korrel8r
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
+	"time"
 )
 
 // Domain is the entry-point to a package implementing a korrel8r domain.
@@ -114,6 +117,112 @@ type Previewer interface {
 	Preview(Object) string
 }
 
+// Severer is optionally implemented by Class implementations to report the error or warning status of an object.
+//
+// Severity is used to rank correlated objects, objects with problems are more likely to be relevant.
+type Severer interface {
+	Severity(Object) Severity
+}
+
+// Severity of problems reported by an object, see [Severer].
+type Severity int
+
+const (
+	SeverityNone    Severity = iota // No problem reported.
+	SeverityWarning                 // Warning status.
+	SeverityError                   // Error status.
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return ""
+	}
+}
+
+// SeverityOf returns the severity of an object if class is a [Severer], SeverityNone otherwise.
+func SeverityOf(class Class, o Object) Severity {
+	if s, ok := class.(Severer); ok {
+		return s.Severity(o)
+	}
+	return SeverityNone
+}
+
+// Timestamper is optionally implemented by Class implementations to report when an object happened.
+//
+// Timestamps are used to enforce the time interval of a [Constraint] consistently across stores,
+// to sort objects chronologically, and to build timelines.
+type Timestamper interface {
+	// Timestamp returns the time interval covered by an object.
+	// For an object at a single point in time, start and end are equal.
+	// A zero end means the interval is not finished, for example a resource that still exists.
+	// A zero start means the time of the object is not known.
+	Timestamp(Object) (start, end time.Time)
+}
+
+// TimestampOf returns the timestamp of an object if class is a [Timestamper], zero times otherwise.
+func TimestampOf(class Class, o Object) (start, end time.Time) {
+	if t, ok := class.(Timestamper); ok {
+		return t.Timestamp(o)
+	}
+	return time.Time{}, time.Time{}
+}
+
+// SortByTime sorts objects of class in chronological order of start time.
+// The sort is stable, objects with unknown times are sorted last.
+func SortByTime(class Class, objects []Object) {
+	t, ok := class.(Timestamper)
+	if !ok {
+		return
+	}
+	start := func(o Object) time.Time { s, _ := t.Timestamp(o); return s }
+	slices.SortStableFunc(objects, func(a, b Object) int {
+		ta, tb := start(a), start(b)
+		switch {
+		case ta.IsZero() && tb.IsZero():
+			return 0
+		case ta.IsZero():
+			return 1
+		case tb.IsZero():
+			return -1
+		}
+		return ta.Compare(tb)
+	})
+}
+
+// TemplateFuncer is optionally implemented by Domain implementations that provide functions for rule templates.
+//
+// Function names must be unique across all domains, by convention they start with the domain name.
+type TemplateFuncer interface {
+	TemplateFuncs() []TemplateFunc
+}
+
+// TemplateFunc describes a function that can be called from rule templates.
+type TemplateFunc struct {
+	// Name of the function in templates.
+	Name string `json:"name"`
+	// Func is the Go function value, see [text/template.FuncMap] for restrictions.
+	Func any `json:"-"`
+	// Description of the function arguments and result.
+	Description string `json:"description,omitempty"`
+	// Example of calling the function in a template.
+	Example string `json:"example,omitempty"`
+	// Source of the function: a domain name, or "engine" or "sprig". Set by the engine.
+	Source string `json:"source,omitempty"`
+}
+
+// Signature returns the Go signature of the function, for example: `func(string) string`.
+func (f TemplateFunc) Signature() string {
+	if f.Func == nil {
+		return ""
+	}
+	return reflect.TypeOf(f.Func).String()
+}
+
 // Appender gathers results from Store.Get calls.
 //
 // Not required for a domain implementations: implemented by [Result]
@@ -138,6 +247,7 @@ var _ Appender = AppenderFunc(nil) // AppenderFunc implements appender
 // Rules types must be comparable.
 type Rule interface {
 	// Apply the rule to a start Object, return a Query for results.
+	// Returns [ErrNotApplicable] if the rule does not apply to the start object.
 	Apply(start Object) (Query, error)
 	// Start returns a list of start classes that the rule can apply to, all in the same start domain.
 	Start() []Class
@@ -147,5 +257,14 @@ type Rule interface {
 	Name() string
 }
 
+// Constrainer is optionally implemented by [Rule] implementations that narrow the [Constraint] for their goal queries,
+// for example to a time window around the start object.
+type Constrainer interface {
+	// Constrain returns the constraint for the goal query generated by applying the rule to start.
+	// The result is derived from c, which may be nil, and must not modify c.
+	// Returns [ErrNotApplicable] if the narrowed time interval is empty, the goal query is skipped.
+	Constrain(start Object, c *Constraint) (*Constraint, error)
+}
+
 // NameSeparator used in DOMAIN:CLASS and DOMAIN:CLASS:QUERY strings.
 const NameSeparator = ":"
@@ -0,0 +1,247 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package report renders correlation results as a self-contained HTML document.
+//
+// The document needs no network access to view: it includes a graph of the result drawn as SVG,
+// a table of objects for each class, the queries that were run with their result counts,
+// and the errors encountered during the search.
+package report
+
+import (
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
+
+// Report is the input for an HTML report.
+type Report struct {
+	Title string
+	// Created is the time the report was created, defaults to the current time.
+	Created time.Time
+	// Start class of the search.
+	Start korrel8r.Class
+	// Graph is the result graph of the search.
+	Graph *graph.Graph
+	// Constraint used for the search, may be nil.
+	Constraint *korrel8r.Constraint
+	// Explanation of the search, may be nil. Failed steps are reported as errors.
+	Explanation *traverse.Explanation
+	// Err is the error returned by the search, may be nil.
+	Err error
+	// MaxObjects if > 0 is the maximum number of objects shown for each class.
+	MaxObjects int
+}
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportHTML))
+
+// Write the report as HTML to w.
+func (r *Report) Write(w io.Writer) error { return reportTemplate.Execute(w, r.page()) }
+
+// Layout of the SVG graph.
+const (
+	nodeWidth  = 200
+	nodeHeight = 40
+	columnGap  = 80
+	rowGap     = 30
+	margin     = 20
+	loopHeight = 60 // Height of edges that loop back over the nodes.
+)
+
+// page is the data for the HTML template.
+type page struct {
+	Title, Created, Start, Constraint string
+	Width, Height                     int
+	NodeWidth, NodeHeight             int
+	Nodes                             []pageNode
+	Edges                             []pageEdge
+	Errors                            []string
+}
+
+type pageNode struct {
+	ID, Class string
+	X, Y      int
+	Count     int
+	More      int // Number of objects not shown.
+	Queries   []pageQuery
+	Objects   []pageObject
+}
+
+type pageObject struct {
+	Time, Preview, JSON string
+}
+
+type pageEdge struct {
+	From, To           string // Node IDs
+	FromClass, ToClass string
+	Path               string // SVG path data.
+	Rules              []pageRule
+}
+
+type pageRule struct {
+	Name    string
+	Queries []pageQuery
+}
+
+type pageQuery struct {
+	Query string
+	Count int
+}
+
+func (r *Report) page() *page {
+	p := &page{
+		Title:   cmp.Or(r.Title, "Korrel8r Report"),
+		Created: cmp.Or(r.Created, time.Now()).Format(time.RFC3339),
+
+		NodeWidth:  nodeWidth,
+		NodeHeight: nodeHeight,
+	}
+	if r.Start != nil {
+		p.Start = r.Start.String()
+	}
+	if r.Constraint != nil {
+		b, _ := json.Marshal(r.Constraint)
+		p.Constraint = string(b)
+	}
+	g := r.Graph.NonEmpty()
+	nodes := map[int64]*pageNode{}
+	columns := r.columns(g)
+	rows := map[int]int{}
+	for _, n := range sortedNodes(g, columns) {
+		col := columns[n.ID()]
+		pn := &pageNode{
+			ID:      fmt.Sprintf("node-%v", n.ID()),
+			Class:   n.Class.String(),
+			X:       margin + col*(nodeWidth+columnGap),
+			Y:       margin + loopHeight + rows[col]*(nodeHeight+rowGap),
+			Queries: queries(n.Queries),
+		}
+		rows[col]++
+		p.Width = max(p.Width, pn.X+nodeWidth+margin)
+		p.Height = max(p.Height, pn.Y+nodeHeight+margin)
+		r.objects(pn, n)
+		nodes[n.ID()] = pn
+		p.Nodes = append(p.Nodes, *pn)
+	}
+	g.EachEdge(func(e *graph.Edge) {
+		from, to := nodes[e.Start().ID()], nodes[e.Goal().ID()]
+		pe := pageEdge{From: from.ID, To: to.ID, FromClass: from.Class, ToClass: to.Class}
+		if to.X > from.X { // Curve from the right side of start to the left side of goal.
+			x1, y1, x2, y2 := from.X+nodeWidth, from.Y+nodeHeight/2, to.X, to.Y+nodeHeight/2
+			pe.Path = fmt.Sprintf("M%v,%v C%v,%v %v,%v %v,%v", x1, y1, x1+columnGap/2, y1, x2-columnGap/2, y2, x2, y2)
+		} else { // Loop back over the top of the nodes.
+			x1, y1, x2, y2 := from.X+nodeWidth/3, from.Y, to.X+2*nodeWidth/3, to.Y
+			pe.Path = fmt.Sprintf("M%v,%v C%v,%v %v,%v %v,%v", x1, y1, x1, y1-loopHeight, x2, y2-loopHeight, x2, y2)
+		}
+		e.EachLine(func(l *graph.Line) {
+			pe.Rules = append(pe.Rules, pageRule{Name: l.Rule.Name(), Queries: queries(l.Queries)})
+		})
+		slices.SortFunc(pe.Rules, func(a, b pageRule) int { return cmp.Compare(a.Name, b.Name) })
+		p.Edges = append(p.Edges, pe)
+	})
+	slices.SortFunc(p.Edges, func(a, b pageEdge) int { return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To)) })
+	p.Errors = r.errors()
+	return p
+}
+
+// columns assigns each node to the column of its distance from the start.
+// Nodes that are not reachable from the start go in the last column.
+func (r *Report) columns(g *graph.Graph) map[int64]int {
+	columns := map[int64]int{}
+	last := 0
+	if r.Start != nil && g.Node(g.NodeFor(r.Start).ID()) != nil {
+		columns[g.NodeFor(r.Start).ID()] = 0
+		g.BreadthFirst(r.Start, graph.FuncVisitor{
+			LineF: func(l *graph.Line) bool {
+				if _, ok := columns[l.To().ID()]; !ok {
+					columns[l.To().ID()] = columns[l.From().ID()] + 1
+					last = max(last, columns[l.To().ID()])
+				}
+				return true
+			},
+		}, nil)
+	}
+	g.EachNode(func(n *graph.Node) {
+		if _, ok := columns[n.ID()]; !ok {
+			columns[n.ID()] = last + 1
+		}
+	})
+	return columns
+}
+
+// sortedNodes returns nodes sorted by column, then by class name.
+func sortedNodes(g *graph.Graph, columns map[int64]int) (nodes []*graph.Node) {
+	g.EachNode(func(n *graph.Node) { nodes = append(nodes, n) })
+	slices.SortFunc(nodes, func(a, b *graph.Node) int {
+		return cmp.Or(cmp.Compare(columns[a.ID()], columns[b.ID()]), cmp.Compare(a.Class.String(), b.Class.String()))
+	})
+	return nodes
+}
+
+// objects adds the objects of n to pn in chronological order.
+func (r *Report) objects(pn *pageNode, n *graph.Node) {
+	objects := slices.Clone(n.Result.List())
+	pn.Count = len(objects)
+	korrel8r.SortByTime(n.Class, objects)
+	if r.MaxObjects > 0 && len(objects) > r.MaxObjects {
+		pn.More = len(objects) - r.MaxObjects
+		objects = objects[:r.MaxObjects]
+	}
+	previewer, _ := n.Class.(korrel8r.Previewer)
+	for _, o := range objects {
+		var po pageObject
+		if start, _ := korrel8r.TimestampOf(n.Class, o); !start.IsZero() {
+			po.Time = start.Format(time.RFC3339)
+		}
+		if previewer != nil {
+			po.Preview = previewer.Preview(o)
+		}
+		if b, err := json.MarshalIndent(o, "", "  "); err == nil {
+			po.JSON = string(b)
+		} else {
+			po.JSON = fmt.Sprint(o)
+		}
+		pn.Objects = append(pn.Objects, po)
+	}
+}
+
+// errors returns the search error and the errors of explanation steps.
+func (r *Report) errors() (errs []string) {
+	if r.Err != nil {
+		errs = append(errs, r.Err.Error())
+	}
+	for _, s := range r.Explanation.Steps() {
+		if s.Err == nil || s.Outcome == traverse.NotApplicable {
+			continue
+		}
+		msg := fmt.Sprintf("rule %v", s.Rule.Name())
+		if s.Object != "" {
+			msg += fmt.Sprintf(", start %v", s.Object)
+		}
+		if s.Query != nil {
+			msg += fmt.Sprintf(", query %v", s.Query)
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", msg, s.Err))
+	}
+	return slices.Compact(errs)
+}
+
+// queries returns query counts sorted by descending count, then query string.
+func queries(qs graph.Queries) (pqs []pageQuery) {
+	for _, qc := range qs {
+		pqs = append(pqs, pageQuery{Query: qc.Query.String(), Count: qc.Count})
+	}
+	slices.SortFunc(pqs, func(a, b pageQuery) int { return cmp.Or(-cmp.Compare(a.Count, b.Count), cmp.Compare(a.Query, b.Query)) })
+	return pqs
+}
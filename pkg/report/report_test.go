@@ -0,0 +1,85 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// class is a mock class where an int object N has time base+N minutes.
+type class struct{ mock.Class }
+
+func (c class) Timestamp(o korrel8r.Object) (time.Time, time.Time) {
+	t := base.Add(time.Duration(o.(int)) * time.Minute)
+	return t, t
+}
+
+func (c class) Preview(o korrel8r.Object) string { return fmt.Sprintf("object %v", o) }
+
+func TestReport_Write(t *testing.T) {
+	d := mock.Domain("mock")
+	s, a := class{d.Class("s").(mock.Class)}, class{d.Class("a").(mock.Class)}
+	z := d.Class("z")
+	list := func(c ...korrel8r.Class) []korrel8r.Class { return c }
+	sa := mock.NewRule("sa", list(s), list(a), mock.NewQuery(a, "a-query"))
+	sz := mock.NewRule("sz", list(s), list(z), mock.NewQuery(z, "z-query"))
+	g := graph.NewData(sa, sz).FullGraph()
+	g.NodeFor(s).Result.Append(0)
+	g.NodeFor(a).Result.Append(5, 2, 7)
+	q, _ := sa.Apply(nil)
+	g.NodeFor(a).Queries.Set(q, 3)
+	g.EachLine(func(l *graph.Line) {
+		if l.Rule == sa {
+			l.Queries.Set(q, 3)
+		}
+	})
+
+	var b bytes.Buffer
+	r := &Report{
+		Title:      "Test <Report>",
+		Created:    base,
+		Start:      s,
+		Graph:      g,
+		Err:        errors.New("partial result"),
+		MaxObjects: 2,
+	}
+	require.NoError(t, r.Write(&b))
+	html := b.String()
+	for _, want := range []string{
+		"<title>Test &lt;Report&gt;</title>",
+		"<dd>2024-01-01T00:00:00Z</dd>",
+		`<text x="20" y="80" dx="10" dy="25">mock:s (1)</text>`,
+		`<text x="300" y="80" dx="10" dy="25">mock:a (3)</text>`,
+		`<path d="M220,100 C260,100 260,100 300,100" marker-end="url(#arrow)"/>`,
+		"<title>sa</title>",
+		"<td>sa</td>",
+		"<code>mock:a:a-query</code></td><td>3</td>",
+		"<tr><td>2024-01-01T00:02:00Z</td><td>object 2<details>",
+		"<tr><td>2024-01-01T00:05:00Z</td><td>object 5<details>",
+		"1 more objects not shown.",
+		"<li><code>partial result</code></li>",
+	} {
+		assert.Contains(t, html, want)
+	}
+	assert.NotContains(t, html, "object 7", "beyond MaxObjects")
+	assert.NotContains(t, html, "mock:z", "empty node")
+}
+
+func TestReport_Write_empty(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, (&Report{Graph: graph.NewData().EmptyGraph()}).Write(&b))
+	assert.Contains(t, b.String(), "<p>No results.</p>")
+	assert.Contains(t, b.String(), "<p>No rules were followed.</p>")
+}
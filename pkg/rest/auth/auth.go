@@ -1,11 +1,19 @@
 // Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
 
 // package auth forwards authorization information from an incoming REST request to an outgoing store request.
+//
+// By default the caller's Authorization header is copied to store requests, stores apply the caller's permissions.
+//
+// Alternatively a server can authenticate callers with an [Authenticator] and use its own credentials for stores.
+// Store access is restricted by impersonating the caller (see [WithUser]),
+// or by checking a SubjectAccessReview before each store query (see [AccessReview]).
 package auth
 
 import (
 	"context"
 	"net/http"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
 )
 
 // Context returns an authorization-forwarding context for an incoming request.
@@ -14,6 +22,19 @@ func Context(req *http.Request) context.Context {
 	return context.WithValue(req.Context(), authKey{}, auth)
 }
 
+// WithUser returns a context for store requests made on behalf of an authenticated user.
+// The user's credentials are not forwarded, store requests use the server's own credentials.
+// If impersonate is true, store requests impersonate the user with Kubernetes impersonation headers.
+func WithUser(ctx context.Context, u *User, impersonate bool) context.Context {
+	return context.WithValue(ctx, userKey{}, userValue{user: u, impersonate: impersonate})
+}
+
+// UserFrom returns the user attached to a context by [WithUser], or nil.
+func UserFrom(ctx context.Context) *User {
+	v, _ := ctx.Value(userKey{}).(userValue)
+	return v.user
+}
+
 // Wrap adds authorization-forwarding for outgoing requests with an authorization-forwarding context.
 func Wrap(next http.RoundTripper) http.RoundTripper {
 	return &roundTripper{next: next}
@@ -23,7 +44,15 @@ type roundTripper struct{ next http.RoundTripper }
 
 func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	ctx := req.Context()
-	if auth, ok := ctx.Value(authKey{}).(string); ok {
+	if v, ok := ctx.Value(userKey{}).(userValue); ok {
+		if v.impersonate && v.user != nil {
+			req.Header.Set(authenticationv1.ImpersonateUserHeader, v.user.Name)
+			req.Header.Del(authenticationv1.ImpersonateGroupHeader)
+			for _, g := range v.user.Groups {
+				req.Header.Add(authenticationv1.ImpersonateGroupHeader, g)
+			}
+		}
+	} else if auth, ok := ctx.Value(authKey{}).(string); ok {
 		req.Header.Set(authorization, auth)
 	}
 	return rt.next.RoundTrip(req)
@@ -31,4 +60,11 @@ func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 
 type authKey struct{}
 
+type userKey struct{}
+
+type userValue struct {
+	user        *User
+	impersonate bool
+}
+
 const authorization = "Authorization"
@@ -9,6 +9,7 @@ import (
 
 	"github.com/korrel8r/korrel8r/pkg/rest/auth"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type dummyRoundTripper struct{ *http.Request }
@@ -40,3 +41,38 @@ func Test_Context_RoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func Test_WithUser_RoundTrip(t *testing.T) {
+	drt := dummyRoundTripper{}
+	rt := auth.Wrap(&drt)
+	in := &http.Request{Header: http.Header{"Authorization": []string{"Bearer user-token"}}}
+	user := &auth.User{Name: "alice", Groups: []string{"a", "b"}}
+	for _, impersonate := range []bool{false, true} {
+		t.Run(fmt.Sprintf("impersonate=%v", impersonate), func(t *testing.T) {
+			ctx := auth.WithUser(auth.Context(in), user, impersonate)
+			assert.Equal(t, user, auth.UserFrom(ctx))
+			out, err := http.NewRequestWithContext(ctx, "GET", "/", nil)
+			require.NoError(t, err)
+			out.Header.Set("Authorization", "Bearer server-token")
+			_, _ = rt.RoundTrip(out)
+			assert.Equal(t, "Bearer server-token", drt.Request.Header.Get("Authorization"), "must not forward user token")
+			if impersonate {
+				assert.Equal(t, "alice", drt.Request.Header.Get("Impersonate-User"))
+				assert.Equal(t, []string{"a", "b"}, drt.Request.Header.Values("Impersonate-Group"))
+			} else {
+				assert.Empty(t, drt.Request.Header.Get("Impersonate-User"))
+			}
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	for _, x := range []struct{ header, want string }{
+		{"Bearer abc", "abc"},
+		{"bearer abc", "abc"},
+		{"Basic abc", ""},
+		{"", ""},
+	} {
+		assert.Equal(t, x.want, auth.BearerToken(&http.Request{Header: http.Header{"Authorization": []string{x.header}}}), x.header)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// User is an authenticated caller.
+type User struct {
+	Name   string   `json:"name"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Authenticator identifies the user presenting a bearer token.
+type Authenticator interface {
+	// Authenticate returns the user for token, or an error if the token is not valid.
+	Authenticate(ctx context.Context, token string) (*User, error)
+}
+
+// ErrNoToken is returned when a request has no bearer token.
+var ErrNoToken = errors.New("no bearer token")
+
+// BearerToken returns the bearer token from the Authorization header of a request, or "" if there is none.
+func BearerToken(req *http.Request) string {
+	scheme, token, _ := strings.Cut(req.Header.Get(authorization), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// TokenReview authenticates bearer tokens with the Kubernetes TokenReview API.
+type TokenReview struct {
+	// Client used to create reviews, it needs permission to create TokenReviews.
+	Client client.Client
+	// Audiences the token must be issued for, optional.
+	Audiences []string
+}
+
+var _ Authenticator = &TokenReview{}
+
+func (t *TokenReview) Authenticate(ctx context.Context, token string) (*User, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token, Audiences: t.Audiences}}
+	if err := t.Client.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("token review failed: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return nil, fmt.Errorf("token not authenticated: %v", review.Status.Error)
+	}
+	return &User{Name: review.Status.User.Username, Groups: review.Status.User.Groups}, nil
+}
@@ -0,0 +1,147 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AccessReview authorizes store queries for the user attached to a context by [WithUser],
+// using the Kubernetes SubjectAccessReview API. It implements engine.Authorizer.
+//
+// A query with a namespace constraint is reviewed for each namespace and narrowed to the allowed namespaces.
+// A query without a namespace constraint is reviewed for all namespaces.
+// Contexts without a user are not checked.
+type AccessReview struct {
+	// Client used to create reviews, it needs permission to create SubjectAccessReviews.
+	Client client.Client
+	// Attributes returns the attributes to review for a query in a namespace, default [DefaultAttributes].
+	Attributes func(q korrel8r.Query, namespace string) authorizationv1.ResourceAttributes
+	// TTL is the time to cache review results, default 1 minute.
+	TTL time.Duration
+
+	lock  sync.Mutex
+	cache map[string]review
+}
+
+type review struct {
+	allowed bool
+	expires time.Time
+}
+
+// DefaultAttributes reviews the "get" verb for a virtual resource in API group "korrel8r.io".
+// The resource is the domain name of the query, the resource name is the class name.
+//
+// For example, this RBAC rule allows getting all log classes:
+//
+//	apiGroups: ["korrel8r.io"]
+//	resources: ["log"]
+//	verbs: ["get"]
+func DefaultAttributes(q korrel8r.Query, namespace string) authorizationv1.ResourceAttributes {
+	return authorizationv1.ResourceAttributes{
+		Group:     "korrel8r.io",
+		Resource:  q.Class().Domain().Name(),
+		Name:      q.Class().Name(),
+		Namespace: namespace,
+		Verb:      "get",
+	}
+}
+
+func (a *AccessReview) Authorize(ctx context.Context, q korrel8r.Query, c *korrel8r.Constraint) (*korrel8r.Constraint, error) {
+	user := UserFrom(ctx)
+	if user == nil {
+		return c, nil
+	}
+	namespaces := c.GetNamespaces()
+	if len(namespaces) == 0 {
+		namespaces = []string{""} // All namespaces.
+	}
+	attrs := a.Attributes
+	if attrs == nil {
+		attrs = DefaultAttributes
+	}
+	var allowed []string
+	for _, ns := range namespaces {
+		ok, err := a.allowed(ctx, user, attrs(q, ns))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, ns)
+		}
+	}
+	switch {
+	case len(allowed) == 0:
+		return nil, korrel8r.DeniedError{Reason: fmt.Sprintf("user %q may not get %v", user.Name, q.Class())}
+	case len(c.GetNamespaces()) == 0:
+		return c, nil
+	default:
+		c, _ = c.Scope(allowed)
+		return c, nil
+	}
+}
+
+// AuthorizeEdit authorizes a request to edit the configuration for the user attached by [WithUser].
+// It reviews a virtual resource "config" in API group "korrel8r.io",
+// with verb "create", "update" or "delete" for HTTP methods POST, PUT or DELETE.
+// Requests without a user are denied.
+//
+// For example, this RBAC rule allows all configuration edits:
+//
+//	apiGroups: ["korrel8r.io"]
+//	resources: ["config"]
+//	verbs: ["create", "update", "delete"]
+func (a *AccessReview) AuthorizeEdit(req *http.Request) error {
+	verb := map[string]string{http.MethodPost: "create", http.MethodPut: "update", http.MethodDelete: "delete"}[req.Method]
+	user := UserFrom(req.Context())
+	if user == nil || verb == "" {
+		return korrel8r.DeniedError{Reason: "configuration edit not allowed"}
+	}
+	ok, err := a.allowed(req.Context(), user, authorizationv1.ResourceAttributes{Group: "korrel8r.io", Resource: "config", Verb: verb})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return korrel8r.DeniedError{Reason: fmt.Sprintf("user %q may not %v config", user.Name, verb)}
+	}
+	return nil
+}
+
+// allowed reviews access for user to resource attributes ra, using a cached result if there is one.
+func (a *AccessReview) allowed(ctx context.Context, user *User, ra authorizationv1.ResourceAttributes) (bool, error) {
+	key := fmt.Sprintf("%v|%v|%+v", user.Name, strings.Join(user.Groups, ","), ra)
+	now := time.Now()
+	a.lock.Lock()
+	r, ok := a.cache[key]
+	a.lock.Unlock()
+	if ok && now.Before(r.expires) {
+		return r.allowed, nil
+	}
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{User: user.Name, Groups: user.Groups, ResourceAttributes: &ra},
+	}
+	if err := a.Client.Create(ctx, sar); err != nil {
+		return false, fmt.Errorf("access review failed: %w", err)
+	}
+	ttl := a.TTL
+	if ttl == 0 {
+		ttl = time.Minute
+	}
+	r = review{allowed: sar.Status.Allowed && !sar.Status.Denied, expires: now.Add(ttl)}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.cache == nil {
+		a.cache = map[string]review{}
+	}
+	a.cache[key] = r
+	return r.allowed, nil
+}
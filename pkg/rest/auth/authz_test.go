@@ -0,0 +1,112 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/rest/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// fakeClient returns a client that answers reviews with the review function.
+func fakeClient(review func(obj client.Object)) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			review(obj)
+			return nil
+		},
+	}).Build()
+}
+
+func TestTokenReview(t *testing.T) {
+	tr := &auth.TokenReview{Client: fakeClient(func(obj client.Object) {
+		r := obj.(*authenticationv1.TokenReview)
+		if r.Spec.Token == "good" {
+			r.Status.Authenticated = true
+			r.Status.User = authenticationv1.UserInfo{Username: "alice", Groups: []string{"dev"}}
+		} else {
+			r.Status.Error = "bad token"
+		}
+	})}
+	u, err := tr.Authenticate(context.Background(), "good")
+	require.NoError(t, err)
+	assert.Equal(t, &auth.User{Name: "alice", Groups: []string{"dev"}}, u)
+	_, err = tr.Authenticate(context.Background(), "bad")
+	assert.ErrorContains(t, err, "bad token")
+	_, err = tr.Authenticate(context.Background(), "")
+	assert.ErrorIs(t, err, auth.ErrNoToken)
+}
+
+func TestAccessReview(t *testing.T) {
+	reviews := 0
+	ar := &auth.AccessReview{Client: fakeClient(func(obj client.Object) {
+		reviews++
+		r := obj.(*authorizationv1.SubjectAccessReview)
+		ra := r.Spec.ResourceAttributes
+		// alice may get mock:a in namespaces x and y, admins may get anything anywhere.
+		r.Status.Allowed = slices.Contains(r.Spec.Groups, "admin") ||
+			r.Spec.User == "alice" && ra.Group == "korrel8r.io" && ra.Resource == "mock" && ra.Name == "a" && (ra.Namespace == "x" || ra.Namespace == "y")
+	})}
+	q := mock.NewQuery(mock.Domain("mock").Class("a"), "q")
+	alice := auth.WithUser(context.Background(), &auth.User{Name: "alice"}, false)
+	admin := auth.WithUser(context.Background(), &auth.User{Name: "bob", Groups: []string{"admin"}}, false)
+
+	c, err := ar.Authorize(alice, q, &korrel8r.Constraint{Namespaces: []string{"x", "y", "z"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, c.Namespaces, "narrowed to allowed namespaces")
+
+	_, err = ar.Authorize(alice, q, &korrel8r.Constraint{Namespaces: []string{"z"}})
+	assert.True(t, korrel8r.IsDenied(err), "%v", err)
+	_, err = ar.Authorize(alice, q, nil)
+	assert.True(t, korrel8r.IsDenied(err), "%v", err)
+	_, err = ar.Authorize(alice, mock.NewQuery(mock.Domain("mock").Class("b"), "q"), &korrel8r.Constraint{Namespaces: []string{"x"}})
+	assert.True(t, korrel8r.IsDenied(err), "%v", err)
+
+	c, err = ar.Authorize(admin, q, nil)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	c, err = ar.Authorize(context.Background(), q, nil)
+	require.NoError(t, err, "no user, not checked")
+	assert.Nil(t, c)
+
+	n := reviews
+	_, _ = ar.Authorize(alice, q, &korrel8r.Constraint{Namespaces: []string{"x", "z"}})
+	assert.Equal(t, n, reviews, "results are cached")
+}
+
+func TestAccessReview_AuthorizeEdit(t *testing.T) {
+	ar := &auth.AccessReview{Client: fakeClient(func(obj client.Object) {
+		r := obj.(*authorizationv1.SubjectAccessReview)
+		ra := r.Spec.ResourceAttributes
+		// alice may update the configuration, but not create or delete.
+		r.Status.Allowed = r.Spec.User == "alice" && ra.Group == "korrel8r.io" && ra.Resource == "config" && ra.Verb == "update"
+	})}
+	edit := func(user *auth.User, method string) error {
+		req := httptest.NewRequest(method, "/api/v1alpha1/config/rules", nil)
+		if user != nil {
+			req = req.WithContext(auth.WithUser(req.Context(), user, false))
+		}
+		return ar.AuthorizeEdit(req)
+	}
+	alice := &auth.User{Name: "alice"}
+	assert.NoError(t, edit(alice, http.MethodPut))
+	assert.True(t, korrel8r.IsDenied(edit(alice, http.MethodPost)))
+	assert.True(t, korrel8r.IsDenied(edit(alice, http.MethodGet)))
+	assert.True(t, korrel8r.IsDenied(edit(&auth.User{Name: "bob"}, http.MethodPut)))
+	assert.True(t, korrel8r.IsDenied(edit(nil, http.MethodPut)), "no user, denied")
+}
@@ -0,0 +1,167 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package auth
+
+import (
+	"cmp"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// OIDC authenticates JSON Web Tokens issued by an OpenID Connect provider.
+//
+// Token signatures are verified with a local JSON Web Key Set, there is no connection to the provider.
+// Supported signature algorithms are RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384 and ES512.
+// Tokens with critical header parameters are rejected.
+type OIDC struct {
+	// Issuer is the required value of the "iss" claim.
+	Issuer string
+	// Audience if not empty must be one of the values of the "aud" claim.
+	Audience string
+	// UsernameClaim is the claim for the user name, default "sub".
+	UsernameClaim string
+	// GroupsClaim is the claim for the user's groups, default "groups".
+	GroupsClaim string
+	// Keys to verify token signatures.
+	Keys *JWKS
+	// Leeway allowed for clock skew when checking token times, default 1 minute.
+	Leeway time.Duration
+}
+
+var _ Authenticator = &OIDC{}
+
+// signatureAlgorithms accepted for tokens.
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512, jose.ES256, jose.ES384, jose.ES512,
+}
+
+func (o *OIDC) Authenticate(_ context.Context, token string) (*User, error) {
+	if token == "" {
+		return nil, ErrNoToken
+	}
+	tok, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %w", err)
+	}
+	key, err := o.Keys.key(tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+	var std jwt.Claims
+	var claims map[string]any
+	if err := tok.Claims(key, &std, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if std.Expiry == nil {
+		return nil, errors.New("token has no expiry")
+	}
+	expected := jwt.Expected{Issuer: o.Issuer, Time: time.Now()}
+	if o.Audience != "" {
+		expected.AnyAudience = jwt.Audience{o.Audience}
+	}
+	if err := std.ValidateWithLeeway(expected, cmp.Or(o.Leeway, jwt.DefaultLeeway)); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	name, _ := claims[cmp.Or(o.UsernameClaim, "sub")].(string)
+	if name == "" {
+		return nil, fmt.Errorf("token has no user name claim %q", cmp.Or(o.UsernameClaim, "sub"))
+	}
+	return &User{Name: name, Groups: stringsClaim(claims[cmp.Or(o.GroupsClaim, "groups")])}, nil
+}
+
+// JWKS is a JSON Web Key Set of public keys, indexed by key ID.
+type JWKS struct {
+	keys map[string]any
+}
+
+// MinRSAKeyBits is the minimum size of RSA keys in a [JWKS].
+const MinRSAKeyBits = 2048
+
+// LoadJWKS loads a JSON Web Key Set from a file.
+func LoadJWKS(file string) (*JWKS, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON Web Key Set. RSA and EC public keys are supported, other keys are ignored.
+// RSA keys smaller than [MinRSAKeyBits] are rejected.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	// Decode keys one at a time, so unsupported key types can be skipped.
+	var set struct{ Keys []json.RawMessage }
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+	ks := &JWKS{keys: map[string]any{}}
+	for _, raw := range set.Keys {
+		var kty struct{ Kty, Kid string }
+		if err := json.Unmarshal(raw, &kty); err != nil {
+			return nil, fmt.Errorf("invalid JWKS: %w", err)
+		}
+		if kty.Kty != "RSA" && kty.Kty != "EC" {
+			continue
+		}
+		var k jose.JSONWebKey
+		if err := k.UnmarshalJSON(raw); err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", kty.Kid, err)
+		}
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch key := k.Key.(type) {
+		case *rsa.PublicKey:
+			if key.N.BitLen() < MinRSAKeyBits {
+				return nil, fmt.Errorf("invalid JWKS key %q: RSA key size %v is less than %v", k.KeyID, key.N.BitLen(), MinRSAKeyBits)
+			}
+		case *ecdsa.PublicKey:
+		default:
+			return nil, fmt.Errorf("invalid JWKS key %q: not a public key", k.KeyID)
+		}
+		ks.keys[k.KeyID] = k.Key
+	}
+	if len(ks.keys) == 0 {
+		return nil, errors.New("invalid JWKS: no usable keys")
+	}
+	return ks, nil
+}
+
+// key returns the key for a key ID. A token without a key ID can use the only key in a single-key set.
+func (ks *JWKS) key(kid string) (any, error) {
+	if key, ok := ks.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown token key ID: %q", kid)
+}
+
+// stringsClaim returns a claim that may be a string or a list of strings as a list.
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var list []string
+		for _, x := range v {
+			if s, ok := x.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
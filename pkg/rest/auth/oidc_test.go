@@ -0,0 +1,140 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/rest/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var b64 = base64.RawURLEncoding
+
+// signer creates test tokens.
+type signer struct {
+	kid, alg string
+	sign     func(digest []byte) []byte
+	header   map[string]any // Extra header parameters.
+}
+
+func (s signer) token(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header := map[string]any{"alg": s.alg, "kid": s.kid, "typ": "JWT"}
+	maps.Copy(header, s.header)
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	c, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signed := b64.EncodeToString(h) + "." + b64.EncodeToString(c)
+	digest := sha256.Sum256([]byte(signed))
+	return signed + "." + b64.EncodeToString(s.sign(digest[:]))
+}
+
+func newKeys(t *testing.T) (rsaSigner, ecSigner signer, jwks *auth.JWKS) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaSigner = signer{kid: "rsa", alg: "RS256", sign: func(digest []byte) []byte {
+		sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest)
+		require.NoError(t, err)
+		return sig
+	}}
+	ecSigner = signer{kid: "ec", alg: "ES256", sign: func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest)
+		require.NoError(t, err)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}}
+	set := fmt.Sprintf(`{"keys": [
+{"kty": "RSA", "kid": "rsa", "use": "sig", "n": %q, "e": %q},
+{"kty": "EC", "kid": "ec", "crv": "P-256", "x": %q, "y": %q},
+{"kty": "oct", "kid": "ignored", "k": "c2VjcmV0"}
+]}`,
+		b64.EncodeToString(rsaKey.N.Bytes()), b64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64.EncodeToString(ecKey.X.Bytes()), b64.EncodeToString(ecKey.Y.Bytes()))
+	jwks, err = auth.ParseJWKS([]byte(set))
+	require.NoError(t, err)
+	return rsaSigner, ecSigner, jwks
+}
+
+func TestOIDC(t *testing.T) {
+	rsaSigner, ecSigner, jwks := newKeys(t)
+	o := &auth.OIDC{Issuer: "https://issuer", Audience: "korrel8r", Keys: jwks}
+	exp := time.Now().Add(time.Hour).Unix()
+	claims := func(changes ...any) map[string]any {
+		c := map[string]any{"iss": "https://issuer", "aud": []string{"korrel8r", "other"}, "sub": "alice", "groups": []string{"dev"}, "exp": exp}
+		for i := 0; i < len(changes); i += 2 {
+			c[changes[i].(string)] = changes[i+1]
+		}
+		return c
+	}
+	extraHeader := signer{kid: "rsa", alg: "RS256", sign: rsaSigner.sign, header: map[string]any{"exp": 1}}
+	for _, s := range []signer{rsaSigner, ecSigner, extraHeader} {
+		t.Run(s.alg, func(t *testing.T) {
+			u, err := o.Authenticate(context.Background(), s.token(t, claims()))
+			require.NoError(t, err)
+			assert.Equal(t, &auth.User{Name: "alice", Groups: []string{"dev"}}, u)
+		})
+	}
+	for _, x := range []struct {
+		name  string
+		token string
+	}{
+		{"no token", ""},
+		{"malformed", "not.a-token"},
+		{"expired", rsaSigner.token(t, claims("exp", time.Now().Add(-2*time.Minute).Unix()))},
+		{"no expiry", rsaSigner.token(t, claims("exp", nil))},
+		{"not yet valid", rsaSigner.token(t, claims("nbf", time.Now().Add(time.Hour).Unix()))},
+		{"wrong issuer", rsaSigner.token(t, claims("iss", "https://other"))},
+		{"wrong audience", rsaSigner.token(t, claims("aud", "other"))},
+		{"no user", rsaSigner.token(t, claims("sub", ""))},
+		{"unknown key", signer{kid: "x", alg: "RS256", sign: rsaSigner.sign}.token(t, claims())},
+		{"wrong key", signer{kid: "ec", alg: "RS256", sign: rsaSigner.sign}.token(t, claims())},
+		{"bad signature", rsaSigner.token(t, claims())[:100] + "x" + rsaSigner.token(t, claims())[101:]},
+		{"critical header", signer{kid: "rsa", alg: "RS256", sign: rsaSigner.sign, header: map[string]any{"crit": []string{"exp"}, "exp": 1}}.token(t, claims())},
+		{"unsigned", signer{kid: "rsa", alg: "none", sign: func([]byte) []byte { return nil }}.token(t, claims())},
+		{"symmetric", signer{kid: "rsa", alg: "HS256", sign: rsaSigner.sign}.token(t, claims())},
+	} {
+		t.Run(x.name, func(t *testing.T) {
+			_, err := o.Authenticate(context.Background(), x.token)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOIDC_leeway(t *testing.T) {
+	rsaSigner, _, jwks := newKeys(t)
+	o := &auth.OIDC{Issuer: "https://issuer", Keys: jwks}
+	claims := map[string]any{"iss": "https://issuer", "sub": "alice", "exp": time.Now().Add(-10 * time.Second).Unix()}
+	_, err := o.Authenticate(context.Background(), rsaSigner.token(t, claims))
+	assert.NoError(t, err, "expired within the default leeway")
+	o.Leeway = time.Second
+	_, err = o.Authenticate(context.Background(), rsaSigner.token(t, claims))
+	assert.Error(t, err)
+}
+
+func TestParseJWKS_error(t *testing.T) {
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	small := fmt.Sprintf(`{"keys": [{"kty": "RSA", "kid": "small", "n": %q, "e": "AQAB"}]}`, b64.EncodeToString(smallKey.N.Bytes()))
+	for _, data := range []string{`not json`, `{"keys": []}`, `{"keys": [{"kty": "EC", "crv": "P-0"}]}`, small} {
+		_, err := auth.ParseJWKS([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
@@ -3,10 +3,16 @@
 package rest
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/korrel8r/korrel8r/internal/pkg/logging"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"sigs.k8s.io/yaml"
 )
 
 // Config settings that can be modified at runtime via the API.
@@ -32,3 +38,307 @@ func (a *API) PutConfig(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, config)
 }
+
+// editConfig rejects configuration edits unless they are enabled by [API.EditConfig] and authorized.
+func (a *API) editConfig(c *gin.Context) {
+	if a.EditConfig == nil {
+		check(c, http.StatusForbidden, errors.New("configuration editing is not enabled"))
+		return
+	}
+	if check(c, http.StatusForbidden, a.EditConfig(c.Request)) {
+		c.Next()
+	}
+}
+
+// ConfigRules handler
+//
+//	@router		/config/rules [get]
+//	@summary	List rules in the configuration.
+//	@success	200		{array}		ConfigRule
+//	@failure	default	{object}	any
+func (a *API) ConfigRules(c *gin.Context) { listConfig(a, c, rules) }
+
+// ConfigRulesCreate handler
+//
+//	@router		/config/rules [post]
+//	@summary	Add a rule to the configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		rule	body		ConfigRule	true	"rule to add"
+//	@success	201		{object}	ConfigRule
+//	@failure	default	{object}	any
+func (a *API) ConfigRulesCreate(c *gin.Context) { createConfig(a, c, rules) }
+
+// ConfigRulesUpdate handler
+//
+//	@router		/config/rules/{name} [put]
+//	@summary	Replace a rule in the configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		name	path		string		true	"rule name"
+//	@param		rule	body		ConfigRule	true	"new rule"
+//	@success	200		{object}	ConfigRule
+//	@failure	default	{object}	any
+func (a *API) ConfigRulesUpdate(c *gin.Context) { updateConfig(a, c, rules) }
+
+// ConfigRulesDelete handler
+//
+//	@router		/config/rules/{name} [delete]
+//	@summary	Delete a rule from the configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		name	path	string	true	"rule name"
+//	@success	204
+//	@failure	default	{object}	any
+func (a *API) ConfigRulesDelete(c *gin.Context) { deleteConfig(a, c, rules) }
+
+// ConfigAliases handler
+//
+//	@router		/config/aliases [get]
+//	@summary	List class aliases in the configuration.
+//	@success	200		{array}		ConfigAlias
+//	@failure	default	{object}	any
+func (a *API) ConfigAliases(c *gin.Context) { listConfig(a, c, aliases) }
+
+// ConfigAliasesCreate handler
+//
+//	@router		/config/aliases [post]
+//	@summary	Add a class alias to the configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		alias	body		ConfigAlias	true	"alias to add"
+//	@success	201		{object}	ConfigAlias
+//	@failure	default	{object}	any
+func (a *API) ConfigAliasesCreate(c *gin.Context) { createConfig(a, c, aliases) }
+
+// ConfigAliasesUpdate handler
+//
+//	@router		/config/aliases/{domain}/{name} [put]
+//	@summary	Replace a class alias in the configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		domain	path		string		true	"alias domain"
+//	@param		name	path		string		true	"alias name"
+//	@param		alias	body		ConfigAlias	true	"new alias"
+//	@success	200		{object}	ConfigAlias
+//	@failure	default	{object}	any
+func (a *API) ConfigAliasesUpdate(c *gin.Context) { updateConfig(a, c, aliases) }
+
+// ConfigAliasesDelete handler
+//
+//	@router		/config/aliases/{domain}/{name} [delete]
+//	@summary	Delete a class alias from the configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		domain	path	string	true	"alias domain"
+//	@param		name	path	string	true	"alias name"
+//	@success	204
+//	@failure	default	{object}	any
+func (a *API) ConfigAliasesDelete(c *gin.Context) { deleteConfig(a, c, aliases) }
+
+// ConfigStores handler
+//
+//	@router		/config/stores [get]
+//	@summary	List store configurations. The position in the list is the index for update and delete.
+//	@description	New stores are added at the end of the list. Deleting a store changes the index of the stores after it.
+//	@success	200		{array}		Store
+//	@failure	default	{object}	any
+func (a *API) ConfigStores(c *gin.Context) { listConfig(a, c, stores) }
+
+// ConfigStoresCreate handler
+//
+//	@router		/config/stores [post]
+//	@summary	Add a store configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		store	body		Store	true	"store to add"
+//	@success	201		{object}	Store
+//	@failure	default	{object}	any
+func (a *API) ConfigStoresCreate(c *gin.Context) { createConfig(a, c, stores) }
+
+// ConfigStoresUpdate handler
+//
+//	@router		/config/stores/{index} [put]
+//	@summary	Replace a store configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		index	path		int		true	"index in store list"
+//	@param		store	body		Store	true	"new store"
+//	@success	200		{object}	Store
+//	@failure	default	{object}	any
+func (a *API) ConfigStoresUpdate(c *gin.Context) { updateConfig(a, c, stores) }
+
+// ConfigStoresDelete handler
+//
+//	@router		/config/stores/{index} [delete]
+//	@summary	Delete a store configuration, creates a new engine.
+//	@description	Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).
+//	@param		index	path	int	true	"index in store list"
+//	@success	204
+//	@failure	default	{object}	any
+func (a *API) ConfigStoresDelete(c *gin.Context) { deleteConfig(a, c, stores) }
+
+// ConfigExport handler
+//
+//	@router		/config/export [get]
+//	@summary	Export the effective configuration as a single YAML document.
+//	@produce	application/yaml
+//	@success	200		{string}	string
+//	@failure	default	{object}	any
+func (a *API) ConfigExport(c *gin.Context) {
+	configs := a.current(c).Configs
+	var export config.Config
+	for i, cfg := range configs {
+		if i == 0 {
+			export.Tuning = cfg.Tuning
+		}
+		export.Rules = append(export.Rules, cfg.Rules...)
+		export.Aliases = append(export.Aliases, cfg.Aliases...)
+		export.Stores = append(export.Stores, cfg.Stores...)
+	}
+	b, err := yaml.Marshal(export)
+	if !check(c, http.StatusInternalServerError, err) {
+		return
+	}
+	c.Data(http.StatusOK, "application/yaml", b)
+}
+
+// configList is a list of items in the configuration that can be modified via the API.
+type configList[T any] struct {
+	// list returns the list in a Config.
+	list func(*config.Config) *[]T
+	// key for an item, index is the position of the item in the list of all items.
+	key func(index int, v T) string
+	// param returns the key from the request path.
+	param func(c *gin.Context) string
+}
+
+var (
+	rules = configList[config.Rule]{
+		list:  func(c *config.Config) *[]config.Rule { return &c.Rules },
+		key:   func(_ int, r config.Rule) string { return r.Name },
+		param: func(c *gin.Context) string { return c.Param("name") },
+	}
+	aliases = configList[config.Class]{
+		list:  func(c *config.Config) *[]config.Class { return &c.Aliases },
+		key:   func(_ int, a config.Class) string { return a.Domain + "/" + a.Name },
+		param: func(c *gin.Context) string { return c.Param("domain") + "/" + c.Param("name") },
+	}
+	stores = configList[config.Store]{
+		list:  func(c *config.Config) *[]config.Store { return &c.Stores },
+		key:   func(i int, _ config.Store) string { return strconv.Itoa(i) },
+		param: func(c *gin.Context) string { return c.Param("index") },
+	}
+)
+
+// find returns the list containing the item with key, the index of the item in that list,
+// and the index of the item in the list of all items.
+func (l configList[T]) find(configs config.Configs, key string) (list *[]T, index int, all int) {
+	n := 0
+	for i := range configs {
+		list := l.list(&configs[i])
+		for j, v := range *list {
+			if l.key(n, v) == key {
+				return list, j, n
+			}
+			n++
+		}
+	}
+	return nil, -1, -1
+}
+
+func (l configList[T]) all(configs config.Configs) []T {
+	all := []T{} // return [] not null for empty
+	for i := range configs {
+		all = append(all, *l.list(&configs[i])...)
+	}
+	return all
+}
+
+func listConfig[T any](a *API, c *gin.Context, l configList[T]) {
+	c.JSON(http.StatusOK, l.all(a.current(c).Configs))
+}
+
+// createConfig adds a new item after the last item in the list of all items.
+// The item goes in the last configuration that has items of its kind, or the first (top level) configuration if none do.
+func createConfig[T any](a *API, c *gin.Context, l configList[T]) {
+	var v T
+	if !check(c, http.StatusBadRequest, c.BindJSON(&v)) {
+		return
+	}
+	a.edit(c, func(configs config.Configs) (int, error) {
+		key := l.key(len(l.all(configs)), v)
+		if list, _, _ := l.find(configs, key); list != nil {
+			return http.StatusConflict, fmt.Errorf("already exists: %v", key)
+		}
+		list := l.list(&configs[0])
+		for i := len(configs) - 1; i > 0; i-- {
+			if last := l.list(&configs[i]); len(*last) > 0 {
+				list = last
+				break
+			}
+		}
+		*list = append(*list, v)
+		return http.StatusCreated, nil
+	})
+	if !c.IsAborted() {
+		c.JSON(http.StatusCreated, v)
+	}
+}
+
+func updateConfig[T any](a *API, c *gin.Context, l configList[T]) {
+	var v T
+	if !check(c, http.StatusBadRequest, c.BindJSON(&v)) {
+		return
+	}
+	a.edit(c, func(configs config.Configs) (int, error) {
+		key := l.param(c)
+		list, i, n := l.find(configs, key)
+		if list == nil {
+			return http.StatusNotFound, fmt.Errorf("not found: %v", key)
+		}
+		if k := l.key(n, v); k != key {
+			if other, _, _ := l.find(configs, k); other != nil {
+				return http.StatusConflict, fmt.Errorf("already exists: %v", k)
+			}
+		}
+		(*list)[i] = v
+		return http.StatusOK, nil
+	})
+	if !c.IsAborted() {
+		c.JSON(http.StatusOK, v)
+	}
+}
+
+func deleteConfig[T any](a *API, c *gin.Context, l configList[T]) {
+	a.edit(c, func(configs config.Configs) (int, error) {
+		key := l.param(c)
+		list, i, _ := l.find(configs, key)
+		if list == nil {
+			return http.StatusNotFound, fmt.Errorf("not found: %v", key)
+		}
+		*list = slices.Delete(*list, i, i+1)
+		return http.StatusNoContent, nil
+	})
+	if !c.IsAborted() {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// edit applies change to a copy of the current configuration.
+// If change succeeds and the new configuration builds a valid engine, the API is updated.
+// Otherwise the request is aborted with the returned status code, and the API is not changed.
+//
+// Changes are only held in memory. They are lost on restart, or if configuration files are re-loaded.
+// Use /config/export to save the modified configuration.
+func (a *API) edit(c *gin.Context, change func(config.Configs) (int, error)) {
+	a.editLock.Lock()
+	defer a.editLock.Unlock()
+	s := a.state.Load()
+	configs := s.Configs.Clone()
+	if len(configs) == 0 {
+		configs = config.Configs{{}}
+	}
+	code, err := change(configs)
+	if !check(c, code, err) {
+		return
+	}
+	e, err := a.buildEngine(configs)
+	if !check(c, http.StatusBadRequest, err) {
+		return
+	}
+	a.update(e, configs) // Re-apply request limits from the tuning section.
+	log.V(1).Info("REST: Configuration changed", "method", c.Request.Method, "url", c.Request.URL)
+}
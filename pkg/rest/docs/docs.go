@@ -50,6 +50,356 @@ const docTemplate = `{
                 }
             }
         },
+        "/config/aliases": {
+            "get": {
+                "summary": "List class aliases in the configuration.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ConfigAlias"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Add a class alias to the configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "description": "alias to add",
+                        "name": "alias",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ConfigAlias"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ConfigAlias"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/config/aliases/{domain}/{name}": {
+            "put": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Replace a class alias in the configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "alias domain",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "alias name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new alias",
+                        "name": "alias",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ConfigAlias"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ConfigAlias"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Delete a class alias from the configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "alias domain",
+                        "name": "domain",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "alias name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/config/export": {
+            "get": {
+                "produces": [
+                    "application/yaml"
+                ],
+                "summary": "Export the effective configuration as a single YAML document.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/config/rules": {
+            "get": {
+                "summary": "List rules in the configuration.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/ConfigRule"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Add a rule to the configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "description": "rule to add",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ConfigRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/ConfigRule"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/config/rules/{name}": {
+            "put": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Replace a rule in the configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rule name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new rule",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ConfigRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ConfigRule"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Delete a rule from the configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rule name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/config/stores": {
+            "get": {
+                "description": "New stores are added at the end of the list. Deleting a store changes the index of the stores after it.",
+                "summary": "List store configurations. The position in the list is the index for update and delete.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Store"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Add a store configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "description": "store to add",
+                        "name": "store",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Store"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/Store"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/config/stores/{index}": {
+            "put": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Replace a store configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "index in store list",
+                        "name": "index",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "new store",
+                        "name": "store",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Store"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Store"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Requires configuration editing to be enabled and authorized on the server, otherwise fails with 403 (Forbidden).",
+                "summary": "Delete a store configuration, creates a new engine.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "index in store list",
+                        "name": "index",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
         "/domains": {
             "get": {
                 "summary": "Get name, configuration and status for each domain.",
@@ -98,6 +448,13 @@ const docTemplate = `{
         },
         "/graphs/goals": {
             "post": {
+                "produces": [
+                    "application/json",
+                    "text/vnd.graphviz",
+                    "text/vnd.mermaid",
+                    "application/vnd.cytoscape+json",
+                    "application/graphml+xml"
+                ],
                 "summary": "Create a correlation graph from start objects to goal queries.",
                 "parameters": [
                     {
@@ -106,6 +463,18 @@ const docTemplate = `{
                         "name": "rules",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "description": "include up to this many of the most relevant objects in each node",
+                        "name": "ranked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include an explanation of each rule applied",
+                        "name": "explain",
+                        "in": "query"
+                    },
                     {
                         "description": "search from start to goal classes",
                         "name": "request",
@@ -136,8 +505,62 @@ const docTemplate = `{
                 }
             }
         },
+        "/graphs/multi": {
+            "post": {
+                "description": "Searches from each start, and reports intersections: classes and objects reached from more than one start.\nConstraints must be set on the request, not on individual starts.\nEach start counts as one search against the server limit on searches in progress.",
+                "summary": "Create correlation graphs from multiple starts, and find what they have in common.",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "include rules in graph edges",
+                        "name": "rules",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "include up to this many of the most relevant objects in each node",
+                        "name": "ranked",
+                        "in": "query"
+                    },
+                    {
+                        "description": "search from multiple starts",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/MultiStart"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/MultiGraph"
+                        }
+                    },
+                    "206": {
+                        "description": "interrupted, partial result",
+                        "schema": {
+                            "$ref": "#/definitions/MultiGraph"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
         "/graphs/neighbours": {
             "post": {
+                "produces": [
+                    "application/json",
+                    "text/vnd.graphviz",
+                    "text/vnd.mermaid",
+                    "application/vnd.cytoscape+json",
+                    "application/graphml+xml"
+                ],
                 "summary": "Create a neighbourhood graph around a start object to a given depth.",
                 "parameters": [
                     {
@@ -146,6 +569,18 @@ const docTemplate = `{
                         "name": "rules",
                         "in": "query"
                     },
+                    {
+                        "type": "integer",
+                        "description": "include up to this many of the most relevant objects in each node",
+                        "name": "ranked",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "include an explanation of each rule applied",
+                        "name": "explain",
+                        "in": "query"
+                    },
                     {
                         "description": "search from neighbours",
                         "name": "request",
@@ -176,17 +611,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/lists/goals": {
+        "/graphs/rules": {
+            "get": {
+                "produces": [
+                    "application/json",
+                    "text/vnd.graphviz",
+                    "text/vnd.mermaid",
+                    "application/vnd.cytoscape+json",
+                    "application/graphml+xml"
+                ],
+                "summary": "Get the graph of all classes and rules.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Graph"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/lists/goals": {
+            "post": {
+                "summary": "Create a list of goal nodes related to a starting point.",
+                "parameters": [
+                    {
+                        "description": "search from start to goal classes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Goals"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Node"
+                            }
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/objects": {
+            "get": {
+                "summary": "Execute a query, returns a list of JSON objects in chronological order if they have timestamps.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "query string",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {}
+                        }
+                    },
+                    "206": {
+                        "description": "access denied, partial result",
+                        "schema": {
+                            "type": "array",
+                            "items": {}
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/timelines": {
             "post": {
-                "summary": "Create a list of goal nodes related to a starting point.",
+                "description": "Searches from start to goals, or to neighbours if there are no goals.\nObjects with unknown times are at the end of the timeline.",
+                "summary": "Create a timeline: all correlated objects in chronological order.",
                 "parameters": [
                     {
-                        "description": "search from start to goal classes",
+                        "description": "search for timeline",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/Goals"
+                            "$ref": "#/definitions/Timelines"
                         }
                     }
                 ],
@@ -196,35 +721,17 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/Node"
+                                "$ref": "#/definitions/TimelineEntry"
                             }
                         }
                     },
-                    "default": {
-                        "description": "",
-                        "schema": {}
-                    }
-                }
-            }
-        },
-        "/objects": {
-            "get": {
-                "summary": "Execute a query, returns a list of JSON objects.",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "query string",
-                        "name": "query",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "206": {
+                        "description": "interrupted, partial result",
                         "schema": {
                             "type": "array",
-                            "items": {}
+                            "items": {
+                                "$ref": "#/definitions/TimelineEntry"
+                            }
                         }
                     },
                     "default": {
@@ -243,6 +750,65 @@ const docTemplate = `{
                 "type": "string"
             }
         },
+        "ConfigAlias": {
+            "description": "ConfigAlias is a class alias in the korrel8r configuration.",
+            "type": "object",
+            "properties": {
+                "classes": {
+                    "description": "Classes are the names of classes in this group.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "domain": {
+                    "description": "Domain of the classes, all must be in the same domain.",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the short name for a group of classes.",
+                    "type": "string"
+                }
+            }
+        },
+        "ConfigRule": {
+            "description": "ConfigRule is a rule in the korrel8r configuration.",
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "Cost of following this rule, multiplied by the cost of the goal domain.\nGoal searches follow the lowest-cost paths. If omitted, the cost is 1.",
+                    "type": "number"
+                },
+                "goal": {
+                    "description": "Goal specifies the set of classes that this rule can produce.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.ClassSpec"
+                        }
+                    ]
+                },
+                "name": {
+                    "description": "Name is a short, descriptive name.\nIf omitted, a name is generated from Start and Goal.",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "TemplateResult contains templates to generate the result of applying this rule.\nEach template is applied to an object from one of the ` + "`" + `start` + "`" + ` classes.\nIf any template yields a blank string or an error, the rule does not apply.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.ResultSpec"
+                        }
+                    ]
+                },
+                "start": {
+                    "description": "Start specifies the set of classes that this rule can apply to.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.ClassSpec"
+                        }
+                    ]
+                }
+            }
+        },
         "Constraint": {
             "description": "Constraint constrains the objects that will be included in search results.",
             "type": "object",
@@ -257,6 +823,13 @@ const docTemplate = `{
                     "description": "Limit number of objects returned per query, \u003c=0 means no limit.",
                     "type": "integer"
                 },
+                "namespaces": {
+                    "description": "Namespaces if not empty restricts results to objects in one of the listed namespaces.\nObjects that do not belong to any namespace are excluded.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "start": {
                     "description": "Start of time interval, quoted RFC 3339 format.",
                     "type": "string",
@@ -323,6 +896,10 @@ const docTemplate = `{
                         "domain:class"
                     ]
                 },
+                "maxCost": {
+                    "description": "MaxCost if \u003e 0 excludes goals where the lowest-cost path from start costs more than MaxCost.",
+                    "type": "number"
+                },
                 "start": {
                     "$ref": "#/definitions/Start"
                 }
@@ -338,6 +915,13 @@ const docTemplate = `{
                         "$ref": "#/definitions/Edge"
                     }
                 },
+                "explain": {
+                    "description": "Explain lists the rules applied to each start object, if requested.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Step"
+                    }
+                },
                 "nodes": {
                     "type": "array",
                     "items": {
@@ -346,6 +930,82 @@ const docTemplate = `{
                 }
             }
         },
+        "Intersection": {
+            "description": "Intersection is a class with results from more than one start.",
+            "type": "object",
+            "properties": {
+                "class": {
+                    "description": "Class is the full class name in \"DOMAIN:CLASS\" form.",
+                    "type": "string",
+                    "example": "domain:class"
+                },
+                "count": {
+                    "description": "Count of objects reached from more than one start.",
+                    "type": "integer"
+                },
+                "objects": {
+                    "description": "Objects reached from more than one start.",
+                    "type": "array",
+                    "items": {}
+                },
+                "starts": {
+                    "description": "Starts are the indices of the starts that reached this class.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "MultiGraph": {
+            "description": "MultiGraph results from a search from multiple starts.",
+            "type": "object",
+            "properties": {
+                "graphs": {
+                    "description": "Graphs for each start, in the same order as the starts.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Graph"
+                    }
+                },
+                "intersections": {
+                    "description": "Intersections are the classes and objects reached from more than one start.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Intersection"
+                    }
+                }
+            }
+        },
+        "MultiStart": {
+            "description": "Multiple starting points for a search.",
+            "type": "object",
+            "properties": {
+                "constraint": {
+                    "$ref": "#/definitions/Constraint"
+                },
+                "depth": {
+                    "description": "Max depth of neighbours search.",
+                    "type": "integer"
+                },
+                "goals": {
+                    "description": "Goal classes for correlation.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "domain:class"
+                    ]
+                },
+                "starts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Start"
+                    }
+                }
+            }
+        },
         "Neighbours": {
             "description": "Starting point for a neighbours search.",
             "type": "object",
@@ -378,6 +1038,13 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/QueryCount"
                     }
+                },
+                "ranked": {
+                    "description": "Ranked objects of this class with relevance scores, most relevant first. Only included if requested.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Ranked"
+                    }
                 }
             }
         },
@@ -395,6 +1062,40 @@ const docTemplate = `{
                 }
             }
         },
+        "Ranked": {
+            "description": "Ranked is a correlated object with its relevance score.",
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "description": "Distance is the number of rules followed from the start class, -1 if not reachable.",
+                    "type": "integer"
+                },
+                "object": {
+                    "description": "Object serialized as JSON."
+                },
+                "paths": {
+                    "description": "Paths is the number of independent queries that returned the object.",
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "Score is the relevance score, higher is more relevant.",
+                    "type": "number"
+                },
+                "severity": {
+                    "description": "Severity of problems reported by the object, if any.",
+                    "type": "string",
+                    "enum": [
+                        "warning",
+                        "error"
+                    ]
+                },
+                "timeDelta": {
+                    "description": "TimeDelta is the time difference to the nearest start object, if known.",
+                    "type": "string",
+                    "example": "2m30s"
+                }
+            }
+        },
         "Rule": {
             "description": "Rule is a correlation rule with a list of queries and results counts found during navigation.",
             "type": "object",
@@ -436,12 +1137,163 @@ const docTemplate = `{
                 }
             }
         },
+        "Step": {
+            "description": "Step explains the outcome of applying a rule to a start object during a search.",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count of results for the query, or -1 if the query was not executed.",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "Error applying the rule or executing the query.",
+                    "type": "string"
+                },
+                "goal": {
+                    "description": "Goal is the class of the generated query, empty if no query was generated.",
+                    "type": "string",
+                    "example": "domain:class"
+                },
+                "object": {
+                    "description": "Object is the ID or a preview of the start object, may be empty.",
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "Outcome of applying the rule.",
+                    "type": "string",
+                    "enum": [
+                        "applied",
+                        "notApplicable",
+                        "error"
+                    ]
+                },
+                "query": {
+                    "description": "Query generated by the rule.",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "Rule is the name of the rule applied.",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "Start is the class of the start object.",
+                    "type": "string",
+                    "example": "domain:class"
+                }
+            }
+        },
         "Store": {
             "description": "Store is a map of name:value attributes used to connect to a store.",
             "type": "object",
             "additionalProperties": {
                 "type": "string"
             }
+        },
+        "TimelineEntry": {
+            "description": "TimelineEntry is a correlated object on a timeline.",
+            "type": "object",
+            "properties": {
+                "class": {
+                    "description": "Class is the full class name in \"DOMAIN:CLASS\" form.",
+                    "type": "string",
+                    "example": "domain:class"
+                },
+                "end": {
+                    "description": "End time of the object, omitted if it is the same as Start or not known.",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
+                },
+                "object": {
+                    "description": "Object serialized as JSON."
+                },
+                "path": {
+                    "description": "Path is the list of rule names followed from the start class to this object's class.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "preview": {
+                    "description": "Preview is a short description of the object, may be empty.",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "Start time of the object, omitted if not known.",
+                    "type": "string",
+                    "format": "date-time",
+                    "x-nullable": true
+                }
+            }
+        },
+        "Timelines": {
+            "description": "Timelines requests a timeline of correlated objects.",
+            "type": "object",
+            "properties": {
+                "depth": {
+                    "description": "Max depth of neighbours search.",
+                    "type": "integer"
+                },
+                "goals": {
+                    "description": "Goal classes for correlation.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "domain:class"
+                    ]
+                },
+                "start": {
+                    "$ref": "#/definitions/Start"
+                }
+            }
+        },
+        "config.ClassSpec": {
+            "type": "object",
+            "properties": {
+                "classes": {
+                    "description": "Classes is a list of class names to be selected from the domain.\nIf absent, all classes in the domain are selected.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "domain": {
+                    "description": "Domain is the domain for selected classes.",
+                    "type": "string"
+                }
+            }
+        },
+        "config.ConstraintSpec": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "description": "End template generates the end of the time interval.",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "Start template generates the start of the time interval.",
+                    "type": "string"
+                }
+            }
+        },
+        "config.ResultSpec": {
+            "type": "object",
+            "properties": {
+                "constraint": {
+                    "description": "Constraint templates narrow the constraint for the goal query, optional.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/config.ConstraintSpec"
+                        }
+                    ]
+                },
+                "query": {
+                    "description": "Query template generates a query object suitable for the goal store.",
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -7,7 +7,11 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
 	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/score"
+	"github.com/korrel8r/korrel8r/pkg/timeline"
 )
 
 func queryCounts(gq graph.Queries) []QueryCount {
@@ -85,6 +89,10 @@ func Normalize(v any) any {
 	case Graph:
 		Normalize(v.Nodes)
 		Normalize(v.Edges)
+	case MultiGraph:
+		for _, g := range v.Graphs {
+			Normalize(g)
+		}
 	case []Node:
 		slices.SortFunc(v, func(a, b Node) int { return strings.Compare(a.Class, b.Class) })
 		for _, n := range v {
@@ -113,7 +121,95 @@ func Normalize(v any) any {
 	return v
 }
 
+// NewRuleGraph returns a rest.Graph with all the classes and rules of a rule graph.
+func NewRuleGraph(g *graph.Graph) *Graph {
+	gr := &Graph{Nodes: []Node{}, Edges: []Edge{}}
+	g.EachNode(func(n *graph.Node) { gr.Nodes = append(gr.Nodes, Node{Class: n.Class.String()}) })
+	g.EachEdge(func(e *graph.Edge) {
+		edge := Edge{Start: e.Start().Class.String(), Goal: e.Goal().Class.String()}
+		e.EachLine(func(l *graph.Line) { edge.Rules = append(edge.Rules, Rule{Name: l.Rule.Name()}) })
+		slices.SortFunc(edge.Rules, func(a, b Rule) int { return strings.Compare(a.Name, b.Name) })
+		gr.Edges = append(gr.Edges, edge)
+	})
+	Normalize(*gr)
+	return gr
+}
+
 // NewGraph returns a new rest.Graph corresponding to the internal graph.Graph.
 func NewGraph(g *graph.Graph) *Graph {
 	return &Graph{Nodes: nodes(g), Edges: edges(g, &Options{})}
 }
+
+// NewSteps returns rest.Steps corresponding to the steps of an explanation.
+func NewSteps(x *traverse.Explanation) []Step {
+	var steps []Step
+	for _, s := range x.Steps() {
+		step := Step{
+			Rule:    s.Rule.Name(),
+			Start:   s.Start.String(),
+			Object:  s.Object,
+			Outcome: string(s.Outcome),
+			Count:   s.Count,
+		}
+		if s.Query != nil {
+			step.Goal, step.Query = s.Goal().String(), s.Query.String()
+		}
+		if s.Err != nil {
+			step.Error = s.Err.Error()
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// RankNodes adds up to n ranked objects to each node of gr, where g is the result graph of a search from start.
+func RankNodes(gr *Graph, g *graph.Graph, start korrel8r.Class, n int) {
+	if n <= 0 || g == nil || start == nil {
+		return
+	}
+	scorer := score.New(g, start)
+	byClass := map[string]*graph.Node{}
+	g.EachNode(func(n *graph.Node) { byClass[n.Class.String()] = n })
+	for i := range gr.Nodes {
+		gn := byClass[gr.Nodes[i].Class]
+		if gn == nil {
+			continue
+		}
+		scored := scorer.Rank(gn)
+		for _, s := range scored[:min(n, len(scored))] {
+			r := Ranked{
+				Object:   s.Object,
+				Score:    s.Score.Total,
+				Distance: s.Score.Distance,
+				Paths:    s.Score.Paths,
+				Severity: s.Score.Severity.String(),
+			}
+			if s.Score.TimeDelta != nil {
+				r.TimeDelta = s.Score.TimeDelta.String()
+			}
+			gr.Nodes[i].Ranked = append(gr.Nodes[i].Ranked, r)
+		}
+	}
+}
+
+// NewTimeline returns the timeline of objects in g, a result graph of a search from start.
+func NewTimeline(g *graph.Graph, start korrel8r.Class) []TimelineEntry {
+	entries := []TimelineEntry{} // Want [] not null for empty in JSON.
+	if g == nil || start == nil {
+		return entries
+	}
+	for _, e := range timeline.New(g, start) {
+		te := TimelineEntry{Class: e.Class.String(), Preview: e.Preview, Object: e.Object}
+		if !e.Start.IsZero() {
+			te.Start = &e.Start
+			if !e.End.IsZero() && !e.End.Equal(e.Start) {
+				te.End = &e.End
+			}
+		}
+		for _, r := range e.Path {
+			te.Path = append(te.Path, r.Name())
+		}
+		entries = append(entries, te)
+	}
+	return entries
+}
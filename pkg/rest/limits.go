@@ -0,0 +1,179 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/rest/auth"
+	"golang.org/x/time/rate"
+)
+
+// maxIdleClients is the number of per-client limiters kept before idle ones are discarded.
+const maxIdleClients = 1000
+
+// limits are the request admission limits from the tuning configuration.
+// A nil *limits admits all requests.
+type limits struct {
+	global *rate.Limiter // Global request rate, nil if unlimited.
+	client *config.Rate  // Per-client request rate, nil if unlimited.
+
+	lock    sync.Mutex
+	clients map[string]*rate.Limiter
+}
+
+func newLimits(t *config.Tuning) *limits {
+	if t == nil || t.RequestRate == nil && t.ClientRequestRate == nil {
+		return nil
+	}
+	l := &limits{client: t.ClientRequestRate, clients: map[string]*rate.Limiter{}}
+	if r := t.RequestRate; r != nil {
+		l.global = rate.NewLimiter(rate.Limit(r.QPS), r.GetBurst())
+	}
+	return l
+}
+
+// allow reserves a request for client.
+// Returns 0 if the request is allowed, or the time to wait before retrying if it is not.
+func (l *limits) allow(client string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	limiters := []*rate.Limiter{l.global, l.clientLimiter(client)}
+	now := time.Now()
+	var reserved []*rate.Reservation
+	for _, lim := range limiters {
+		if lim == nil {
+			continue
+		}
+		r := lim.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); delay > 0 {
+			// Over the limit, give back all reservations.
+			r.CancelAt(now)
+			for _, r := range reserved {
+				r.CancelAt(now)
+			}
+			return delay
+		}
+		reserved = append(reserved, r)
+	}
+	return 0
+}
+
+// clientLimiter returns the limiter for client, or nil if there is no per-client limit.
+func (l *limits) clientLimiter(client string) *rate.Limiter {
+	if l.client == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	lim := l.clients[client]
+	if lim == nil {
+		if len(l.clients) >= maxIdleClients {
+			// A limiter with a full bucket is idle, the client is indistinguishable from a new one.
+			for k, v := range l.clients {
+				if v.Tokens() >= float64(v.Burst()) {
+					delete(l.clients, k)
+				}
+			}
+		}
+		lim = rate.NewLimiter(rate.Limit(l.client.QPS), l.client.GetBurst())
+		l.clients[client] = lim
+	}
+	return lim
+}
+
+// traversals counts traversals in progress.
+// It is kept across configuration changes, so traversals started before a change
+// count against the new limit.
+type traversals struct {
+	lock       sync.Mutex
+	max, count int // max <= 0 is unlimited.
+}
+
+// setMax changes the limit, traversals in progress are not affected.
+func (t *traversals) setMax(n int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.max = n
+}
+
+// limit returns the maximum number of traversals, 0 if unlimited.
+func (t *traversals) limit() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return max(t.max, 0)
+}
+
+// start returns false if starting n more traversals would exceed the limit.
+// Otherwise it returns true and a function to call when the traversals are done.
+func (t *traversals) start(n int) (done func(), ok bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.max > 0 && t.count+n > t.max {
+		return nil, false
+	}
+	t.count += n
+	return sync.OnceFunc(func() {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		t.count -= n
+	}), true
+}
+
+// clientID identifies the client of a request by authenticated user name, or by IP address.
+func clientID(c *gin.Context) string {
+	if u := auth.UserFrom(c.Request.Context()); u != nil {
+		return "user:" + u.Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// limit rejects API requests that exceed the request rate limits.
+func (a *API) limit(c *gin.Context) {
+	if strings.HasPrefix(c.Request.URL.Path, BasePath) {
+		if wait := a.current(c).limits.allow(clientID(c)); wait > 0 {
+			tooManyRequests(c, wait, errors.New("request rate limit exceeded"))
+			return
+		}
+	}
+	c.Next()
+}
+
+// traversal rejects correlation searches if too many are already in progress.
+func (a *API) traversal(c *gin.Context) {
+	if done := a.startTraversals(c, 1); done != nil {
+		defer done()
+		c.Next()
+	}
+}
+
+// startTraversals starts n traversals, or aborts the request and returns nil if there are too many.
+// Requests for more traversals than the limit can never succeed, they are rejected as bad requests.
+func (a *API) startTraversals(c *gin.Context, n int) (done func()) {
+	if limit := a.traversals.limit(); limit > 0 && n > limit {
+		check(c, http.StatusBadRequest, fmt.Errorf("too many searches in one request: %v, limit is %v", n, limit))
+		return nil
+	}
+	done, ok := a.traversals.start(n)
+	if !ok {
+		tooManyRequests(c, time.Second, errors.New("too many searches in progress"))
+		return nil
+	}
+	return done
+}
+
+// tooManyRequests aborts with HTTP 429 and a Retry-After header.
+func tooManyRequests(c *gin.Context, retry time.Duration, err error) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, c.Error(err).JSON())
+}
@@ -0,0 +1,38 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package rest
+
+import (
+	"cmp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/korrel8r/korrel8r/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// REST metrics are labeled by HTTP method and operation, the route path of the request.
+var (
+	requests = metrics.Factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "rest",
+		Name:      "requests_total",
+		Help:      "REST requests by method, operation and HTTP status code.",
+	}, []string{"method", "operation", "code"})
+	requestDuration = metrics.Factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "rest",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of REST requests.",
+	}, []string{"method", "operation"})
+)
+
+// metrics is a Gin handler to record request metrics.
+func (a *API) metrics(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	operation := cmp.Or(c.FullPath(), "unknown") // Use the route, not the URL, to limit label values.
+	requests.WithLabelValues(c.Request.Method, operation, strconv.Itoa(c.Writer.Status())).Inc()
+	requestDuration.WithLabelValues(c.Request.Method, operation).Observe(time.Since(start).Seconds())
+}
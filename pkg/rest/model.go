@@ -4,6 +4,7 @@ package rest
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/korrel8r"
@@ -16,6 +17,12 @@ import (
 // @description Store is a map of name:value attributes used to connect to a store.
 type Store = config.Store // @name Store
 
+// @description ConfigRule is a rule in the korrel8r configuration.
+type ConfigRule = config.Rule // @name ConfigRule
+
+// @description ConfigAlias is a class alias in the korrel8r configuration.
+type ConfigAlias = config.Class // @name ConfigAlias
+
 // @description Constraint constrains the objects that will be included in search results.
 type Constraint = korrel8r.Constraint // @name Constraint
 
@@ -48,6 +55,8 @@ type Start struct {
 type Goals struct {
 	Start Start    `json:"start"`
 	Goals []string `json:"goals,omitempty" example:"domain:class"` // Goal classes for correlation.
+	// MaxCost if > 0 excludes goals where the lowest-cost path from start costs more than MaxCost.
+	MaxCost float64 `json:"maxCost,omitempty"`
 } // @name Goals
 
 // @description	Starting point for a neighbours search.
@@ -57,9 +66,28 @@ type Neighbours struct {
 
 } // @name Neighbours
 
+// @description	Multiple starting points for a search.
+// If Goals is not empty, a goal search is done from each start, otherwise a neighbours search to Depth.
+type MultiStart struct {
+	Starts     []Start     `json:"starts"`
+	Goals      []string    `json:"goals,omitempty" example:"domain:class"` // Goal classes for correlation.
+	Depth      int         `json:"depth,omitempty"`                        // Max depth of neighbours search.
+	Constraint *Constraint `json:"constraint,omitempty"`
+} // @name MultiStart
+
+// @description	Timelines requests a timeline of correlated objects.
+// If Goals is not empty, a goal search is done from start, otherwise a neighbours search to Depth.
+type Timelines struct {
+	Start Start    `json:"start"`
+	Goals []string `json:"goals,omitempty" example:"domain:class"` // Goal classes for correlation.
+	Depth int      `json:"depth,omitempty"`                        // Max depth of neighbours search.
+} // @name Timelines
+
 // @description Options control the format of the graph
 type Options struct {
-	Rules bool `form:"rules"` // Rules if true include rules in the graph edges.
+	Rules   bool `form:"rules"`   // Rules if true include rules in the graph edges.
+	Explain bool `form:"explain"` // Explain if true include an explanation of each rule applied.
+	Ranked  int  `form:"ranked"`  // Ranked if > 0 include up to this many of the most relevant objects in each node.
 } // @name GraphOptions
 
 // @description Objects requests objects corresponding to a query.
@@ -91,8 +119,26 @@ type Node struct {
 	Queries []QueryCount `json:"queries,omitempty"`
 	// Count of results found for this class, after de-duplication.
 	Count int `json:"count"`
+	// Ranked objects of this class with relevance scores, most relevant first. Only included if requested.
+	Ranked []Ranked `json:"ranked,omitempty"`
 } // @name Node
 
+// @description Ranked is a correlated object with its relevance score.
+type Ranked struct {
+	// Object serialized as JSON.
+	Object any `json:"object"`
+	// Score is the relevance score, higher is more relevant.
+	Score float64 `json:"score"`
+	// Distance is the number of rules followed from the start class, -1 if not reachable.
+	Distance int `json:"distance"`
+	// Paths is the number of independent queries that returned the object.
+	Paths int `json:"paths"`
+	// TimeDelta is the time difference to the nearest start object, if known.
+	TimeDelta string `json:"timeDelta,omitempty" example:"2m30s"`
+	// Severity of problems reported by the object, if any.
+	Severity string `json:"severity,omitempty" enums:"warning,error"`
+} // @name Ranked
+
 // @description Directed edge in the result graph, from Start to Goal classes.
 type Edge struct {
 	// Start is the class name of the start node.
@@ -103,8 +149,66 @@ type Edge struct {
 	Rules []Rule `json:"rules,omitempty" extensions:"x-omitempty"`
 } // @name Edge
 
+// @description Step explains the outcome of applying a rule to a start object during a search.
+type Step struct {
+	// Rule is the name of the rule applied.
+	Rule string `json:"rule"`
+	// Start is the class of the start object.
+	Start string `json:"start" example:"domain:class"`
+	// Goal is the class of the generated query, empty if no query was generated.
+	Goal string `json:"goal,omitempty" example:"domain:class"`
+	// Object is the ID or a preview of the start object, may be empty.
+	Object string `json:"object,omitempty"`
+	// Outcome of applying the rule.
+	Outcome string `json:"outcome" enums:"applied,notApplicable,error"`
+	// Query generated by the rule.
+	Query string `json:"query,omitempty"`
+	// Count of results for the query, or -1 if the query was not executed.
+	Count int `json:"count"`
+	// Error applying the rule or executing the query.
+	Error string `json:"error,omitempty"`
+} // @name Step
+
 // @description	Graph resulting from a correlation search.
 type Graph struct {
 	Nodes []Node `json:"nodes,omitempty"`
 	Edges []Edge `json:"edges,omitempty"`
+	// Explain lists the rules applied to each start object, if requested.
+	Explain []Step `json:"explain,omitempty"`
 } // @name Graph
+
+// @description Intersection is a class with results from more than one start.
+type Intersection struct {
+	// Class is the full class name in "DOMAIN:CLASS" form.
+	Class string `json:"class" example:"domain:class"`
+	// Starts are the indices of the starts that reached this class.
+	Starts []int `json:"starts"`
+	// Count of objects reached from more than one start.
+	Count int `json:"count"`
+	// Objects reached from more than one start.
+	Objects []any `json:"objects,omitempty"`
+} // @name Intersection
+
+// @description	MultiGraph results from a search from multiple starts.
+type MultiGraph struct {
+	// Graphs for each start, in the same order as the starts.
+	Graphs []Graph `json:"graphs"`
+	// Intersections are the classes and objects reached from more than one start.
+	Intersections []Intersection `json:"intersections,omitempty"`
+} // @name MultiGraph
+
+// @description TimelineEntry is a correlated object on a timeline.
+type TimelineEntry struct {
+	// Class is the full class name in "DOMAIN:CLASS" form.
+	Class string `json:"class" example:"domain:class"`
+	// Start time of the object, omitted if not known.
+	Start *time.Time `json:"start,omitempty" swaggertype:"string" format:"date-time" extensions:"x-nullable"`
+	// End time of the object, omitted if it is the same as Start or not known.
+	End *time.Time `json:"end,omitempty" swaggertype:"string" format:"date-time" extensions:"x-nullable"`
+	// Preview is a short description of the object, may be empty.
+	Preview string `json:"preview,omitempty"`
+	// Path is the list of rule names followed from the start class to this object's class.
+	Path []string `json:"path,omitempty"`
+	// Object serialized as JSON.
+	Object any `json:"object"`
+} // @name TimelineEntry
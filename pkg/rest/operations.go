@@ -21,15 +21,21 @@
 package rest
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/korrel8r/korrel8r/internal/pkg/logging"
+	"github.com/korrel8r/korrel8r/internal/pkg/metrics"
 	"github.com/korrel8r/korrel8r/pkg/config"
 	"github.com/korrel8r/korrel8r/pkg/engine"
 	"github.com/korrel8r/korrel8r/pkg/engine/traverse"
@@ -48,33 +54,141 @@ var log = logging.Log()
 var BasePath = docs.SwaggerInfo.BasePath
 
 type API struct {
+	Router *gin.Engine
+	// Scope if not nil returns the namespaces that a request is allowed to see, see [HeaderScope].
+	// Returning a nil list with no error allows all namespaces.
+	// Request constraints are restricted to the allowed namespaces.
+	Scope func(*http.Request) ([]string, error)
+	// Authenticator if not nil authenticates the caller of each API request, see [auth.WithUser].
+	// Unauthenticated requests are rejected. Store requests use the server's credentials, not the caller's.
+	Authenticator auth.Authenticator
+	// Impersonate if true makes store requests impersonate the authenticated caller.
+	Impersonate bool
+	// BuildEngine builds a new engine when the configuration changes, see [API.Reload].
+	// It must apply the same options (authorizer, recording) as the engine passed to [New].
+	// If nil, the engine is built from the configuration and the domains of the current engine.
+	BuildEngine func(config.Configs) (*engine.Engine, error)
+	// EditConfig if not nil enables the endpoints that edit rules, aliases and stores.
+	// It is called to authorize each edit, an error denies the edit.
+	// Edits are not restricted by Scope: an edit can add stores and rules that read any data.
+	EditConfig func(*http.Request) error
+	state      atomic.Pointer[state]
+	editLock   sync.Mutex // Serialize changes to state.
+	traversals traversals // Traversals in progress, kept across changes to state.
+}
+
+// state is the engine and configuration used to serve a request.
+type state struct {
 	Engine  *engine.Engine
 	Configs config.Configs
-	Router  *gin.Engine
+	limits  *limits
 }
 
 // New API instance, registers  handlers with a gin Engine.
 func New(e *engine.Engine, c config.Configs, r *gin.Engine) (*API, error) {
-	a := &API{Engine: e, Configs: c, Router: r}
+	a := &API{Router: r}
+	a.Update(e, c)
 	r.Use(a.logger)
+	r.Use(a.metrics)
+	r.Use(a.span)
 	r.Use(a.context)
+	r.Use(a.limit)
 	r.GET("/", func(c *gin.Context) { c.Redirect(http.StatusTemporaryRedirect, "/swagger/index.html") })
 	r.GET("/api", func(c *gin.Context) { c.Redirect(http.StatusTemporaryRedirect, "/swagger/index.html") })
 	r.GET("/swagger/*any", a.handleSwagger)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 	v := r.Group(docs.SwaggerInfo.BasePath)
 	v.GET("/domains", a.Domains)
 	v.GET("/domains/:domain/classes", a.DomainClasses)
 	v.GET("/objects", a.GetObjects)
-	v.POST("/graphs/goals", a.GraphsGoals)
-	v.POST("/graphs/neighbours", a.GraphsNeighbours)
-	v.POST("/lists/goals", a.ListsGoals)
+	v.POST("/graphs/goals", a.traversal, a.GraphsGoals)
+	v.POST("/graphs/neighbours", a.traversal, a.GraphsNeighbours)
+	v.POST("/graphs/multi", a.GraphsMulti) // Limits one traversal per start.
+	v.GET("/graphs/rules", a.GraphsRules)
+	v.POST("/lists/goals", a.traversal, a.ListsGoals)
+	v.POST("/timelines", a.traversal, a.PostTimelines)
 	v.PUT("/config", a.PutConfig)
+	v.GET("/config/rules", a.ConfigRules)
+	v.POST("/config/rules", a.editConfig, a.ConfigRulesCreate)
+	v.PUT("/config/rules/:name", a.editConfig, a.ConfigRulesUpdate)
+	v.DELETE("/config/rules/:name", a.editConfig, a.ConfigRulesDelete)
+	v.GET("/config/aliases", a.ConfigAliases)
+	v.POST("/config/aliases", a.editConfig, a.ConfigAliasesCreate)
+	v.PUT("/config/aliases/:domain/:name", a.editConfig, a.ConfigAliasesUpdate)
+	v.DELETE("/config/aliases/:domain/:name", a.editConfig, a.ConfigAliasesDelete)
+	v.GET("/config/stores", a.ConfigStores)
+	v.POST("/config/stores", a.editConfig, a.ConfigStoresCreate)
+	v.PUT("/config/stores/:index", a.editConfig, a.ConfigStoresUpdate)
+	v.DELETE("/config/stores/:index", a.editConfig, a.ConfigStoresDelete)
+	v.GET("/config/export", a.ConfigExport)
 	return a, nil
 }
 
 // Close cleans any persistent resources.
 func (a *API) Close() {}
 
+// Update atomically replaces the engine and configuration used by the API.
+// New requests use the new engine, requests in progress complete using the engine they started with.
+// Request limits are reset from the tuning section of the new configuration.
+func (a *API) Update(e *engine.Engine, c config.Configs) {
+	a.editLock.Lock()
+	defer a.editLock.Unlock()
+	a.update(e, c)
+}
+
+// Reload builds a new engine for configuration c with [API.BuildEngine] and updates the API.
+// The API is not changed if the engine cannot be built.
+func (a *API) Reload(c config.Configs) error {
+	a.editLock.Lock()
+	defer a.editLock.Unlock()
+	e, err := a.buildEngine(c)
+	if err != nil {
+		return err
+	}
+	a.update(e, c)
+	return nil
+}
+
+func (a *API) buildEngine(c config.Configs) (*engine.Engine, error) {
+	if a.BuildEngine != nil {
+		return a.BuildEngine(c)
+	}
+	return engine.Build().Domains(a.state.Load().Engine.Domains()...).Config(c).Engine()
+}
+
+// update is not safe, must be called with editLock held.
+func (a *API) update(e *engine.Engine, c config.Configs) {
+	s := &state{Engine: e, Configs: c}
+	maxTraversals := 0
+	if len(c) > 0 {
+		s.limits = newLimits(c[0].Tuning)
+		if c[0].Tuning != nil {
+			maxTraversals = c[0].Tuning.MaxTraversals
+		}
+	}
+	a.traversals.setMax(maxTraversals)
+	a.state.Store(s)
+}
+
+// Engine returns the engine used for new requests.
+func (a *API) Engine() *engine.Engine { return a.state.Load().Engine }
+
+// Configs returns the configuration used for new requests.
+func (a *API) Configs() config.Configs { return a.state.Load().Configs }
+
+const stateKey = "korrel8r-state"
+
+// current returns the state for a request, captured when the request started.
+func (a *API) current(c *gin.Context) *state {
+	if s, ok := c.Get(stateKey); ok {
+		return s.(*state)
+	}
+	return a.state.Load()
+}
+
+// engine returns the engine for a request.
+func (a *API) engine(c *gin.Context) *engine.Engine { return a.current(c).Engine }
+
 func (a *API) handleSwagger(c *gin.Context) {
 	// Set the SwaggerInfo Host to be consistent with the incoming request URL so the test UI will work.
 	// Note this may not work properly if there are concurrent requests with different URLs.
@@ -94,10 +208,11 @@ var swaggerInfoLock sync.Mutex
 //	@failure	default	{object}	any
 func (a *API) Domains(c *gin.Context) {
 	var domains []Domain
-	for _, d := range a.Engine.Domains() {
+	e := a.engine(c)
+	for _, d := range e.Domains() {
 		domains = append(domains, Domain{
 			Name:   d.Name(),
-			Stores: a.Engine.StoreConfigsFor(d),
+			Stores: e.StoreConfigsFor(d),
 		})
 	}
 	c.JSON(http.StatusOK, domains)
@@ -111,7 +226,7 @@ func (a *API) Domains(c *gin.Context) {
 //	@success	200		{object}	Classes
 //	@failure	default	{object}	any
 func (a *API) DomainClasses(c *gin.Context) {
-	d, err := a.Engine.DomainErr(c.Params.ByName("domain"))
+	d, err := a.engine(c).DomainErr(c.Params.ByName("domain"))
 	if !check(c, http.StatusNotFound, err) {
 		return
 	}
@@ -127,7 +242,10 @@ func (a *API) DomainClasses(c *gin.Context) {
 //	@router		/graphs/goals [post]
 //	@summary	Create a correlation graph from start objects to goal queries.
 //	@param		rules	query		bool	false	"include rules in graph edges"
+//	@param		ranked	query		int		false	"include up to this many of the most relevant objects in each node"
+//	@param		explain	query		bool	false	"include an explanation of each rule applied"
 //	@param		request	body		Goals	true	"search from start to goal classes"
+//	@produce	json,text/vnd.graphviz,text/vnd.mermaid,application/vnd.cytoscape+json,application/graphml+xml
 //	@success	200		{object}	Graph
 //	@success	206		{object}	Graph "interrupted, partial result"
 //	@failure	default	{object}	any
@@ -136,12 +254,17 @@ func (a *API) GraphsGoals(c *gin.Context) {
 	if !check(c, http.StatusBadRequest, c.BindQuery(opts)) {
 		return
 	}
-	g, _ := a.goals(c)
+	var explain *traverse.Explanation
+	if opts.Explain {
+		explain = traverse.NewExplanation()
+	}
+	g, start, _ := a.goals(c, explain)
 	if c.IsAborted() {
 		return
 	}
-	gr := Graph{Nodes: nodes(g), Edges: edges(g, opts)}
-	okResponse(c, gr)
+	gr := Graph{Nodes: nodes(g), Edges: edges(g, opts), Explain: NewSteps(explain)}
+	RankNodes(&gr, g, start, opts.Ranked)
+	graphResponse(c, g, gr)
 }
 
 // ListsGoals handler.
@@ -153,7 +276,7 @@ func (a *API) GraphsGoals(c *gin.Context) {
 //	@failure	default	{object}	any
 func (a *API) ListsGoals(c *gin.Context) {
 	nodes := []Node{} // return [] not null for empty
-	g, goals := a.goals(c)
+	g, _, goals := a.goals(c, nil)
 	if c.IsAborted() {
 		return
 	}
@@ -171,84 +294,227 @@ func (a *API) ListsGoals(c *gin.Context) {
 //	@router		/graphs/neighbours [post]
 //	@summary	Create a neighbourhood graph around a start object to a given depth.
 //	@param		rules	query		bool		false	"include rules in graph edges"
+//	@param		ranked	query		int			false	"include up to this many of the most relevant objects in each node"
+//	@param		explain	query		bool		false	"include an explanation of each rule applied"
 //	@param		request	body		Neighbours	true	"search from neighbours"
+//	@produce	json,text/vnd.graphviz,text/vnd.mermaid,application/vnd.cytoscape+json,application/graphml+xml
 //	@success	200		{object}	Graph
 //	@success	206		{object}	Graph "interrupted, partial result"
 //	@failure	default	{object}	any
 func (a *API) GraphsNeighbours(c *gin.Context) {
 	r, opts := Neighbours{}, Options{}
-	if !(check(c, http.StatusBadRequest, c.BindJSON(&r)) && check(c, http.StatusBadRequest, c.BindUri(&opts))) {
+	if !(check(c, http.StatusBadRequest, c.BindJSON(&r)) && check(c, http.StatusBadRequest, c.BindQuery(&opts))) {
 		return
 	}
 	start, constraint := a.start(c, &r.Start)
+	constraint = a.scope(c, constraint)
 	depth := r.Depth
 	if c.IsAborted() {
 		return
 	}
 	ctx, cancel := korrel8r.WithConstraint(c.Request.Context(), constraint.Default())
 	defer cancel()
-	g, err := traverse.New(a.Engine, a.Engine.Graph()).Neighbours(ctx, start, depth)
-	gr := Graph{Nodes: nodes(g), Edges: edges(g, &opts)}
+	var explain *traverse.Explanation
+	if opts.Explain {
+		explain = traverse.NewExplanation()
+		ctx = traverse.WithExplanation(ctx, explain)
+	}
+	e := a.engine(c)
+	g, err := traverse.New(e, e.Graph()).Neighbours(ctx, start, depth)
+	gr := Graph{Nodes: nodes(g), Edges: edges(g, &opts), Explain: NewSteps(explain)}
+	RankNodes(&gr, g, start.Class, opts.Ranked)
+	if !interrupted(c) {
+		check(c, http.StatusBadRequest, err)
+	}
+	if !c.IsAborted() {
+		graphResponse(c, g, gr)
+	}
+}
+
+// GraphsRules handler
+//
+//	@router		/graphs/rules [get]
+//	@summary	Get the graph of all classes and rules.
+//	@produce	json,text/vnd.graphviz,text/vnd.mermaid,application/vnd.cytoscape+json,application/graphml+xml
+//	@success	200		{object}	Graph
+//	@failure	default	{object}	any
+func (a *API) GraphsRules(c *gin.Context) {
+	g := a.engine(c).Graph()
+	if f := graphFormat(c); f != nil {
+		encodedResponse(c, f, g)
+	} else {
+		okResponse(c, NewRuleGraph(g))
+	}
+}
+
+// GraphsMulti handler
+//
+//	@router		/graphs/multi [post]
+//	@summary	Create correlation graphs from multiple starts, and find what they have in common.
+//	@description	Searches from each start, and reports intersections: classes and objects reached from more than one start.
+//	@description	Constraints must be set on the request, not on individual starts.
+//	@description	Each start counts as one search against the server limit on searches in progress.
+//	@param		rules	query		bool		false	"include rules in graph edges"
+//	@param		ranked	query		int			false	"include up to this many of the most relevant objects in each node"
+//	@param		request	body		MultiStart	true	"search from multiple starts"
+//	@success	200		{object}	MultiGraph
+//	@success	206		{object}	MultiGraph "interrupted, partial result"
+//	@failure	default	{object}	any
+func (a *API) GraphsMulti(c *gin.Context) {
+	r, opts := MultiStart{}, Options{}
+	if !(check(c, http.StatusBadRequest, c.BindJSON(&r)) && check(c, http.StatusBadRequest, c.BindQuery(&opts))) {
+		return
+	}
+	done := a.startTraversals(c, len(r.Starts))
+	if done == nil {
+		return
+	}
+	defer done()
+	var starts []traverse.Start
+	for i := range r.Starts {
+		start, constraint := a.start(c, &r.Starts[i])
+		if constraint != nil {
+			check(c, http.StatusBadRequest, errors.New("constraint must be set on the request, not on a start"), "start %v", i)
+		}
+		starts = append(starts, start)
+	}
+	constraint := a.scope(c, r.Constraint)
+	search := traverse.NeighbourSearch(r.Depth)
+	if len(r.Goals) > 0 {
+		search = traverse.GoalSearch(a.classes(c, r.Goals))
+	}
+	if c.IsAborted() {
+		return
+	}
+	ctx, cancel := korrel8r.WithConstraint(c.Request.Context(), constraint.Default())
+	defer cancel()
+	result, err := traverse.MultiStart(ctx, a.engine(c), starts, search)
+	if !interrupted(c) {
+		check(c, http.StatusBadRequest, err)
+	}
+	if c.IsAborted() {
+		return
+	}
+	mg := MultiGraph{Graphs: []Graph{}}
+	for i, g := range result.Graphs {
+		gr := Graph{Nodes: nodes(g), Edges: edges(g, &opts)}
+		RankNodes(&gr, g, starts[i].Class, opts.Ranked)
+		mg.Graphs = append(mg.Graphs, gr)
+	}
+	for _, x := range result.Intersections {
+		mg.Intersections = append(mg.Intersections, Intersection{
+			Class:   x.Class.String(),
+			Starts:  x.Starts,
+			Count:   len(x.Objects),
+			Objects: x.Objects,
+		})
+	}
+	okResponse(c, mg)
+}
+
+// PostTimelines handler
+//
+//	@router		/timelines [post]
+//	@summary	Create a timeline: all correlated objects in chronological order.
+//	@description	Searches from start to goals, or to neighbours if there are no goals.
+//	@description	Objects with unknown times are at the end of the timeline.
+//	@param		request	body		Timelines	true	"search for timeline"
+//	@success	200		{array}		TimelineEntry
+//	@success	206		{array}		TimelineEntry "interrupted, partial result"
+//	@failure	default	{object}	any
+func (a *API) PostTimelines(c *gin.Context) {
+	r := Timelines{}
+	if !check(c, http.StatusBadRequest, c.BindJSON(&r)) {
+		return
+	}
+	start, constraint := a.start(c, &r.Start)
+	constraint = a.scope(c, constraint)
+	search := traverse.NeighbourSearch(r.Depth)
+	if len(r.Goals) > 0 {
+		search = traverse.GoalSearch(a.classes(c, r.Goals))
+	}
+	if c.IsAborted() {
+		return
+	}
+	ctx, cancel := korrel8r.WithConstraint(c.Request.Context(), constraint.Default())
+	defer cancel()
+	e := a.engine(c)
+	g, err := search(ctx, traverse.New(e, e.Graph()), start)
 	if !interrupted(c) {
 		check(c, http.StatusBadRequest, err)
 	}
 	if !c.IsAborted() {
-		okResponse(c, gr)
+		okResponse(c, NewTimeline(g, start.Class))
 	}
 }
 
 // GetObjects handler
 //
 //	@router		/objects [get]
-//	@summary	Execute a query, returns a list of JSON objects.
+//	@summary	Execute a query, returns a list of JSON objects in chronological order if they have timestamps.
 //	@param		query	query		string	true	"query string"
 //	@success	200		{array}		any
+//	@success	206		{array}		any	"access denied, partial result"
 //	@failure	default	{object}	any
 func (a *API) GetObjects(c *gin.Context) {
 	opts := &Objects{}
 	if !check(c, http.StatusBadRequest, c.BindQuery(opts)) {
 		return
 	}
-	query, err := a.Engine.Query(opts.Query)
+	constraint := a.scope(c, opts.Constraint)
+	e := a.engine(c)
+	query, err := e.Query(opts.Query)
 	if !check(c, http.StatusBadRequest, err) {
 		return
 	}
 	result := graph.NewResult(query.Class())
-	if !check(c, http.StatusNotFound, a.Engine.Get(c.Request.Context(), query, (*korrel8r.Constraint)(opts.Constraint), result)) {
+	err = e.Get(c.Request.Context(), query, constraint, result)
+	if korrel8r.IsDenied(err) {
+		err = &traverse.PartialError{Err: err} // Denied queries have partial, empty, results.
+	}
+	if !check(c, http.StatusNotFound, err) && !traverse.IsPartial(err) {
 		return
 	}
 	log.V(3).Info("REST: response OK", "objects", len(result.List()))
-	body := []any(result.List())
+	body := slices.Clone(result.List())
+	korrel8r.SortByTime(query.Class(), body)
 	if body == nil {
 		body = []any{} // Return [] on empty, not null.
 	}
-	c.JSON(http.StatusOK, body)
+	okResponse(c, body)
 }
 
-func (a *API) goals(c *gin.Context) (g *graph.Graph, goals []korrel8r.Class) {
+// goals runs a goal search, recording steps in explain if it is not nil.
+func (a *API) goals(c *gin.Context, explain *traverse.Explanation) (g *graph.Graph, startClass korrel8r.Class, goals []korrel8r.Class) {
 	r := Goals{}
 	if !check(c, http.StatusBadRequest, c.BindJSON(&r)) {
-		return nil, nil
+		return nil, nil, nil
 	}
 	start, constraint := a.start(c, &r.Start)
+	constraint = a.scope(c, constraint)
 	goals = a.classes(c, r.Goals)
 	if c.IsAborted() {
-		return nil, nil
+		return nil, nil, nil
 	}
-	g = a.Engine.Graph().ShortestPaths(start.Class, goals...)
+	e := a.engine(c)
+	g = e.Graph().CheapestPaths(start.Class, goals, e.Cost, r.MaxCost)
 	var err error
 	ctx, cancel := korrel8r.WithConstraint(c.Request.Context(), constraint.Default())
 	defer cancel()
-	g, err = traverse.New(a.Engine, g).Goals(ctx, start, goals)
+	ctx = traverse.WithMaxCost(ctx, r.MaxCost)
+	if explain != nil {
+		ctx = traverse.WithExplanation(ctx, explain)
+	}
+	g, err = traverse.New(e, g).Goals(ctx, start, goals)
 	if !interrupted(c) && !traverse.IsPartial(err) {
 		check(c, http.StatusNotFound, err)
 	}
-	return g, goals
+	return g, start.Class, goals
 }
 
 func (a *API) queries(c *gin.Context, queryStrings []string) (queries []korrel8r.Query) {
 	for _, q := range queryStrings {
-		query, err := a.Engine.Query(q)
+		query, err := a.engine(c).Query(q)
 		if check(c, http.StatusBadRequest, err, "query parameter") {
 			queries = append(queries, query)
 		}
@@ -283,6 +549,45 @@ func (a *API) start(c *gin.Context, start *Start) (traverse.Start, *korrel8r.Con
 	return traverse.Start{Class: class, Objects: objects, Queries: queries}, start.Constraint
 }
 
+// scope restricts a request constraint to the namespaces allowed by [API.Scope].
+// Aborts the request as forbidden if none of the requested namespaces are allowed.
+func (a *API) scope(c *gin.Context, constraint *korrel8r.Constraint) *korrel8r.Constraint {
+	if a.Scope == nil {
+		return constraint
+	}
+	allowed, err := a.Scope(c.Request)
+	if err == nil && allowed != nil {
+		var ok bool
+		if constraint, ok = constraint.Scope(allowed); !ok {
+			err = errors.New("no requested namespace is allowed")
+		}
+	}
+	check(c, http.StatusForbidden, err, "namespace scope")
+	return constraint
+}
+
+// HeaderScope returns an [API.Scope] function that reads allowed namespaces from a request header.
+// The header is a comma-separated list of namespaces, usually set by an authenticating proxy.
+// The value "*" allows all namespaces. Requests without the header are forbidden.
+func HeaderScope(header string) func(*http.Request) ([]string, error) {
+	return func(r *http.Request) ([]string, error) {
+		value := strings.TrimSpace(r.Header.Get(header))
+		switch value {
+		case "":
+			return nil, fmt.Errorf("missing header %v", header)
+		case "*":
+			return nil, nil
+		}
+		namespaces := []string{} // Not nil, an empty list allows nothing.
+		for _, ns := range strings.Split(value, ",") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				namespaces = append(namespaces, ns)
+			}
+		}
+		return namespaces, nil
+	}
+}
+
 func check(c *gin.Context, code int, err error, format ...any) (ok bool) {
 	if err != nil && !c.IsAborted() {
 		if len(format) > 0 {
@@ -298,7 +603,7 @@ func check(c *gin.Context, code int, err error, format ...any) (ok bool) {
 }
 
 func (a *API) class(c *gin.Context, className string) korrel8r.Class {
-	class, err := a.Engine.Class(className)
+	class, err := a.engine(c).Class(className)
 	check(c, http.StatusNotFound, err)
 	return class
 }
@@ -336,12 +641,22 @@ func (a *API) logger(c *gin.Context) {
 }
 
 // context sets up authorization and deadline context for outgoing requests.
+// It also captures the current state, so the request uses the same engine throughout.
 func (a *API) context(c *gin.Context) {
 	ctx := auth.Context(c.Request) // add authentication
+	if a.Authenticator != nil && strings.HasPrefix(c.Request.URL.Path, BasePath) {
+		user, err := a.Authenticator.Authenticate(c.Request.Context(), auth.BearerToken(c.Request))
+		if !check(c, http.StatusUnauthorized, err) {
+			return
+		}
+		ctx = auth.WithUser(c.Request.Context(), user, a.Impersonate)
+	}
+	s := a.state.Load()
+	c.Set(stateKey, s)
 
 	timeout := korrel8r.DefaultTimeout
-	if len(a.Configs) > 0 {
-		tuning := a.Configs[0].Tuning
+	if len(s.Configs) > 0 {
+		tuning := s.Configs[0].Tuning
 		if tuning != nil && tuning.RequestTimeout.Duration > 0 {
 			timeout = tuning.RequestTimeout.Duration
 		}
@@ -359,10 +674,38 @@ func interrupted(c *gin.Context) bool {
 		c.Errors.Last() != nil && traverse.IsPartial(c.Errors.Last())
 }
 
-func okResponse(c *gin.Context, body any) {
-	status := http.StatusOK
+func okStatus(c *gin.Context) int {
 	if interrupted(c) {
-		status = http.StatusPartialContent
+		return http.StatusPartialContent
+	}
+	return http.StatusOK
+}
+
+func okResponse(c *gin.Context, body any) { c.JSON(okStatus(c), body) }
+
+// graphFormat returns the graph format requested by the Accept header, or nil for JSON.
+func graphFormat(c *gin.Context) *graph.Format {
+	offered := []string{gin.MIMEJSON}
+	for _, f := range graph.Formats() {
+		offered = append(offered, f.MediaType)
+	}
+	return graph.FormatFor(c.NegotiateFormat(offered...))
+}
+
+// encodedResponse writes g encoded in format f.
+func encodedResponse(c *gin.Context, f *graph.Format, g *graph.Graph) {
+	var b bytes.Buffer
+	if check(c, http.StatusInternalServerError, f.Encode(&b, g)) {
+		c.Data(okStatus(c), f.MediaType, b.Bytes())
+	}
+}
+
+// graphResponse writes the non-empty nodes of result graph g if the Accept header requests a graph format,
+// and body as JSON otherwise.
+func graphResponse(c *gin.Context, g *graph.Graph, body any) {
+	if f := graphFormat(c); f != nil && g != nil {
+		encodedResponse(c, f, g.NonEmpty())
+	} else {
+		okResponse(c, body)
 	}
-	c.JSON(status, body)
 }
@@ -4,6 +4,8 @@ package rest
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,16 +14,25 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/korrel8r/korrel8r/internal/pkg/test"
 	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
 	"github.com/korrel8r/korrel8r/pkg/config"
 	logDomain "github.com/korrel8r/korrel8r/pkg/domains/log"
 	"github.com/korrel8r/korrel8r/pkg/domains/metric"
 	"github.com/korrel8r/korrel8r/pkg/engine"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/korrel8r/korrel8r/pkg/otel"
+	"github.com/korrel8r/korrel8r/pkg/rest/auth"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
 )
 
 func TestAPI_GetDomains(t *testing.T) {
@@ -40,6 +51,17 @@ func TestAPI_GetDomains(t *testing.T) {
 	})
 }
 
+func TestAPI_Update(t *testing.T) {
+	e, err := engine.Build().Domains(mock.Domains("foo")...).Engine()
+	require.NoError(t, err)
+	a := newTestAPI(t, e)
+	assertDo(t, a, "GET", "/api/v1alpha1/domains", nil, http.StatusOK, []Domain{{Name: "foo"}})
+	e, err = engine.Build().Domains(mock.Domains("bar")...).Engine()
+	require.NoError(t, err)
+	a.Update(e, nil)
+	assertDo(t, a, "GET", "/api/v1alpha1/domains", nil, http.StatusOK, []Domain{{Name: "bar"}})
+}
+
 func TestAPI_GetDomainClasses(t *testing.T) {
 	e, err := engine.Build().Domains(logDomain.Domain, metric.Domain).Engine()
 	require.NoError(t, err)
@@ -106,6 +128,46 @@ func TestAPI_GraphGoals_rules(t *testing.T) {
 		})
 }
 
+func TestAPI_GraphGoals_maxCost(t *testing.T) {
+	e := testEngine(t)
+	assertDo(t, newTestAPI(t, e), "POST", "/api/v1alpha1/graphs/goals",
+		Goals{
+			Start:   Start{Class: "mock:a", Objects: []json.RawMessage{[]byte(`"x"`)}},
+			Goals:   []string{"mock:b"},
+			MaxCost: 0.5,
+		},
+		http.StatusOK, Graph{Nodes: []Node{{Class: "mock:a", Count: 1}}})
+}
+
+func TestAPI_GraphGoals_explain(t *testing.T) {
+	e := testEngine(t)
+	assertDo(t, newTestAPI(t, e), "POST", "/api/v1alpha1/graphs/goals?explain=true",
+		Goals{
+			Start: Start{
+				Class:   "mock:a",
+				Objects: []json.RawMessage{[]byte(`"x"`)},
+			},
+			Goals: []string{"mock:b"},
+		},
+		http.StatusOK,
+		Graph{
+			Nodes: []Node{
+				{Class: "mock:a", Count: 1},
+				{Class: "mock:b", Count: 1, Queries: []QueryCount{{Query: "mock:b:y", Count: 1}}},
+			},
+			Edges: []Edge{{Start: "mock:a", Goal: "mock:b"}},
+			Explain: []Step{{
+				Rule:    "a-b",
+				Start:   "mock:a",
+				Goal:    "mock:b",
+				Object:  "x",
+				Outcome: "applied",
+				Query:   "mock:b:y",
+				Count:   1,
+			}},
+		})
+}
+
 func TestAPI_PostNeighbours(t *testing.T) {
 	e := testEngine(t)
 	assertDo(t, newTestAPI(t, e), "POST", "/api/v1alpha1/graphs/neighbours",
@@ -133,6 +195,118 @@ func TestAPI_PostNeighbours(t *testing.T) {
 	)
 }
 
+func TestAPI_GraphsMulti(t *testing.T) {
+	d := mock.Domain("mock")
+	a, b, c := d.Class("a"), d.Class("b"), d.Class("c")
+	s := mock.NewStore(d)
+	e, err := engine.Build().Domains(d).Stores(s).Rules(
+		mock.NewRule("ac", list(a), list(c), mock.NewQuery(c, "1,2", 1, 2)),
+		mock.NewRule("bc", list(b), list(c), mock.NewQuery(c, "2,3", 2, 3)),
+	).Engine()
+	require.NoError(t, err)
+	assertDo(t, newTestAPI(t, e), "POST", "/api/v1alpha1/graphs/multi",
+		MultiStart{
+			Starts: []Start{
+				{Class: "mock:a", Objects: []json.RawMessage{[]byte(`0`)}},
+				{Class: "mock:b", Objects: []json.RawMessage{[]byte(`0`)}},
+			},
+			Depth: 1,
+		},
+		http.StatusOK,
+		MultiGraph{
+			Graphs: []Graph{
+				{
+					Nodes: []Node{
+						{Class: "mock:a", Count: 1},
+						{Class: "mock:c", Count: 2, Queries: []QueryCount{{Query: "mock:c:1,2", Count: 2}}},
+					},
+					Edges: []Edge{{Start: "mock:a", Goal: "mock:c"}},
+				},
+				{
+					Nodes: []Node{
+						{Class: "mock:b", Count: 1},
+						{Class: "mock:c", Count: 2, Queries: []QueryCount{{Query: "mock:c:2,3", Count: 2}}},
+					},
+					Edges: []Edge{{Start: "mock:b", Goal: "mock:c"}},
+				},
+			},
+			Intersections: []Intersection{{Class: "mock:c", Starts: []int{0, 1}, Count: 1, Objects: []any{2.0}}},
+		})
+
+	// Constraints are not allowed on individual starts.
+	rr := newTestAPI(t, e).do(t, "POST", "/api/v1alpha1/graphs/multi", MultiStart{
+		Starts: []Start{{Class: "mock:a", Objects: []json.RawMessage{[]byte(`0`)}, Constraint: &Constraint{}}},
+	})
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+}
+
+func TestAPI_PostNeighbours_ranked(t *testing.T) {
+	e := testEngine(t)
+	assertDo(t, newTestAPI(t, e), "POST", "/api/v1alpha1/graphs/neighbours?ranked=1",
+		Neighbours{
+			Start: Start{Class: "mock:a", Objects: []json.RawMessage{[]byte(`"x"`)}},
+			Depth: 1,
+		},
+		http.StatusOK,
+		Graph{
+			Nodes: []Node{
+				{Class: "mock:a", Count: 1, Ranked: []Ranked{{Object: "x", Score: 1, Distance: 0, Paths: 1}}},
+				{
+					Class:   "mock:b",
+					Count:   1,
+					Queries: []QueryCount{{Query: "mock:b:y", Count: 1}},
+					Ranked:  []Ranked{{Object: "by", Score: 0.5, Distance: 1, Paths: 1}},
+				},
+			},
+			Edges: []Edge{{Start: "mock:a", Goal: "mock:b"}},
+		},
+	)
+}
+
+func TestAPI_PostTimelines(t *testing.T) {
+	e := testEngine(t)
+	want := []TimelineEntry{
+		{Class: "mock:a", Object: "x"},
+		{Class: "mock:b", Object: "by", Path: []string{"a-b"}},
+	}
+	a := newTestAPI(t, e)
+	start := Start{Class: "mock:a", Objects: []json.RawMessage{[]byte(`"x"`)}}
+	assertDo(t, a, "POST", "/api/v1alpha1/timelines", Timelines{Start: start, Depth: 1}, http.StatusOK, want)
+	assertDo(t, a, "POST", "/api/v1alpha1/timelines", Timelines{Start: start, Goals: []string{"mock:b"}}, http.StatusOK, want)
+}
+
+func TestAPI_GraphsRules(t *testing.T) {
+	a := newTestAPI(t, testEngine(t))
+	assertDo(t, a, "GET", "/api/v1alpha1/graphs/rules", nil, http.StatusOK, Graph{
+		Nodes: []Node{{Class: "mock:a"}, {Class: "mock:b"}},
+		Edges: []Edge{{Start: "mock:a", Goal: "mock:b", Rules: []Rule{{Name: "a-b"}}}},
+	})
+	rr := a.doAccept(t, "GET", "/api/v1alpha1/graphs/rules", nil, "text/vnd.mermaid")
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, "text/vnd.mermaid", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "flowchart LR\n  n0[\"mock:a\"]\n  n1[\"mock:b\"]\n  n0 -->|\"a-b\"| n1\n", rr.Body.String())
+}
+
+func TestAPI_GraphsNeighbours_accept(t *testing.T) {
+	a := newTestAPI(t, testEngine(t))
+	req := Neighbours{Start: Start{Queries: []string{"mock:a:x"}}, Depth: 1}
+	for _, x := range []struct{ accept, contentType, want string }{
+		{"text/vnd.mermaid", "text/vnd.mermaid", "flowchart LR\n  n0[\"mock:a (1)\"]\n  n1[\"mock:b (1)\"]\n  n0 -->|\"a-b (1)\"| n1\n"},
+		{"application/graphml+xml", "application/graphml+xml", `<data key="edgeCount">1</data>`},
+		{"application/vnd.cytoscape+json", "application/vnd.cytoscape+json", `"source": "mock:a"`},
+		{"text/vnd.graphviz", "text/vnd.graphviz", `label="a-b (1)"`},
+		{"", "application/json; charset=utf-8", `"class":"mock:b"`},
+		{"text/html", "application/json; charset=utf-8", `"class":"mock:b"`},
+	} {
+		t.Run(x.accept, func(t *testing.T) {
+			rr := a.doAccept(t, "POST", "/api/v1alpha1/graphs/neighbours", req, x.accept)
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Equal(t, x.contentType, rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Body.String(), x.want)
+		})
+	}
+}
+
 func TestAPI_PostNeighbours_partial(t *testing.T) {
 	e := testEngine(t)
 	s := e.StoresFor(e.Domains()[0])[0].(*mock.Store)
@@ -216,6 +390,11 @@ func newTestAPI(t *testing.T, e *engine.Engine) *testAPI {
 }
 
 func (a *testAPI) do(t *testing.T, method, url string, body any) *httptest.ResponseRecorder {
+	return a.doAccept(t, method, url, body, "")
+}
+
+// doAccept is like do with an Accept header, if accept is not empty.
+func (a *testAPI) doAccept(t *testing.T, method, url string, body any, accept string) *httptest.ResponseRecorder {
 	rr := httptest.NewRecorder()
 	var r io.Reader
 	if body != nil {
@@ -228,6 +407,9 @@ func (a *testAPI) do(t *testing.T, method, url string, body any) *httptest.Respo
 		rr.Code = http.StatusBadRequest
 		fmt.Fprintln(rr, err.Error())
 	} else {
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
 		a.Router.ServeHTTP(rr, req)
 	}
 	return rr
@@ -263,3 +445,459 @@ func testEngine(t *testing.T) (e *engine.Engine) {
 }
 
 func list[T any](x ...T) []T { return x }
+
+func TestAPI_Scope(t *testing.T) {
+	d := mock.Domain("mock")
+	s := mock.NewStore(d)
+	s.AddQuery("mock:a:x", "ax")
+	var got []string
+	s.ConstraintFunc = func(c *korrel8r.Constraint, _ korrel8r.Object) bool { got = c.Namespaces; return true }
+	r := mock.NewRule("a-b", list(d.Class("a")), list(d.Class("b")), mock.NewQuery(d.Class("b"), "y"))
+	e, err := engine.Build().Domains(d).Stores(s).Rules(r).Engine()
+	require.NoError(t, err)
+	a := newTestAPI(t, e)
+	var allowed []string
+	var scopeErr error
+	a.Scope = func(*http.Request) ([]string, error) { return allowed, scopeErr }
+
+	allowed = []string{"ns1", "ns2"}
+	assertDo(t, a, "GET", "/api/v1alpha1/objects?query=mock:a:x", nil, http.StatusOK, []any{"ax"})
+	assert.Equal(t, []string{"ns1", "ns2"}, got)
+
+	req := Neighbours{Start: Start{Queries: []string{"mock:a:x"}, Constraint: &Constraint{Namespaces: []string{"ns2", "ns3"}}}}
+	rr := a.do(t, "POST", "/api/v1alpha1/graphs/neighbours", req)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, []string{"ns2"}, got)
+
+	req.Start.Constraint.Namespaces = []string{"ns3"}
+	rr = a.do(t, "POST", "/api/v1alpha1/graphs/neighbours", req)
+	assert.Equal(t, http.StatusForbidden, rr.Code, rr.Body.String())
+
+	allowed, scopeErr = nil, errors.New("denied")
+	rr = a.do(t, "GET", "/api/v1alpha1/objects?query=mock:a:x", nil)
+	assert.Equal(t, http.StatusForbidden, rr.Code, rr.Body.String())
+
+	allowed, scopeErr = nil, nil // All namespaces.
+	got = nil
+	assertDo(t, a, "GET", "/api/v1alpha1/objects?query=mock:a:x", nil, http.StatusOK, []any{"ax"})
+	assert.Nil(t, got)
+}
+
+// testAuth authenticates token "good" as user "alice", and denies queries for class "mock:b".
+// allowEdit allows all configuration edits.
+func allowEdit(*http.Request) error { return nil }
+
+type testAuth struct{}
+
+func (testAuth) Authenticate(_ context.Context, token string) (*auth.User, error) {
+	if token != "good" {
+		return nil, errors.New("bad token")
+	}
+	return &auth.User{Name: "alice"}, nil
+}
+
+func (testAuth) Authorize(ctx context.Context, q korrel8r.Query, c *korrel8r.Constraint) (*korrel8r.Constraint, error) {
+	if u := auth.UserFrom(ctx); u == nil || q.Class().Name() == "b" {
+		return nil, korrel8r.DeniedError{Reason: "not allowed"}
+	}
+	return c, nil
+}
+
+func TestAPI_Authorization(t *testing.T) {
+	d := mock.Domain("mock")
+	a, b := d.Class("a"), d.Class("b")
+	s := mock.NewStore(d)
+	s.AddQuery("mock:a:x", "ax")
+	s.AddQuery("mock:b:y", "by")
+	r := mock.NewRule("a-b", list(a), list(b), mock.NewQuery(b, "y"))
+	e, err := engine.Build().Domains(d).Stores(s).Rules(r).Authorizer(testAuth{}).Engine()
+	require.NoError(t, err)
+	api := newTestAPI(t, e)
+	api.Authenticator = testAuth{}
+	do := func(token, method, url string, body any) *httptest.ResponseRecorder {
+		var r io.Reader
+		if body != nil {
+			j, _ := json.Marshal(body)
+			r = bytes.NewReader(j)
+		}
+		req := httptest.NewRequest(method, url, r)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		api.Router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("bad", "GET", "/api/v1alpha1/objects?query=mock:a:x", nil)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code, rr.Body.String())
+
+	rr = do("good", "GET", "/api/v1alpha1/objects?query=mock:a:x", nil)
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `["ax"]`, rr.Body.String())
+
+	rr = do("good", "GET", "/api/v1alpha1/objects?query=mock:b:y", nil)
+	assert.Equal(t, http.StatusPartialContent, rr.Code, rr.Body.String())
+	assert.JSONEq(t, `[]`, rr.Body.String())
+
+	rr = do("good", "POST", "/api/v1alpha1/graphs/neighbours", Neighbours{Start: Start{Queries: []string{"mock:a:x"}}, Depth: 1})
+	assert.Equal(t, http.StatusPartialContent, rr.Code, rr.Body.String())
+	var g Graph
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &g))
+	assert.Equal(t, []Node{{Class: "mock:a", Count: 1, Queries: []QueryCount{{Query: "mock:a:x", Count: 1}}}}, g.Nodes)
+}
+
+func TestAPI_Authorization_configEdit(t *testing.T) {
+	d := mock.Domain("mock")
+	s := mock.NewStore(d)
+	s.AddQuery("mock:b:y", "by")
+	build := func(c config.Configs) (*engine.Engine, error) {
+		return engine.Build().Domains(d).Stores(s).Config(c).Authorizer(testAuth{}).Engine()
+	}
+	e, err := build(nil)
+	require.NoError(t, err)
+	api := newTestAPI(t, e)
+	api.Authenticator = testAuth{}
+	api.BuildEngine = build
+	api.EditConfig = func(req *http.Request) error {
+		if u := auth.UserFrom(req.Context()); u == nil || u.Name != "alice" {
+			return errors.New("not alice")
+		}
+		return nil
+	}
+	do := func(method, url string, body any) *httptest.ResponseRecorder {
+		j, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, url, bytes.NewReader(j))
+		req.Header.Set("Authorization", "Bearer good")
+		rr := httptest.NewRecorder()
+		api.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	const objects = "/api/v1alpha1/objects?query=mock:b:y"
+	require.Equal(t, http.StatusPartialContent, do("GET", objects, nil).Code)
+	rule := config.Rule{Name: "r", Start: config.ClassSpec{Domain: "mock", Classes: []string{"a"}},
+		Goal: config.ClassSpec{Domain: "mock", Classes: []string{"b"}}, Result: config.ResultSpec{Query: "mock:b:y"}}
+	rr := do("POST", "/api/v1alpha1/config/rules", rule)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	require.NotNil(t, api.Engine().Rule("r"))
+	rr = do("GET", objects, nil)
+	assert.Equal(t, http.StatusPartialContent, rr.Code, "still denied after a configuration change")
+	assert.JSONEq(t, `[]`, rr.Body.String())
+}
+
+func TestHeaderScope(t *testing.T) {
+	scope := HeaderScope("X-Namespaces")
+	for _, x := range []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"a, b", []string{"a", "b"}, false},
+		{"*", nil, false},
+		{",", []string{}, false},
+		{"", nil, true},
+	} {
+		r := &http.Request{Header: http.Header{}}
+		if x.value != "" {
+			r.Header.Set("X-Namespaces", x.value)
+		}
+		got, err := scope(r)
+		assert.Equal(t, x.want, got, "%q", x.value)
+		assert.Equal(t, x.wantErr, err != nil, "%q", x.value)
+	}
+}
+
+func TestAPI_ConfigRules(t *testing.T) {
+	r1 := config.Rule{Name: "r1", Start: config.ClassSpec{Domain: "foo", Classes: []string{"x"}},
+		Goal: config.ClassSpec{Domain: "bar", Classes: []string{"y"}}, Result: config.ResultSpec{Query: "bar:y:{}"}}
+	configs := config.Configs{{Rules: []config.Rule{r1}}}
+	e, err := engine.Build().Domains(mock.Domains("foo", "bar")...).Config(configs).Engine()
+	require.NoError(t, err)
+	r := ginEngine()
+	api, err := New(e, configs, r)
+	require.NoError(t, err)
+	a := &testAPI{API: api, Router: r}
+	const path = "/api/v1alpha1/config/rules"
+
+	assertDo(t, a, "GET", path, nil, http.StatusOK, []config.Rule{r1})
+
+	r2 := r1
+	r2.Name = "r2"
+	// Editing is disabled by default.
+	assert.Equal(t, http.StatusForbidden, a.do(t, "POST", path, r2).Code)
+	// Edits must be authorized.
+	a.EditConfig = func(*http.Request) error { return errors.New("denied") }
+	assert.Equal(t, http.StatusForbidden, a.do(t, "POST", path, r2).Code)
+	assert.Nil(t, a.Engine().Rule("r2"))
+	a.EditConfig = allowEdit
+	assertDo(t, a, "POST", path, r2, http.StatusCreated, r2)
+	assert.Equal(t, http.StatusConflict, a.do(t, "POST", path, r2).Code)
+	assert.NotNil(t, a.Engine().Rule("r2"))
+
+	bad := r2
+	bad.Goal.Domain = "nonesuch"
+	assert.Equal(t, http.StatusBadRequest, a.do(t, "PUT", path+"/r2", bad).Code)
+	assert.Equal(t, []config.Rule{r1, r2}, a.Configs()[0].Rules, "invalid update must not change configuration")
+
+	r2.Result.Query = "bar:y:{a: b}"
+	assertDo(t, a, "PUT", path+"/r2", r2, http.StatusOK, r2)
+	assert.Equal(t, http.StatusNotFound, a.do(t, "PUT", path+"/nonesuch", r2).Code)
+
+	assert.Equal(t, http.StatusNoContent, a.do(t, "DELETE", path+"/r1", nil).Code)
+	assert.Equal(t, http.StatusNotFound, a.do(t, "DELETE", path+"/r1", nil).Code)
+	assertDo(t, a, "GET", path, nil, http.StatusOK, []config.Rule{r2})
+	assert.Nil(t, a.Engine().Rule("r1"))
+	assert.Equal(t, []config.Rule{r1}, configs[0].Rules, "original configuration must not change")
+}
+
+func TestAPI_ConfigAliasesStoresExport(t *testing.T) {
+	configs := config.Configs{{
+		Aliases: []config.Class{{Name: "xs", Domain: "foo", Classes: []string{"x"}}},
+		Rules: []config.Rule{{Name: "r1", Start: config.ClassSpec{Domain: "foo", Classes: []string{"xs"}},
+			Goal: config.ClassSpec{Domain: "bar", Classes: []string{"y"}}, Result: config.ResultSpec{Query: "bar:y:{}"}}},
+	}}
+	e, err := engine.Build().Domains(mock.Domains("foo", "bar")...).Config(configs).Engine()
+	require.NoError(t, err)
+	r := ginEngine()
+	api, err := New(e, configs, r)
+	require.NoError(t, err)
+	a := &testAPI{API: api, Router: r}
+	a.EditConfig = allowEdit
+
+	// Changing an alias changes rules that use it.
+	xs := config.Class{Name: "xs", Domain: "foo", Classes: []string{"x", "z"}}
+	assertDo(t, a, "PUT", "/api/v1alpha1/config/aliases/foo/xs", xs, http.StatusOK, xs)
+	assert.Equal(t, []string{"foo:x", "foo:z"}, names(a.Engine().Rule("r1").Start()))
+	assertDo(t, a, "GET", "/api/v1alpha1/config/aliases", nil, http.StatusOK, []config.Class{xs})
+
+	s := config.Store{"domain": "foo", "a": "1"}
+	assertDo(t, a, "POST", "/api/v1alpha1/config/stores", s, http.StatusCreated, s)
+	assertDo(t, a, "GET", "/api/v1alpha1/domains", nil, http.StatusOK, []Domain{{Name: "bar"}, {Name: "foo", Stores: []Store{s}}})
+	s2 := config.Store{"domain": "foo", "a": "2"}
+	assertDo(t, a, "PUT", "/api/v1alpha1/config/stores/0", s2, http.StatusOK, s2)
+	assert.Equal(t, http.StatusNotFound, a.do(t, "PUT", "/api/v1alpha1/config/stores/1", s2).Code)
+
+	w := a.do(t, "GET", "/api/v1alpha1/config/export", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	got, err := config.Read(writeTemp(t, w.Body.Bytes()))
+	require.NoError(t, err)
+	got[0].Source = ""
+	assert.Equal(t, a.Configs(), got)
+
+	assert.Equal(t, http.StatusNoContent, a.do(t, "DELETE", "/api/v1alpha1/config/stores/0", nil).Code)
+	assertDo(t, a, "GET", "/api/v1alpha1/config/stores", nil, http.StatusOK, []config.Store{})
+	assert.Equal(t, http.StatusNoContent, a.do(t, "DELETE", "/api/v1alpha1/config/aliases/foo/xs", nil).Code)
+	assert.Equal(t, []string{"foo:xs"}, names(a.Engine().Rule("r1").Start()))
+}
+
+func TestAPI_ConfigStoresIncluded(t *testing.T) {
+	// Store indexes count across all configuration files.
+	configs := config.Configs{
+		{Source: "main", Stores: []config.Store{{"domain": "foo", "a": "1"}}},
+		{Source: "included", Stores: []config.Store{{"domain": "foo", "a": "2"}, {"domain": "bar", "a": "3"}}},
+	}
+	e, err := engine.Build().Domains(mock.Domains("foo", "bar")...).Config(configs).Engine()
+	require.NoError(t, err)
+	r := ginEngine()
+	api, err := New(e, configs, r)
+	require.NoError(t, err)
+	a := &testAPI{API: api, Router: r}
+	a.EditConfig = allowEdit
+	const path = "/api/v1alpha1/config/stores"
+
+	s := config.Store{"domain": "bar", "a": "4"}
+	assertDo(t, a, "PUT", path+"/2", s, http.StatusOK, s)
+	assertDo(t, a, "GET", path, nil, http.StatusOK, []config.Store{configs[0].Stores[0], configs[1].Stores[0], s})
+	assert.Equal(t, []config.Store{configs[1].Stores[0], s}, a.Configs()[1].Stores)
+
+	// New stores go at the end of the list, so the index of the new store is the list length.
+	s5 := config.Store{"domain": "foo", "a": "5"}
+	assertDo(t, a, "POST", path, s5, http.StatusCreated, s5)
+	assertDo(t, a, "GET", path, nil, http.StatusOK, []config.Store{configs[0].Stores[0], configs[1].Stores[0], s, s5})
+	s6 := config.Store{"domain": "foo", "a": "6"}
+	assertDo(t, a, "PUT", path+"/3", s6, http.StatusOK, s6)
+	assert.Equal(t, []config.Store{configs[1].Stores[0], s, s6}, a.Configs()[1].Stores)
+	assert.Equal(t, configs[0].Stores, a.Configs()[0].Stores)
+}
+
+func names[T fmt.Stringer](list []T) (names []string) {
+	for _, v := range list {
+		names = append(names, v.String())
+	}
+	return names
+}
+
+func writeTemp(t *testing.T, b []byte) string {
+	t.Helper()
+	f := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(f, b, 0666))
+	return f
+}
+
+func TestAPI_Limits(t *testing.T) {
+	a := newTestAPI(t, testEngine(t))
+	a.Update(a.Engine(), config.Configs{{Tuning: &config.Tuning{
+		RequestRate:       &config.Rate{QPS: 0.01, Burst: 4},
+		ClientRequestRate: &config.Rate{QPS: 0.01, Burst: 2},
+		MaxTraversals:     1,
+	}}})
+	do := func(from, method, url string, body any) *httptest.ResponseRecorder {
+		var r io.Reader
+		if body != nil {
+			j, _ := json.Marshal(body)
+			r = bytes.NewReader(j)
+		}
+		req := httptest.NewRequest(method, url, r)
+		req.RemoteAddr = from + ":1234"
+		rr := httptest.NewRecorder()
+		a.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	const objects = "/api/v1alpha1/objects?query=mock:a:x"
+
+	// Per-client limit.
+	assert.Equal(t, http.StatusOK, do("10.0.0.1", "GET", objects, nil).Code)
+	assert.Equal(t, http.StatusOK, do("10.0.0.1", "GET", objects, nil).Code)
+	rr := do("10.0.0.1", "GET", objects, nil)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, rr.Body.String())
+	retry, err := strconv.Atoi(rr.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Greater(t, retry, 0)
+
+	// Traversal limit, simulate a traversal in progress.
+	done, ok := a.traversals.start(1)
+	require.True(t, ok)
+	rr = do("10.0.0.2", "POST", "/api/v1alpha1/graphs/neighbours", Neighbours{Start: Start{Queries: []string{"mock:a:x"}}, Depth: 1})
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, rr.Body.String())
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+	done()
+
+	// Global limit, requests rejected by a rate limit did not use up the global budget.
+	assert.Equal(t, http.StatusOK, do("10.0.0.3", "GET", objects, nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, do("10.0.0.4", "GET", objects, nil).Code)
+
+	// Requests outside the API are not limited.
+	assert.Equal(t, http.StatusTemporaryRedirect, do("10.0.0.1", "GET", "/api", nil).Code)
+
+	// Updating the configuration resets the limits.
+	a.Update(a.Engine(), nil)
+	assert.Equal(t, http.StatusOK, do("10.0.0.1", "GET", objects, nil).Code)
+}
+
+func TestAPI_Limits_configEdit(t *testing.T) {
+	a := newTestAPI(t, testEngine(t))
+	a.Update(a.Engine(), config.Configs{{Tuning: &config.Tuning{ClientRequestRate: &config.Rate{QPS: 0.01, Burst: 1}}}})
+	a.EditConfig = allowEdit
+	rule := config.Rule{
+		Name:   "r",
+		Start:  config.ClassSpec{Domain: "mock", Classes: []string{"a"}},
+		Goal:   config.ClassSpec{Domain: "mock", Classes: []string{"b"}},
+		Result: config.ResultSpec{Query: "mock:b:y"},
+	}
+	rr := a.do(t, "POST", "/api/v1alpha1/config/rules", rule)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	// Editing the configuration keeps the limits.
+	require.NotNil(t, a.state.Load().limits)
+	rr = a.do(t, "GET", "/api/v1alpha1/domains", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	rr = a.do(t, "GET", "/api/v1alpha1/domains", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, rr.Body.String())
+}
+
+func TestAPI_Limits_traversals(t *testing.T) {
+	a := newTestAPI(t, testEngine(t))
+	a.Update(a.Engine(), config.Configs{{Tuning: &config.Tuning{MaxTraversals: 2}}})
+	start := Start{Queries: []string{"mock:a:x"}}
+	multi := func(n int) *httptest.ResponseRecorder {
+		return a.do(t, "POST", "/api/v1alpha1/graphs/multi", MultiStart{Starts: slices.Repeat([]Start{start}, n), Depth: 1})
+	}
+	rr := multi(2)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	// More starts than the limit can never succeed.
+	rr = multi(3)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+
+	// Each start of a multi-start search counts as a traversal.
+	done, ok := a.traversals.start(1)
+	require.True(t, ok)
+	rr = multi(2)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, rr.Body.String())
+
+	// Traversals in progress count against the limit after a configuration change.
+	a.Update(a.Engine(), config.Configs{{Tuning: &config.Tuning{MaxTraversals: 1}}})
+	rr = a.do(t, "POST", "/api/v1alpha1/graphs/neighbours", Neighbours{Start: start, Depth: 1})
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code, rr.Body.String())
+	done()
+	rr = a.do(t, "POST", "/api/v1alpha1/graphs/neighbours", Neighbours{Start: start, Depth: 1})
+	assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+}
+
+func TestAPI_Metrics(t *testing.T) {
+	a := newTestAPI(t, testEngine(t))
+	assertDo(t, a, "GET", "/api/v1alpha1/objects?query=mock:a:x", nil, http.StatusOK, []any{"ax"})
+	rr := a.do(t, "POST", "/api/v1alpha1/graphs/neighbours", Neighbours{Start: Start{Queries: []string{"mock:a:x"}}, Depth: 1})
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	rr = a.do(t, "GET", "/metrics", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	for _, want := range []string{
+		`korrel8r_rest_requests_total{code="200",method="GET",operation="/api/v1alpha1/objects"}`,
+		`korrel8r_rest_request_duration_seconds_count{method="POST",operation="/api/v1alpha1/graphs/neighbours"}`,
+		`korrel8r_store_query_duration_seconds_count{domain="mock",store="0"}`,
+		`korrel8r_store_objects_total{domain="mock",store="0"}`,
+		`korrel8r_traverse_searches_total{search="neighbours"}`,
+		`korrel8r_traverse_nodes_count{search="neighbours"}`,
+		`korrel8r_traverse_rule_applications_total{outcome="query",rule="a-b"}`,
+	} {
+		assert.Contains(t, body, want)
+	}
+}
+
+func TestAPI_Tracing(t *testing.T) {
+	collector := test.NewOTLPCollector(t)
+	stop, err := tracing.Start(context.Background(), collector.Endpoint())
+	require.NoError(t, err)
+	a := newTestAPI(t, testEngine(t))
+	body, _ := json.Marshal(Neighbours{Start: Start{Queries: []string{"mock:a:x"}}, Depth: 1})
+	req := httptest.NewRequest("POST", "/api/v1alpha1/graphs/neighbours", bytes.NewReader(body))
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	a.Router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NoError(t, stop(context.Background()))
+
+	spans := map[string][]*tracepb.Span{}
+	for _, s := range collector.Spans() {
+		assert.Equal(t, traceID, hex.EncodeToString(s.TraceId), "span %v continues the caller's trace", s.Name)
+		spans[s.Name] = append(spans[s.Name], s)
+	}
+	attrs := func(s *tracepb.Span) map[string]any {
+		m := map[string]any{}
+		for _, kv := range s.Attributes {
+			m[kv.Key] = otel.ValueOf(kv.Value)
+		}
+		return m
+	}
+	require.Len(t, spans["POST /api/v1alpha1/graphs/neighbours"], 1)
+	request := spans["POST /api/v1alpha1/graphs/neighbours"][0]
+	assert.Equal(t, int64(200), attrs(request)["http.response.status_code"])
+
+	require.Len(t, spans["korrel8r.traverse.neighbours"], 1)
+	search := spans["korrel8r.traverse.neighbours"][0]
+	assert.Equal(t, request.SpanId, search.ParentSpanId)
+	assert.Equal(t, int64(1), attrs(search)["korrel8r.depth"])
+	assert.Equal(t, int64(2), attrs(search)["korrel8r.count"])
+
+	queries := spans["korrel8r.traverse.query"]
+	require.Len(t, queries, 2)
+	gets := spans["korrel8r.engine.get"]
+	require.Len(t, gets, 2)
+	for i, q := range queries {
+		assert.Equal(t, search.SpanId, q.ParentSpanId)
+		get := gets[slices.IndexFunc(gets, func(s *tracepb.Span) bool { return bytes.Equal(s.ParentSpanId, q.SpanId) })]
+		assert.Equal(t, attrs(q)["korrel8r.query"], attrs(get)["korrel8r.query"], "query %v", i)
+		assert.Equal(t, "mock", attrs(get)["korrel8r.domain"])
+		assert.Equal(t, int64(1), attrs(get)["korrel8r.count"])
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package rest
+
+import (
+	"cmp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/korrel8r/korrel8r/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// span is a Gin handler to create a tracing span for each request.
+// The span continues the caller's trace if the request has trace context headers.
+func (a *API) span(c *gin.Context) {
+	ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+	route := cmp.Or(c.FullPath(), "unknown")
+	ctx, span := tracing.Tracer().Start(ctx, c.Request.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(c.Request.URL.Path)))
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
+	status := c.Writer.Status()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	var err error
+	if status >= http.StatusInternalServerError {
+		if last := c.Errors.Last(); last != nil {
+			err = last
+		}
+	}
+	tracing.End(span, err)
+}
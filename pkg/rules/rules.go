@@ -6,9 +6,10 @@
 package rules
 
 import (
-	"errors"
+	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
 	"bytes"
 
@@ -21,11 +22,20 @@ func NewTemplateRule(start, goal []korrel8r.Class, query *template.Template) kor
 	return &templateRule{start: start, goal: goal, query: query}
 }
 
+// NewIntervalTemplateRule is like [NewTemplateRule], but also uses templates to generate
+// the start and end of the time interval for goal queries, see [korrel8r.Constrainer].
+// The start or end template may be nil.
+func NewIntervalTemplateRule(start, goal []korrel8r.Class, query, startTime, endTime *template.Template) korrel8r.Rule {
+	return &templateRule{start: start, goal: goal, query: query, startTime: startTime, endTime: endTime}
+}
+
 var _ = impl.AssertRule(&templateRule{})
+var _ korrel8r.Constrainer = &templateRule{}
 
 type templateRule struct {
-	query       *template.Template
-	start, goal []korrel8r.Class
+	query              *template.Template
+	startTime, endTime *template.Template
+	start, goal        []korrel8r.Class
 }
 
 func (r *templateRule) Name() string            { return r.query.Name() }
@@ -36,13 +46,56 @@ func (r *templateRule) Goal() []korrel8r.Class  { return r.goal }
 // Apply the rule by applying the template.
 // Return non-nil error if the rule does not apply.
 func (r *templateRule) Apply(start korrel8r.Object) (korrel8r.Query, error) {
-	b := &bytes.Buffer{}
-	if err := r.query.Execute(b, start); err != nil {
+	query, err := execute(r.query, start)
+	if err != nil {
 		return nil, err
 	}
-	query := strings.TrimSpace(string(b.String()))
 	if query == "" { // Blank query means rule does not apply.
-		return nil, errors.New("No query generated")
+		return nil, korrel8r.ErrNotApplicable
 	}
 	return r.Goal()[0].Domain().Query(query)
 }
+
+// Constrain narrows the time interval of c using the start and end templates, if there are any.
+func (r *templateRule) Constrain(start korrel8r.Object, c *korrel8r.Constraint) (*korrel8r.Constraint, error) {
+	if r.startTime == nil && r.endTime == nil {
+		return c, nil
+	}
+	begin, err := executeTime(r.startTime, start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := executeTime(r.endTime, start)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := c.Narrow(begin, end)
+	if !ok {
+		return nil, fmt.Errorf("%w: empty time interval", korrel8r.ErrNotApplicable)
+	}
+	return n, nil
+}
+
+func execute(t *template.Template, data any) (string, error) {
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// executeTime executes a time template, returns zero time if t is nil or the result is blank.
+func executeTime(t *template.Template, data any) (time.Time, error) {
+	if t == nil {
+		return time.Time{}, nil
+	}
+	s, err := execute(t, data)
+	if err != nil || s == "" {
+		return time.Time{}, err
+	}
+	when, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v: invalid time: %w", t.Name(), err)
+	}
+	return when, nil
+}
@@ -0,0 +1,175 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package ruletest runs declarative tests for rules.
+//
+// A test file is a YAML or JSON list of [Test] objects, for example:
+//
+//	# Expected query for a start object.
+//	- rule: PodToLogs
+//	  start: k8s:Pod.v1.
+//	  object:
+//	    metadata: {namespace: project, name: application}
+//	  query: 'log:application:{kubernetes_namespace_name="project",kubernetes_pod_name="application"}'
+//	# Rule does not apply to a start object.
+//	- rule: AlertToIncident
+//	  start: alert:alert
+//	  object: {labels: {alertname: x}}
+//	  notApplicable: true
+//
+// Tests are run against the rules of an [engine.Engine], stores are not used.
+package ruletest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/korrel8r/korrel8r/pkg/engine"
+	"sigs.k8s.io/yaml"
+)
+
+// Test applies a rule to a start object, and checks the resulting query.
+type Test struct {
+	// Name is an optional description of the test.
+	Name string `json:"name,omitempty"`
+	// Rule is the name of the rule to test.
+	Rule string `json:"rule"`
+	// Start is the full name of the class of the start object.
+	Start string `json:"start"`
+	// Object is the start object in YAML or JSON form.
+	Object any `json:"object"`
+	// Query is the expected query.
+	Query string `json:"query,omitempty"`
+	// NotApplicable is true if the rule is expected not to apply to the object.
+	NotApplicable bool `json:"notApplicable,omitempty"`
+
+	// Source file and index of the test in the file, set by [Load].
+	Source string `json:"-"`
+	Index  int    `json:"-"`
+}
+
+func (t *Test) String() string {
+	s := t.Name
+	if s == "" {
+		s = fmt.Sprintf("#%v", t.Index)
+	}
+	if t.Source != "" {
+		s = fmt.Sprintf("%v %v", t.Source, s)
+	}
+	return s
+}
+
+// Load tests from a file, or from all files named *_test.yaml or *_test.json in a directory.
+func Load(path string) ([]Test, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadFile(path)
+	}
+	var files []string
+	for _, pattern := range []string{"*_test.yaml", "*_test.json"} {
+		matches, _ := filepath.Glob(filepath.Join(path, pattern))
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+	var tests []Test
+	for _, f := range files {
+		t, err := loadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, t...)
+	}
+	return tests, nil
+}
+
+func loadFile(path string) ([]Test, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tests []Test
+	if err := yaml.UnmarshalStrict(b, &tests); err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	for i := range tests {
+		tests[i].Source, tests[i].Index = path, i
+	}
+	return tests, nil
+}
+
+// Result of running a test.
+type Result struct {
+	*Test
+	// Got is the query returned by the rule, empty if the rule did not apply.
+	Got string
+	// Err is the reason the test failed, nil if it passed.
+	Err error
+}
+
+func (r Result) Passed() bool { return r.Err == nil }
+
+// Run a test against the rules in an engine.
+func Run(e *engine.Engine, t *Test) Result {
+	r := Result{Test: t}
+	r.Got, r.Err = run(e, t)
+	return r
+}
+
+func run(e *engine.Engine, t *Test) (string, error) {
+	rule := e.Rule(t.Rule)
+	if rule == nil {
+		return "", fmt.Errorf("rule not found: %v", t.Rule)
+	}
+	class, err := e.Class(t.Start)
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(rule.Start(), class) {
+		return "", fmt.Errorf("rule %v does not start from %v", rule, class)
+	}
+	b, err := json.Marshal(t.Object)
+	if err != nil {
+		return "", err
+	}
+	o, err := class.Unmarshal(b)
+	if err != nil {
+		return "", fmt.Errorf("invalid start object: %w", err)
+	}
+	got, applyErr := rule.Apply(o)
+	switch {
+	case t.NotApplicable && applyErr != nil:
+		return "", nil
+	case t.NotApplicable:
+		return got.String(), fmt.Errorf("expected rule not to apply, got: %v", got)
+	case applyErr != nil:
+		return "", fmt.Errorf("rule did not apply: %w", applyErr)
+	case t.Query == "":
+		return got.String(), errors.New("no expected query and notApplicable is false")
+	}
+	// Compare normalized queries if possible.
+	want := strings.TrimSpace(t.Query)
+	if q, err := e.Query(want); err == nil {
+		want = q.String()
+	}
+	if got.String() != want {
+		return got.String(), fmt.Errorf("expected query: %v\ngot query: %v", want, got)
+	}
+	return got.String(), nil
+}
+
+// RunAll runs tests and returns the results grouped by rule name.
+func RunAll(e *engine.Engine, tests []Test) map[string][]Result {
+	results := map[string][]Result{}
+	for i := range tests {
+		r := Run(e, &tests[i])
+		results[r.Rule] = append(results[r.Rule], r)
+	}
+	return results
+}
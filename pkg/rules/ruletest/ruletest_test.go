@@ -0,0 +1,45 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package ruletest_test
+
+import (
+	"testing"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/config"
+	"github.com/korrel8r/korrel8r/pkg/engine"
+	"github.com/korrel8r/korrel8r/pkg/rules/ruletest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	e, err := engine.Build().Domains(mock.Domain("mock")).Config(config.Configs{{
+		Rules: []config.Rule{{
+			Name:   "fooToBar",
+			Start:  config.ClassSpec{Domain: "mock", Classes: []string{"foo"}},
+			Goal:   config.ClassSpec{Domain: "mock", Classes: []string{"bar"}},
+			Result: config.ResultSpec{Query: "{{with .name}}mock:bar:{{.}}{{end}}"},
+		}},
+	}}).Engine()
+	require.NoError(t, err)
+	tests, err := ruletest.Load("testdata")
+	require.NoError(t, err)
+	got := map[string]string{}
+	for i := range tests {
+		r := ruletest.Run(e, &tests[i])
+		if r.Passed() {
+			got[r.Name] = "PASS"
+		} else {
+			got[r.Name] = r.Err.Error()
+		}
+	}
+	assert.Equal(t, map[string]string{
+		"pass":                    "PASS",
+		"wrong query":             "expected query: mock:bar:y\ngot query: mock:bar:x",
+		"not applicable":          "PASS",
+		"unexpectedly applicable": "expected rule not to apply, got: mock:bar:x",
+		"wrong start":             "rule fooToBar does not start from mock:bar",
+		"missing rule":            "rule not found: nonesuch",
+	}, got)
+}
@@ -0,0 +1,141 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package score ranks correlated objects by relevance.
+//
+// Objects in the nodes of a correlation result graph are scored using these signals:
+//   - distance: objects fewer rules away from the start class score higher.
+//   - paths: objects returned by more independent queries score higher.
+//   - time: objects closer in time to the start objects score higher, if the class implements [korrel8r.Timestamper].
+//   - severity: objects with error or warning status score higher, if the class implements [korrel8r.Severer].
+//
+// Each signal is a value between 0 and 1, the total score is the weighted sum of signals, see [Weights].
+package score
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
+
+// TimeScale is the time difference from the start objects that halves the time signal.
+const TimeScale = 5 * time.Minute
+
+// Weights of each signal in the total score.
+type Weights struct {
+	Distance, Paths, Time, Severity float64
+}
+
+// DefaultWeights used by [New].
+var DefaultWeights = Weights{Distance: 1, Paths: 1, Time: 1, Severity: 2}
+
+// Score of an object with the signals used to compute it.
+type Score struct {
+	// Total is the weighted sum of signals, higher is more relevant.
+	Total float64
+	// Distance is the number of rules followed from the start class, -1 if not reachable.
+	Distance int
+	// Paths is the number of independent queries that returned the object.
+	Paths int
+	// TimeDelta is the time between the object and the nearest start object, nil if not known.
+	// It is 0 if their time intervals overlap.
+	TimeDelta *time.Duration
+	// Severity of problems reported by the object.
+	Severity korrel8r.Severity
+}
+
+// Scored is an object with its score.
+type Scored struct {
+	Object korrel8r.Object
+	Score  Score
+}
+
+// Scorer scores objects in a result graph.
+type Scorer struct {
+	Weights Weights
+
+	distance map[int64]int // Distance by node ID.
+	times    []interval    // Time intervals of start objects.
+}
+
+// New returns a Scorer for objects in g, a result graph of a search from start.
+func New(g *graph.Graph, start korrel8r.Class) *Scorer {
+	s := &Scorer{Weights: DefaultWeights, distance: map[int64]int{}}
+	if g.Node(g.NodeFor(start).ID()) == nil {
+		return s // Start is not in the graph.
+	}
+	g.BreadthFirst(start, graph.FuncVisitor{}, func(n *graph.Node, d int) bool {
+		if _, ok := s.distance[n.ID()]; !ok {
+			s.distance[n.ID()] = d
+		}
+		return false
+	})
+	for _, o := range g.NodeFor(start).Result.List() {
+		if t := intervalOf(start, o); !t.start.IsZero() {
+			s.times = append(s.times, t)
+		}
+	}
+	return s
+}
+
+// Score returns the score for object o in node n.
+func (s *Scorer) Score(n *graph.Node, o korrel8r.Object) Score {
+	var score Score
+	if d, ok := s.distance[n.ID()]; ok {
+		score.Distance = d
+		score.Total += s.Weights.Distance / float64(1+d)
+	} else {
+		score.Distance = -1
+	}
+	score.Paths = 1
+	if c, ok := n.Result.(interface{ Count(korrel8r.Object) int }); ok {
+		score.Paths = max(c.Count(o), 1)
+	}
+	score.Total += s.Weights.Paths * (1 - 1/float64(score.Paths))
+	if len(s.times) > 0 {
+		if t := intervalOf(n.Class, o); !t.start.IsZero() {
+			delta := time.Duration(math.MaxInt64)
+			for _, st := range s.times {
+				delta = min(delta, t.gap(st))
+			}
+			score.TimeDelta = &delta
+			score.Total += s.Weights.Time / (1 + float64(delta)/float64(TimeScale))
+		}
+	}
+	score.Severity = korrel8r.SeverityOf(n.Class, o)
+	score.Total += s.Weights.Severity * float64(score.Severity) / float64(korrel8r.SeverityError)
+	return score
+}
+
+// Rank returns the objects in node n with their scores, most relevant first.
+// Objects with equal scores are in result order.
+func (s *Scorer) Rank(n *graph.Node) []Scored {
+	var ranked []Scored
+	for _, o := range n.Result.List() {
+		ranked = append(ranked, Scored{Object: o, Score: s.Score(n, o)})
+	}
+	slices.SortStableFunc(ranked, func(a, b Scored) int { return cmp.Compare(b.Score.Total, a.Score.Total) })
+	return ranked
+}
+
+// interval is an object time interval, a zero end means the interval is not finished.
+type interval struct{ start, end time.Time }
+
+func intervalOf(c korrel8r.Class, o korrel8r.Object) interval {
+	start, end := korrel8r.TimestampOf(c, o)
+	return interval{start: start, end: end}
+}
+
+// gap returns the time between two intervals, 0 if they overlap.
+func (a interval) gap(b interval) time.Duration {
+	switch {
+	case !a.end.IsZero() && a.end.Before(b.start):
+		return b.start.Sub(a.end)
+	case !b.end.IsZero() && b.end.Before(a.start):
+		return a.start.Sub(b.end)
+	}
+	return 0
+}
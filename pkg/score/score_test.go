@@ -0,0 +1,76 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package score
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// class is a mock class where an int object N has time base+N minutes, and 3 has error severity.
+type class struct{ mock.Class }
+
+func (c class) Timestamp(o korrel8r.Object) (time.Time, time.Time) {
+	t := base.Add(time.Duration(o.(int)) * time.Minute)
+	return t, t
+}
+func (c class) Severity(o korrel8r.Object) korrel8r.Severity {
+	if o == 3 {
+		return korrel8r.SeverityError
+	}
+	return korrel8r.SeverityNone
+}
+
+func TestScorer_Rank(t *testing.T) {
+	d := mock.Domain("mock")
+	s, a := class{d.Class("s").(mock.Class)}, class{d.Class("a").(mock.Class)}
+	g := graph.NewData(
+		mock.NewRule("sa1", []korrel8r.Class{s}, []korrel8r.Class{a}, mock.NewQuery(a, "1,2", 1, 2)),
+		mock.NewRule("sa2", []korrel8r.Class{s}, []korrel8r.Class{a}, mock.NewQuery(a, "2,3", 2, 3)),
+	).FullGraph()
+	g.NodeFor(s).Result.Append(0)
+	g.NodeFor(a).Result.Append(1, 2) // Results of query 1,2
+	g.NodeFor(a).Result.Append(2, 3) // Results of query 2,3
+
+	scorer := New(g, s)
+	var got []korrel8r.Object
+	for _, scored := range scorer.Rank(g.NodeFor(a)) {
+		got = append(got, scored.Object)
+	}
+	assert.Equal(t, []korrel8r.Object{3, 2, 1}, got)
+
+	score := scorer.Score(g.NodeFor(a), 2)
+	delta := 2 * time.Minute
+	assert.Equal(t, 1, score.Distance)
+	assert.Equal(t, 2, score.Paths)
+	assert.Equal(t, &delta, score.TimeDelta)
+	assert.Equal(t, korrel8r.SeverityNone, score.Severity)
+	assert.InDelta(t, 0.5+0.5+1/1.4, score.Total, 1e-9)
+
+	score = scorer.Score(g.NodeFor(s), 0)
+	assert.Equal(t, 0, score.Distance)
+	assert.Equal(t, 1, score.Paths)
+	assert.InDelta(t, 2.0, score.Total, 1e-9) // Distance and time signals are both 1.
+}
+
+func TestInterval_gap(t *testing.T) {
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+	for _, x := range []struct {
+		a, b interval
+		want time.Duration
+	}{
+		{interval{at(0), at(0)}, interval{at(5), at(5)}, 5 * time.Minute},
+		{interval{at(5), at(5)}, interval{at(0), at(1)}, 4 * time.Minute},
+		{interval{at(0), at(10)}, interval{at(5), at(5)}, 0},
+		{interval{at(0), time.Time{}}, interval{at(60), at(60)}, 0}, // Not finished.
+	} {
+		assert.Equal(t, x.want, x.a.gap(x.b), "%v", x)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+// Package timeline merges the objects of a correlation result graph into a single chronological list.
+//
+// Object times are provided by classes that implement [korrel8r.Timestamper].
+// Each entry records the path of rules from the start class that led to the object's class.
+package timeline
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+)
+
+// Entry is a correlated object on a timeline.
+type Entry struct {
+	Class  korrel8r.Class
+	Object korrel8r.Object
+	// Start and End are the time interval of the object, see [korrel8r.Timestamper].
+	// Start is zero if the time is not known.
+	Start, End time.Time
+	// Preview of the object if the class is a [korrel8r.Previewer], may be empty.
+	Preview string
+	// Path is the shortest path of rules from the start class to Class, following only rules that returned results.
+	// Path is empty for start objects.
+	Path []korrel8r.Rule
+}
+
+// New returns the entries for all objects in g, a result graph of a search from start.
+//
+// Entries are sorted by start time, then by class name. Entries with unknown times are last.
+// Objects of classes that are not reachable from start are not included.
+func New(g *graph.Graph, start korrel8r.Class) []Entry {
+	paths := rulePaths(g, start)
+	var entries []Entry
+	g.EachNode(func(n *graph.Node) {
+		path, ok := paths[n.ID()]
+		if !ok {
+			return
+		}
+		previewer, _ := n.Class.(korrel8r.Previewer)
+		for _, o := range n.Result.List() {
+			e := Entry{Class: n.Class, Object: o, Path: path}
+			e.Start, e.End = korrel8r.TimestampOf(n.Class, o)
+			if previewer != nil {
+				e.Preview = previewer.Preview(o)
+			}
+			entries = append(entries, e)
+		}
+	})
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		switch {
+		case a.Start.IsZero() != b.Start.IsZero():
+			if a.Start.IsZero() {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.Class.String(), b.Class.String()))
+	})
+	return entries
+}
+
+// rulePaths returns the shortest rule path from start to each reachable node, by node ID.
+func rulePaths(g *graph.Graph, start korrel8r.Class) map[int64][]korrel8r.Rule {
+	paths := map[int64][]korrel8r.Rule{}
+	if g.Node(g.NodeFor(start).ID()) == nil {
+		return paths // Start is not in the graph.
+	}
+	paths[g.NodeFor(start).ID()] = nil
+	g.BreadthFirst(start, graph.FuncVisitor{
+		LineF: func(l *graph.Line) bool {
+			if l.Queries.Total() == 0 {
+				return false // No results along this line.
+			}
+			from, to := l.From().ID(), l.To().ID()
+			if _, ok := paths[to]; !ok {
+				paths[to] = append(slices.Clone(paths[from]), l.Rule)
+			}
+			return true
+		},
+	}, nil)
+	return paths
+}
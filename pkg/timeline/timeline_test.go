@@ -0,0 +1,69 @@
+// Copyright: This file is part of korrel8r, released under https://github.com/korrel8r/korrel8r/blob/main/LICENSE
+
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/korrel8r/korrel8r/internal/pkg/test/mock"
+	"github.com/korrel8r/korrel8r/pkg/graph"
+	"github.com/korrel8r/korrel8r/pkg/korrel8r"
+	"github.com/stretchr/testify/assert"
+)
+
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// class is a mock class where an int object N has time base+N minutes.
+type class struct{ mock.Class }
+
+func (c class) Timestamp(o korrel8r.Object) (time.Time, time.Time) {
+	t := base.Add(time.Duration(o.(int)) * time.Minute)
+	return t, t
+}
+
+func TestNew(t *testing.T) {
+	d := mock.Domain("mock")
+	s, a, b := class{d.Class("s").(mock.Class)}, class{d.Class("a").(mock.Class)}, class{d.Class("b").(mock.Class)}
+	c := d.Class("c") // No timestamps.
+	z := d.Class("z") // No results.
+	list := func(c ...korrel8r.Class) []korrel8r.Class { return c }
+	sa := mock.NewRule("sa", list(s), list(a), mock.NewQuery(a, "2,5", 2, 5))
+	ab := mock.NewRule("ab", list(a), list(b), mock.NewQuery(b, "1", 1))
+	sb := mock.NewRule("sb", list(s), list(b), mock.NewQuery(b, "none"))
+	sc := mock.NewRule("sc", list(s), list(c), mock.NewQuery(c, "x", "x"))
+	az := mock.NewRule("az", list(a), list(z), mock.NewQuery(z, "none"))
+	g := graph.NewData(sa, ab, sb, sc, az).FullGraph()
+	g.NodeFor(s).Result.Append(0)
+	g.NodeFor(a).Result.Append(2, 5)
+	g.NodeFor(b).Result.Append(1)
+	g.NodeFor(c).Result.Append("x")
+	counts := map[string]int{"sa": 2, "ab": 1, "sc": 1} // Other rules return nothing.
+	g.EachLine(func(l *graph.Line) {
+		q, _ := l.Rule.Apply(nil)
+		l.Queries.Set(q, counts[l.Rule.Name()])
+	})
+
+	type entry struct {
+		Class  korrel8r.Class
+		Object korrel8r.Object
+		Time   time.Time
+		Path   []string
+	}
+	var got []entry
+	for _, e := range New(g, s) {
+		x := entry{Class: e.Class, Object: e.Object, Time: e.Start}
+		for _, r := range e.Path {
+			x.Path = append(x.Path, r.Name())
+		}
+		got = append(got, x)
+	}
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+	assert.Equal(t, []entry{
+		{Class: s, Object: 0, Time: at(0)},
+		{Class: b, Object: 1, Time: at(1), Path: []string{"sa", "ab"}},
+		{Class: a, Object: 2, Time: at(2), Path: []string{"sa"}},
+		{Class: a, Object: 5, Time: at(5), Path: []string{"sa"}},
+		{Class: c, Object: "x", Path: []string{"sc"}},
+	}, got)
+}